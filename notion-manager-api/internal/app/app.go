@@ -3,9 +3,14 @@ package app
 import (
 	"os"
 
+	"github.com/Corray333/employee_dashboard/internal/calendar"
+	"github.com/Corray333/employee_dashboard/internal/domains/audit"
+	"github.com/Corray333/employee_dashboard/internal/domains/capacity"
 	"github.com/Corray333/employee_dashboard/internal/domains/client"
 	"github.com/Corray333/employee_dashboard/internal/domains/employee"
 	"github.com/Corray333/employee_dashboard/internal/domains/feedback"
+	"github.com/Corray333/employee_dashboard/internal/domains/ical"
+	"github.com/Corray333/employee_dashboard/internal/domains/job"
 	"github.com/Corray333/employee_dashboard/internal/domains/project"
 	"github.com/Corray333/employee_dashboard/internal/domains/task"
 	time "github.com/Corray333/employee_dashboard/internal/domains/time"
@@ -46,8 +51,15 @@ func New() *app {
 
 	store := postgres.New()
 	notionClient := notion.NewClient()
-	sheetsClient := gsheets.NewSheetsClient()
+	sheetsClient := gsheets.NewSheetsClient(store)
 	telegramClient := telegram.NewTelegramClient(os.Getenv("BOT_TOKEN"))
+	calendars := calendar.Load()
+
+	auditController := audit.NewAuditController(router, store)
+	app.controllers = append(app.controllers, auditController)
+
+	jobController := job.NewJobController(store)
+	app.controllers = append(app.controllers, jobController)
 
 	feedbackController := feedback.NewFeedbackController(grpcServer, store, notionClient)
 	app.controllers = append(app.controllers, feedbackController)
@@ -56,40 +68,49 @@ func New() *app {
 	app.controllers = append(app.controllers, employeeController)
 
 	// Create client controller first (without project service dependency)
-	clientController := client.NewClientController(store, notionClient, sheetsClient, nil)
+	clientController := client.NewClientController(store, notionClient, sheetsClient, telegramClient, nil, auditController.GetService())
 	app.controllers = append(app.controllers, clientController)
 
-	projectController := project.NewProjectController(router, store, notionClient, sheetsClient, clientController.GetService())
+	projectController := project.NewProjectController(router, store, notionClient, sheetsClient, telegramClient, clientController.GetService())
 	app.controllers = append(app.controllers, projectController)
 
-	timeController := time.NewTimeController(router, store, notionClient, sheetsClient, projectController.GetService())
+	timeController := time.NewTimeController(router, store, notionClient, sheetsClient, telegramClient, projectController.GetService(), auditController.GetService())
 	app.controllers = append(app.controllers, timeController)
 
-	taskController := task.NewTaskController(router, store, notionClient, sheetsClient, projectController.GetService())
+	taskController := task.NewTaskController(router, store, calendars, notionClient, sheetsClient, telegramClient, projectController.GetService(), auditController.GetService())
 	app.controllers = append(app.controllers, taskController)
 
-	weekdayController := weekday.NewWeekdayController(store, notionClient, telegramClient, employeeController.GetService(), sheetsClient)
+	weekdayController := weekday.NewWeekdayController(router, store, calendars, notionClient, telegramClient, employeeController.GetService(), sheetsClient, auditController.GetService())
 	app.controllers = append(app.controllers, weekdayController)
 
+	icalController := ical.NewICalController(router, store)
+	app.controllers = append(app.controllers, icalController)
+
+	capacityController := capacity.NewCapacityController(router, store, calendars)
+	app.controllers = append(app.controllers, capacityController)
+
 	// Update client controller with project service after project controller is created
-	clientController = client.NewClientController(store, notionClient, sheetsClient, projectController.GetService())
+	clientController = client.NewClientController(store, notionClient, sheetsClient, telegramClient, projectController.GetService(), auditController.GetService())
 	app.controllers = append(app.controllers, clientController)
 
 	projectController.AddProjectSheetsUpdater(taskController.GetService())
 	projectController.AddProjectSheetsUpdater(timeController.GetService())
 
 	storage := repositories.New()
-	external := external.New()
+	external := external.New(sheetsClient)
 	service := service.New(storage, external)
-	service.AddUpdateSubscriber(timeController.GetService())
-	service.AddUpdateSubscriber(taskController.GetService())
-	service.AddUpdateSubscriber(weekdayController.GetService())
+	service.AddUpdateSubscriber("times", timeController.GetService())
+	service.AddUpdateSubscriber("tasks", taskController.GetService())
+	service.AddUpdateSubscriber("weekdays", weekdayController.GetService())
 
 	// Set task and time services for deletion operations
 	service.SetTaskService(taskController.GetService())
 	service.SetTimeService(timeController.GetService())
-	service.AddUpdateSubscriber(clientController.GetService())
-	// service.AddUpdateSubscriber(projectController.GetService())
+	service.SetWeekdayService(weekdayController.GetService())
+	service.SetChangesRecorder(auditController.GetService())
+	service.AddUpdateSubscriber("clients", clientController.GetService())
+	service.AddUpdateSubscriber("projects", projectController.GetService())
+	service.SetJobQueue(jobController.GetService())
 
 	transport := transport.New(router, service)
 	transport.RegisterRoutes()
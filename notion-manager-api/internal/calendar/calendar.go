@@ -0,0 +1,291 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var ErrInvalidCalendar = errors.New("invalid calendar")
+
+type DayKind string
+
+const (
+	DayWorking DayKind = "working"
+	// DayShort - рабочий день, сокращенный на час перед праздником
+	DayShort   DayKind = "short"
+	DayWeekend DayKind = "weekend"
+	DayHoliday DayKind = "holiday"
+)
+
+// data - файл производственного календаря страны. Даты в формате YYYY-MM-DD:
+// holidays - нерабочие дни, включая перенесенные выходные; workdays - выходные, ставшие рабочими.
+type data struct {
+	Country     string   `json:"country"`
+	HoursPerDay float64  `json:"hours_per_day"`
+	Weekend     []string `json:"weekend"`
+	Holidays    []string `json:"holidays"`
+	Workdays    []string `json:"workdays"`
+	ShortDays   []string `json:"short_days"`
+}
+
+// Calendar - производственный календарь одной страны. Годы, которых нет в файле, считаются
+// по одним выходным дням недели.
+type Calendar struct {
+	country     string
+	hoursPerDay float64
+	weekend     map[time.Weekday]bool
+	days        map[string]DayKind
+}
+
+// Weekends - календарь без праздников, где выходные только суббота и воскресенье
+func Weekends() *Calendar {
+	return &Calendar{
+		hoursPerDay: 8,
+		weekend:     map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		days:        map[string]DayKind{},
+	}
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Parse читает календарь из JSON
+func Parse(raw []byte) (*Calendar, error) {
+	d := data{}
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCalendar, err)
+	}
+
+	c := Weekends()
+	c.country = strings.ToLower(d.Country)
+	if d.HoursPerDay > 0 {
+		c.hoursPerDay = d.HoursPerDay
+	}
+	if len(d.Weekend) > 0 {
+		c.weekend = map[time.Weekday]bool{}
+		for _, name := range d.Weekend {
+			wd, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown weekday %q", ErrInvalidCalendar, name)
+			}
+			c.weekend[wd] = true
+		}
+	}
+
+	// Сокращенный день может быть и перенесенным рабочим, поэтому он читается последним
+	for _, group := range []struct {
+		kind  DayKind
+		dates []string
+	}{{DayHoliday, d.Holidays}, {DayWorking, d.Workdays}, {DayShort, d.ShortDays}} {
+		for _, date := range group.dates {
+			if _, err := time.Parse(time.DateOnly, date); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidCalendar, err)
+			}
+			c.days[date] = group.kind
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Calendar) Country() string {
+	return c.country
+}
+
+// Kind возвращает тип дня
+func (c *Calendar) Kind(day time.Time) DayKind {
+	if kind, ok := c.days[day.Format(time.DateOnly)]; ok {
+		return kind
+	}
+	if c.weekend[day.Weekday()] {
+		return DayWeekend
+	}
+	return DayWorking
+}
+
+func (c *Calendar) IsWorkingDay(day time.Time) bool {
+	kind := c.Kind(day)
+	return kind == DayWorking || kind == DayShort
+}
+
+// WorkingHours возвращает норму часов за день: полный день, на час меньше в сокращенный, 0 в выходной
+func (c *Calendar) WorkingHours(day time.Time) float64 {
+	switch c.Kind(day) {
+	case DayWorking:
+		return c.hoursPerDay
+	case DayShort:
+		return c.hoursPerDay - 1
+	}
+	return 0
+}
+
+// WorkingDays считает рабочие дни в периоде [from, to] включительно
+func (c *Calendar) WorkingDays(from, to time.Time) int {
+	days := 0
+	for d := date(from); !d.After(date(to)); d = d.AddDate(0, 0, 1) {
+		if c.IsWorkingDay(d) {
+			days++
+		}
+	}
+	return days
+}
+
+// NormHours считает норму рабочих часов в периоде [from, to] включительно
+func (c *Calendar) NormHours(from, to time.Time) float64 {
+	hours := 0.0
+	for d := date(from); !d.After(date(to)); d = d.AddDate(0, 0, 1) {
+		hours += c.WorkingHours(d)
+	}
+	return hours
+}
+
+// AddWorkingDays возвращает день, наступающий через n рабочих дней после day, с тем же временем суток
+func (c *Calendar) AddWorkingDays(day time.Time, n int) time.Time {
+	for n > 0 {
+		day = day.AddDate(0, 0, 1)
+		if c.IsWorkingDay(day) {
+			n--
+		}
+	}
+	return day
+}
+
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// GeoCountry - какой календарь использовать для сотрудников из geo
+type GeoCountry struct {
+	Geo     string `mapstructure:"geo"`
+	Country string `mapstructure:"country"`
+}
+
+// Calendars - календари стран с выбором по geo сотрудника
+type Calendars struct {
+	byCountry map[string]*Calendar
+	byGeo     map[string]string
+	fallback  *Calendar
+}
+
+// New собирает набор календарей. Если календаря страны по умолчанию нет, используется календарь без праздников.
+func New(calendars []*Calendar, geo []GeoCountry, defaultCountry string) *Calendars {
+	c := &Calendars{
+		byCountry: map[string]*Calendar{},
+		byGeo:     map[string]string{},
+		fallback:  Weekends(),
+	}
+	for _, cal := range calendars {
+		c.byCountry[cal.country] = cal
+	}
+	for _, g := range geo {
+		c.byGeo[strings.ToLower(strings.TrimSpace(g.Geo))] = strings.ToLower(g.Country)
+	}
+	if cal, ok := c.byCountry[strings.ToLower(defaultCountry)]; ok {
+		c.fallback = cal
+	}
+	return c
+}
+
+// Load читает календари из файлов <calendar.dir>/<страна>.json.
+// Календарь, который не удалось прочитать, пропускается: даты по нему считаются без праздников.
+func Load() *Calendars {
+	dir := viper.GetString("calendar.dir")
+	geo := []GeoCountry{}
+	if err := viper.UnmarshalKey("calendar.geo", &geo); err != nil {
+		slog.Error("Error reading calendar geo mapping", "error", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		slog.Error("Error listing calendars", "error", err, "dir", dir)
+	}
+
+	calendars := []*Calendar{}
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			slog.Error("Error reading calendar", "error", err, "file", file)
+			continue
+		}
+		cal, err := Parse(raw)
+		if err != nil {
+			slog.Error("Error parsing calendar", "error", err, "file", file)
+			continue
+		}
+		if cal.country == "" {
+			cal.country = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		calendars = append(calendars, cal)
+	}
+	if len(calendars) == 0 {
+		slog.Warn("No production calendars loaded, public holidays are ignored", "dir", dir)
+	}
+
+	return New(calendars, geo, viper.GetString("calendar.default_country"))
+}
+
+// Default возвращает календарь страны по умолчанию
+func (c *Calendars) Default() *Calendar {
+	return c.fallback
+}
+
+// ForGeo возвращает календарь для geo сотрудника, а для неизвестного geo - календарь по умолчанию
+func (c *Calendars) ForGeo(geo string) *Calendar {
+	if country, ok := c.byGeo[strings.ToLower(strings.TrimSpace(geo))]; ok {
+		if cal, ok := c.byCountry[country]; ok {
+			return cal
+		}
+	}
+	return c.fallback
+}
+
+// Norm - рабочие дни и норма часов за период
+type Norm struct {
+	Country     string    `json:"country"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	WorkingDays int       `json:"working_days"`
+	Hours       float64   `json:"hours"`
+}
+
+func (c *Calendar) Norm(from, to time.Time) Norm {
+	return Norm{
+		Country:     c.country,
+		From:        date(from),
+		To:          date(to),
+		WorkingDays: c.WorkingDays(from, to),
+		Hours:       c.NormHours(from, to),
+	}
+}
+
+// Quarter возвращает номер квартала, в который попадает t
+func Quarter(t time.Time) int {
+	return (int(t.Month())-1)/3 + 1
+}
+
+// QuarterBounds возвращает первый и последний день квартала года
+func QuarterBounds(year, quarter int) (time.Time, time.Time) {
+	from := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 3, -1)
+}
+
+// MonthBounds возвращает первый и последний день месяца года
+func MonthBounds(year int, month time.Month) (time.Time, time.Time) {
+	from := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 1, -1)
+}
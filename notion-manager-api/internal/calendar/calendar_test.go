@@ -0,0 +1,98 @@
+package calendar
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRussianCalendarNorms(t *testing.T) {
+	raw, err := os.ReadFile("../../../configs/notion-manager-api/calendars/ru.json")
+	if err != nil {
+		t.Fatalf("read calendar: %v", err)
+	}
+	cal, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		year  int
+		days  int
+		hours float64
+	}{
+		{year: 2025, days: 247, hours: 1972},
+		{year: 2026, days: 247, hours: 1972},
+	}
+
+	for _, tt := range tests {
+		from := time.Date(tt.year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(tt.year, time.December, 31, 0, 0, 0, 0, time.UTC)
+		if days := cal.WorkingDays(from, to); days != tt.days {
+			t.Errorf("WorkingDays(%d) = %d, expected %d", tt.year, days, tt.days)
+		}
+		if hours := cal.NormHours(from, to); hours != tt.hours {
+			t.Errorf("NormHours(%d) = %v, expected %v", tt.year, hours, tt.hours)
+		}
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	cal, err := Parse([]byte(`{
+		"country": "RU",
+		"holidays": ["2025-05-01", "2025-05-02"],
+		"workdays": ["2025-11-01"],
+		"short_days": ["2025-04-30", "2025-11-01"]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	date := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 10, 0, 0, 0, time.UTC) }
+
+	kinds := []struct {
+		day  time.Time
+		kind DayKind
+	}{
+		{date(time.April, 29), DayWorking},
+		{date(time.April, 30), DayShort},
+		{date(time.May, 1), DayHoliday},
+		{date(time.May, 3), DayWeekend},
+		{date(time.November, 1), DayShort},
+	}
+	for _, k := range kinds {
+		if kind := cal.Kind(k.day); kind != k.kind {
+			t.Errorf("Kind(%s) = %s, expected %s", k.day.Format(time.DateOnly), kind, k.kind)
+		}
+	}
+
+	if hours := cal.NormHours(date(time.April, 28), date(time.May, 4)); hours != 23 {
+		t.Errorf("NormHours() = %v, expected 23", hours)
+	}
+	if got := cal.AddWorkingDays(date(time.April, 30), 1); !got.Equal(date(time.May, 5)) {
+		t.Errorf("AddWorkingDays() = %s, expected 2025-05-05", got)
+	}
+}
+
+func TestCalendarsForGeo(t *testing.T) {
+	ru := Weekends()
+	ru.country = "ru"
+	by := Weekends()
+	by.country = "by"
+
+	calendars := New([]*Calendar{ru, by}, []GeoCountry{{Geo: "Минск", Country: "BY"}, {Geo: "Алматы", Country: "kz"}}, "ru")
+
+	tests := []struct {
+		geo     string
+		country string
+	}{
+		{geo: " минск ", country: "by"},
+		{geo: "Москва", country: "ru"},
+		{geo: "Алматы", country: "ru"},
+		{geo: "", country: "ru"},
+	}
+	for _, tt := range tests {
+		if got := calendars.ForGeo(tt.geo).Country(); got != tt.country {
+			t.Errorf("ForGeo(%q) = %q, expected %q", tt.geo, got, tt.country)
+		}
+	}
+}
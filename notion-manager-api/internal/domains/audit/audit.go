@@ -0,0 +1,40 @@
+package audit
+
+import (
+	postgres_repo "github.com/Corray333/employee_dashboard/internal/domains/audit/repositories/postgres"
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/service"
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/transport"
+	"github.com/Corray333/employee_dashboard/internal/postgres"
+	"github.com/go-chi/chi/v5"
+)
+
+type AuditController struct {
+	postgresRepo *postgres_repo.AuditPostgresRepository
+	service      *service.AuditService
+	transport    *transport.AuditTransport
+}
+
+func NewAuditController(router *chi.Mux, store *postgres.PostgresClient) *AuditController {
+	postgresRepo := postgres_repo.NewAuditPostgresRepository(store)
+
+	service := service.NewAuditService(service.WithPostgresRepository(postgresRepo))
+
+	transport := transport.NewAuditTransport(router, service)
+
+	return &AuditController{
+		postgresRepo: postgresRepo,
+		service:      service,
+		transport:    transport,
+	}
+}
+
+func (c *AuditController) Build() {
+	c.transport.RegisterRoutes()
+}
+
+func (c *AuditController) Run() {
+}
+
+func (c *AuditController) GetService() *service.AuditService {
+	return c.service
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Entity string
+
+const (
+	EntityTask    Entity = "task"
+	EntityTime    Entity = "time"
+	EntityProject Entity = "project"
+	EntityClient  Entity = "client"
+	EntityWeekday Entity = "weekday"
+)
+
+var Entities = []Entity{
+	EntityTask,
+	EntityTime,
+	EntityProject,
+	EntityClient,
+	EntityWeekday,
+}
+
+// Change - изменение одного поля сущности, полученное при синхронизации с Notion
+type Change struct {
+	ID       int64     `json:"id"`
+	Entity   Entity    `json:"entity"`
+	EntityID uuid.UUID `json:"entity_id"`
+	Field    string    `json:"field"`
+	OldValue string    `json:"old_value"`
+	NewValue string    `json:"new_value"`
+
+	// Время последнего редактирования страницы в Notion и ID пользователя Notion, который ее редактировал
+	EditedAt time.Time `json:"edited_at"`
+	EditedBy string    `json:"edited_by"`
+	Editor   string    `json:"editor"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Diff returns changes between two field snapshots. A nil before means the entity is new.
+func Diff(before, after map[string]string) []Change {
+	fields := make([]string, 0, len(after))
+	for field := range after {
+		fields = append(fields, field)
+	}
+	for field := range before {
+		if _, ok := after[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []Change{}
+	for _, field := range fields {
+		oldValue, newValue := before[field], after[field]
+		if oldValue == newValue {
+			continue
+		}
+		changes = append(changes, Change{
+			Field:    field,
+			OldValue: oldValue,
+			NewValue: newValue,
+		})
+	}
+
+	return changes
+}
+
+// FormatTime приводит время к виду, в котором оно хранится в журнале
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// FormatUUID возвращает пустую строку для uuid.Nil
+func FormatUUID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  map[string]string
+		after   map[string]string
+		expects []Change
+	}{
+		{
+			name:    "no changes",
+			before:  map[string]string{"status": "В работе", "estimate": "4"},
+			after:   map[string]string{"status": "В работе", "estimate": "4"},
+			expects: []Change{},
+		},
+		{
+			name:   "changed fields are sorted",
+			before: map[string]string{"status": "В работе", "estimate": "4", "title": "Задача"},
+			after:  map[string]string{"status": "Код-ревью", "estimate": "6", "title": "Задача"},
+			expects: []Change{
+				{Field: "estimate", OldValue: "4", NewValue: "6"},
+				{Field: "status", OldValue: "В работе", NewValue: "Код-ревью"},
+			},
+		},
+		{
+			name:   "new entity",
+			before: nil,
+			after:  map[string]string{"status": "Можно делать", "executor_id": ""},
+			expects: []Change{
+				{Field: "status", OldValue: "", NewValue: "Можно делать"},
+			},
+		},
+		{
+			name:   "removed field",
+			before: map[string]string{"reason": "Отпуск"},
+			after:  map[string]string{},
+			expects: []Change{
+				{Field: "reason", OldValue: "Отпуск", NewValue: ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.before, tt.after)
+			if !reflect.DeepEqual(got, tt.expects) {
+				t.Errorf("Diff() = %+v, expected %+v", got, tt.expects)
+			}
+		})
+	}
+}
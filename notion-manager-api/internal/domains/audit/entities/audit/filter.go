@@ -0,0 +1,8 @@
+package audit
+
+import "github.com/google/uuid"
+
+type Filter struct {
+	Entity   Entity
+	EntityID uuid.UUID
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
+	"github.com/google/uuid"
+)
+
+type changeDB struct {
+	ID        int64     `db:"change_id"`
+	Entity    string    `db:"entity"`
+	EntityID  uuid.UUID `db:"entity_id"`
+	Field     string    `db:"field"`
+	OldValue  string    `db:"old_value"`
+	NewValue  string    `db:"new_value"`
+	EditedAt  time.Time `db:"edited_at"`
+	EditedBy  string    `db:"edited_by"`
+	Editor    string    `db:"editor"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (c *changeDB) toEntity() *audit.Change {
+	return &audit.Change{
+		ID:        c.ID,
+		Entity:    audit.Entity(c.Entity),
+		EntityID:  c.EntityID,
+		Field:     c.Field,
+		OldValue:  c.OldValue,
+		NewValue:  c.NewValue,
+		EditedAt:  c.EditedAt,
+		EditedBy:  c.EditedBy,
+		Editor:    c.Editor,
+		CreatedAt: c.CreatedAt,
+	}
+}
+
+func (r *AuditPostgresRepository) CreateChanges(ctx context.Context, changes []audit.Change) error {
+	tx, isNew, err := r.GetTx(ctx)
+	if err != nil {
+		return err
+	}
+	if isNew {
+		defer tx.Rollback()
+	}
+
+	for _, change := range changes {
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO audit_log (entity, entity_id, field, old_value, new_value, edited_at, edited_by)
+			VALUES (:entity, :entity_id, :field, :old_value, :new_value, :edited_at, :edited_by)
+		`, &changeDB{
+			Entity:   string(change.Entity),
+			EntityID: change.EntityID,
+			Field:    change.Field,
+			OldValue: change.OldValue,
+			NewValue: change.NewValue,
+			EditedAt: change.EditedAt,
+			EditedBy: change.EditedBy,
+		})
+		if err != nil {
+			slog.Error("Error creating audit change", "error", err)
+			return err
+		}
+	}
+
+	if isNew {
+		return tx.Commit()
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+func (r *AuditPostgresRepository) ListChanges(ctx context.Context, filter audit.Filter) ([]audit.Change, error) {
+	builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select(
+			"a.change_id", "a.entity", "a.entity_id", "a.field", "a.old_value", "a.new_value",
+			"a.edited_at", "a.edited_by", "a.created_at",
+			"COALESCE(e.username, '') AS editor",
+		).
+		From("audit_log a").
+		LeftJoin("employees e ON e.employee_id::text = a.edited_by AND a.edited_by != ''").
+		OrderBy("a.edited_at DESC", "a.change_id DESC")
+
+	if filter.Entity != "" {
+		builder = builder.Where(squirrel.Eq{"a.entity": string(filter.Entity)})
+	}
+	if filter.EntityID != uuid.Nil {
+		builder = builder.Where(squirrel.Eq{"a.entity_id": filter.EntityID})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		slog.Error("Error building SQL query", "error", err)
+		return nil, err
+	}
+
+	changesDB := []changeDB{}
+	if err := r.DB().SelectContext(ctx, &changesDB, query, args...); err != nil {
+		slog.Error("Error listing audit changes", "error", err)
+		return nil, err
+	}
+
+	changes := make([]audit.Change, 0, len(changesDB))
+	for _, c := range changesDB {
+		changes = append(changes, *c.toEntity())
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,13 @@
+package postgres
+
+import (
+	"github.com/Corray333/employee_dashboard/internal/postgres"
+)
+
+type AuditPostgresRepository struct {
+	*postgres.PostgresClient
+}
+
+func NewAuditPostgresRepository(client *postgres.PostgresClient) *AuditPostgresRepository {
+	return &AuditPostgresRepository{client}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
+	"github.com/google/uuid"
+)
+
+type AuditService struct {
+	changesCreator changesCreator
+	changesLister  changesLister
+}
+
+type postgresRepository interface {
+	changesCreator
+	changesLister
+}
+
+type changesCreator interface {
+	CreateChanges(ctx context.Context, changes []audit.Change) error
+}
+
+type changesLister interface {
+	ListChanges(ctx context.Context, filter audit.Filter) ([]audit.Change, error)
+}
+
+type option func(*AuditService)
+
+func NewAuditService(opts ...option) *AuditService {
+	service := &AuditService{}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	return service
+}
+
+func WithPostgresRepository(repository postgresRepository) option {
+	return func(s *AuditService) {
+		s.changesCreator = repository
+		s.changesLister = repository
+	}
+}
+
+// RecordChanges сохраняет в журнал поля, которые отличаются в before и after
+func (s *AuditService) RecordChanges(ctx context.Context, entity audit.Entity, entityID uuid.UUID, before, after map[string]string, editedAt time.Time, editedBy string) error {
+	changes := audit.Diff(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	for i := range changes {
+		changes[i].Entity = entity
+		changes[i].EntityID = entityID
+		changes[i].EditedAt = editedAt
+		changes[i].EditedBy = editedBy
+	}
+
+	return s.changesCreator.CreateChanges(ctx, changes)
+}
+
+func (s *AuditService) ListChanges(ctx context.Context, filter audit.Filter) ([]audit.Change, error) {
+	return s.changesLister.ListChanges(ctx, filter)
+}
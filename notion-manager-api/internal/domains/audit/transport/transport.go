@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
+	"github.com/Corray333/employee_dashboard/internal/transport"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type service interface {
+	ListChanges(ctx context.Context, filter audit.Filter) ([]audit.Change, error)
+}
+
+type AuditTransport struct {
+	service service
+	router  *chi.Mux
+}
+
+func NewAuditTransport(router *chi.Mux, service service) *AuditTransport {
+	t := &AuditTransport{
+		service: service,
+		router:  router,
+	}
+
+	return t
+}
+
+func (t *AuditTransport) RegisterRoutes() {
+	t.router.Group(func(r chi.Router) {
+		r.Use(transport.NewTaskTrackerAuthMiddleware())
+
+		r.Get("/api/audit", t.listChanges)
+	})
+}
+
+func (t *AuditTransport) listChanges(w http.ResponseWriter, r *http.Request) {
+	filter := audit.Filter{
+		Entity: audit.Entity(r.URL.Query().Get("entity")),
+	}
+	if !slices.Contains(audit.Entities, filter.Entity) {
+		http.Error(w, fmt.Sprintf("unknown entity %q", filter.Entity), http.StatusBadRequest)
+		return
+	}
+
+	if idStr := r.URL.Query().Get("id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.EntityID = id
+	}
+
+	changes, err := t.service.ListChanges(r.Context(), filter)
+	if err != nil {
+		slog.Error("Error listing audit changes", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(changes); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,41 @@
+package capacity
+
+import (
+	"github.com/Corray333/employee_dashboard/internal/calendar"
+	postgres_repo "github.com/Corray333/employee_dashboard/internal/domains/capacity/repositories/postgres"
+	"github.com/Corray333/employee_dashboard/internal/domains/capacity/service"
+	"github.com/Corray333/employee_dashboard/internal/domains/capacity/transport"
+	"github.com/Corray333/employee_dashboard/internal/postgres"
+	"github.com/go-chi/chi/v5"
+)
+
+type CapacityController struct {
+	postgresRepo *postgres_repo.CapacityPostgresRepository
+	service      *service.CapacityService
+	transport    *transport.CapacityTransport
+}
+
+func NewCapacityController(router *chi.Mux, store *postgres.PostgresClient, calendars *calendar.Calendars) *CapacityController {
+	postgresRepo := postgres_repo.NewCapacityPostgresRepository(store)
+
+	service := service.NewCapacityService(service.WithPostgresRepository(postgresRepo), service.WithCalendars(calendars))
+
+	transport := transport.NewCapacityTransport(router, service)
+
+	return &CapacityController{
+		postgresRepo: postgresRepo,
+		service:      service,
+		transport:    transport,
+	}
+}
+
+func (c *CapacityController) Build() {
+	c.transport.RegisterRoutes()
+}
+
+func (c *CapacityController) Run() {
+}
+
+func (c *CapacityController) GetService() *service.CapacityService {
+	return c.service
+}
@@ -0,0 +1,278 @@
+package capacity
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidPeriod = errors.New("invalid capacity period")
+
+// WorkingHoursFunc возвращает норму рабочих часов за день по производственному календарю
+type WorkingHoursFunc func(day time.Time) float64
+
+// Employee - сотрудник, для которого считается загрузка. ID - profile_id, как в weekdays;
+// задачи должны приходить с исполнителем, уже переведенным в profile_id.
+type Employee struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	Direction string    `json:"direction"`
+	Geo       string    `json:"geo"`
+}
+
+// Absence - отсутствие сотрудника, однодневное хранится без даты окончания
+type Absence struct {
+	EmployeeID uuid.UUID
+	Start      time.Time
+	End        time.Time
+}
+
+// Task - незавершенная задача с оставшейся оценкой в часах. EmployeeID - profile_id исполнителя.
+type Task struct {
+	ID         uuid.UUID
+	EmployeeID uuid.UUID
+	Remaining  float64
+	Start      time.Time
+	End        time.Time
+}
+
+// Week - неделя горизонта планирования, дни [Start, End] включительно
+type Week struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Weeks делит горизонт на n недель с понедельника по воскресенье. Первая неделя начинается с from.
+func Weeks(from time.Time, n int) []Week {
+	from = day(from)
+	weeks := make([]Week, 0, n)
+	start := from
+	for i := 0; i < n; i++ {
+		// Воскресенье - последний день недели
+		end := start.AddDate(0, 0, (7-int(start.Weekday()))%7)
+		weeks = append(weeks, Week{Start: start, End: end})
+		start = end.AddDate(0, 0, 1)
+	}
+	return weeks
+}
+
+// Load - доступные и распределенные часы. Balance - сколько часов осталось свободно, отрицательный - перегрузка.
+type Load struct {
+	Available     float64 `json:"available"`
+	Allocated     float64 `json:"allocated"`
+	Balance       float64 `json:"balance"`
+	Utilization   float64 `json:"utilization"`
+	Overallocated bool    `json:"overallocated"`
+}
+
+func (l *Load) add(other Load) {
+	l.Available += other.Available
+	l.Allocated += other.Allocated
+}
+
+func (l *Load) finish() {
+	l.Available = round(l.Available)
+	l.Allocated = round(l.Allocated)
+	l.Balance = round(l.Available - l.Allocated)
+	l.Utilization = 0
+	if l.Available > 0 {
+		l.Utilization = round(l.Allocated / l.Available * 100)
+	}
+	l.Overallocated = l.Allocated > l.Available
+}
+
+type WeekLoad struct {
+	Week
+	Load
+}
+
+// EmployeeCapacity - загрузка сотрудника по неделям. Unscheduled - оставшиеся часы задач без срока, в недели они не попадают.
+type EmployeeCapacity struct {
+	Employee    Employee   `json:"employee"`
+	Weeks       []WeekLoad `json:"weeks"`
+	Total       Load       `json:"total"`
+	Unscheduled float64    `json:"unscheduled"`
+}
+
+// DirectionCapacity - суммарная загрузка сотрудников направления
+type DirectionCapacity struct {
+	Direction   string     `json:"direction"`
+	Employees   int        `json:"employees"`
+	Weeks       []WeekLoad `json:"weeks"`
+	Total       Load       `json:"total"`
+	Unscheduled float64    `json:"unscheduled"`
+}
+
+type Report struct {
+	Weeks      []Week              `json:"weeks"`
+	Employees  []EmployeeCapacity  `json:"employees"`
+	Directions []DirectionCapacity `json:"directions"`
+}
+
+// Compute считает доступные часы (норма по календарю сотрудника без дней отсутствия) и распределенные часы
+// (остаток оценки задачи поровну на доступные часы между ее началом и сроком) по неделям weeks.
+// Прошедшая часть задачи не учитывается, остаток просроченной задачи ложится на первый день горизонта.
+// Сотрудники в отчете отсортированы от самых свободных к самым загруженным.
+func Compute(weeks []Week, employees []Employee, absences []Absence, tasks []Task, hoursFor func(e *Employee) WorkingHoursFunc) *Report {
+	report := &Report{Weeks: weeks, Employees: []EmployeeCapacity{}, Directions: []DirectionCapacity{}}
+	if len(weeks) == 0 {
+		return report
+	}
+	from, to := weeks[0].Start, weeks[len(weeks)-1].End
+
+	absent := map[uuid.UUID][]Absence{}
+	for _, a := range absences {
+		absent[a.EmployeeID] = append(absent[a.EmployeeID], a)
+	}
+	assigned := map[uuid.UUID][]Task{}
+	for _, t := range tasks {
+		assigned[t.EmployeeID] = append(assigned[t.EmployeeID], t)
+	}
+
+	directions := map[string]*DirectionCapacity{}
+	for i := range employees {
+		e := &employees[i]
+		a := availability{hours: hoursFor(e), absences: absent[e.ID]}
+
+		available := map[time.Time]float64{}
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			available[d] = a.available(d)
+		}
+
+		allocated := map[time.Time]float64{}
+		unscheduled := 0.0
+		for _, t := range assigned[e.ID] {
+			if t.End.IsZero() {
+				unscheduled += t.Remaining
+				continue
+			}
+			for d, hours := range a.spread(&t, from) {
+				if !d.After(to) {
+					allocated[d] += hours
+				}
+			}
+		}
+
+		c := EmployeeCapacity{Employee: *e, Weeks: make([]WeekLoad, 0, len(weeks)), Unscheduled: round(unscheduled)}
+		for _, w := range weeks {
+			load := WeekLoad{Week: w}
+			for d := w.Start; !d.After(w.End); d = d.AddDate(0, 0, 1) {
+				load.Available += available[d]
+				load.Allocated += allocated[d]
+			}
+			c.Total.add(load.Load)
+			load.finish()
+			c.Weeks = append(c.Weeks, load)
+		}
+
+		dir, ok := directions[e.Direction]
+		if !ok {
+			dir = &DirectionCapacity{Direction: e.Direction, Weeks: make([]WeekLoad, len(weeks))}
+			for i, w := range weeks {
+				dir.Weeks[i].Week = w
+			}
+			directions[e.Direction] = dir
+		}
+		dir.Employees++
+		dir.Unscheduled += unscheduled
+		for i := range c.Weeks {
+			dir.Weeks[i].add(c.Weeks[i].Load)
+		}
+		dir.Total.add(c.Total)
+
+		c.Total.finish()
+		report.Employees = append(report.Employees, c)
+	}
+
+	for _, dir := range directions {
+		for i := range dir.Weeks {
+			dir.Weeks[i].finish()
+		}
+		dir.Total.finish()
+		dir.Unscheduled = round(dir.Unscheduled)
+		report.Directions = append(report.Directions, *dir)
+	}
+
+	sort.SliceStable(report.Employees, func(i, j int) bool {
+		if report.Employees[i].Total.Balance != report.Employees[j].Total.Balance {
+			return report.Employees[i].Total.Balance > report.Employees[j].Total.Balance
+		}
+		return report.Employees[i].Employee.Username < report.Employees[j].Employee.Username
+	})
+	sort.Slice(report.Directions, func(i, j int) bool {
+		return report.Directions[i].Direction < report.Directions[j].Direction
+	})
+
+	return report
+}
+
+type availability struct {
+	hours    WorkingHoursFunc
+	absences []Absence
+}
+
+func (a *availability) isAbsent(d time.Time) bool {
+	for _, abs := range a.absences {
+		first := day(abs.Start)
+		last := first
+		if !abs.End.IsZero() && day(abs.End).After(first) {
+			last = day(abs.End)
+		}
+		if !d.Before(first) && !d.After(last) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *availability) available(d time.Time) float64 {
+	if a.isAbsent(d) {
+		return 0
+	}
+	return a.hours(d)
+}
+
+// spread распределяет остаток задачи по дням с from до срока пропорционально доступным часам.
+// Если доступных часов нет, остаток распределяется по норме календаря, а если нет и ее - ложится на последний день.
+func (a *availability) spread(t *Task, from time.Time) map[time.Time]float64 {
+	start, end := day(t.Start), day(t.End)
+	if start.Before(from) || t.Start.IsZero() {
+		start = from
+	}
+	if end.Before(start) {
+		end = start
+	}
+
+	for _, weight := range []func(time.Time) float64{a.available, a.hours} {
+		weights := map[time.Time]float64{}
+		total := 0.0
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			if w := weight(d); w > 0 {
+				weights[d] = w
+				total += w
+			}
+		}
+		if total == 0 {
+			continue
+		}
+
+		hours := make(map[time.Time]float64, len(weights))
+		for d, w := range weights {
+			hours[d] = t.Remaining * w / total
+		}
+		return hours
+	}
+
+	return map[time.Time]float64{end: t.Remaining}
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
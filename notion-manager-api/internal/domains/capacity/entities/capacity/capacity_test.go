@@ -0,0 +1,164 @@
+package capacity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func weekdayHours(d time.Time) float64 {
+	if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+		return 0
+	}
+	return 8
+}
+
+func TestWeeks(t *testing.T) {
+	// 2025-03-05 - среда
+	weeks := Weeks(time.Date(2025, time.March, 5, 15, 0, 0, 0, time.UTC), 3)
+	expected := [][2]int{{5, 9}, {10, 16}, {17, 23}}
+	if len(weeks) != len(expected) {
+		t.Fatalf("Weeks() returned %d weeks, expected %d", len(weeks), len(expected))
+	}
+	for i, w := range weeks {
+		if w.Start.Day() != expected[i][0] || w.End.Day() != expected[i][1] {
+			t.Errorf("week %d = %s - %s, expected %d - %d", i, w.Start.Format(time.DateOnly), w.End.Format(time.DateOnly), expected[i][0], expected[i][1])
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	date := func(d int) time.Time { return time.Date(2025, time.March, d, 0, 0, 0, 0, time.UTC) }
+	anna := Employee{ID: uuid.MustParse("6a0b7c2e-1f4d-4f2a-9a51-3b8e2d1c0f11"), Username: "anna", Direction: "QA"}
+	ivan := Employee{ID: uuid.MustParse("0c6f7c7e-5b0d-4b44-9f67-0f5f4a8f5d21"), Username: "ivan", Direction: "QA"}
+
+	// Недели 3-9 и 10-16 марта, у каждого по 40 часов в неделю
+	weeks := Weeks(date(3), 2)
+	hoursFor := func(*Employee) WorkingHoursFunc { return weekdayHours }
+
+	tests := []struct {
+		name        string
+		absences    []Absence
+		tasks       []Task
+		expected    map[string][2]Load
+		unscheduled float64
+	}{
+		{
+			name: "absence reduces available hours",
+			absences: []Absence{
+				{EmployeeID: anna.ID, Start: date(4), End: date(5)},
+				{EmployeeID: anna.ID, Start: date(10)},
+			},
+			expected: map[string][2]Load{
+				"anna": {{Available: 24}, {Available: 32}},
+				"ivan": {{Available: 40}, {Available: 40}},
+			},
+		},
+		{
+			name: "task spread across available days",
+			absences: []Absence{
+				{EmployeeID: anna.ID, Start: date(10), End: date(14)},
+			},
+			tasks: []Task{
+				// Рабочие дни 5-7 и 10-14 марта, но на второй неделе anna отсутствует
+				{EmployeeID: anna.ID, Remaining: 30, Start: date(5), End: date(14)},
+				{EmployeeID: ivan.ID, Remaining: 64, Start: date(3), End: date(7)},
+			},
+			expected: map[string][2]Load{
+				"anna": {{Available: 40, Allocated: 30}, {Available: 0}},
+				"ivan": {{Available: 40, Allocated: 64}, {Available: 40}},
+			},
+		},
+		{
+			name: "overdue task lands on first day, task without deadline is unscheduled",
+			tasks: []Task{
+				{EmployeeID: ivan.ID, Remaining: 10, Start: date(1), End: date(2)},
+				{EmployeeID: ivan.ID, Remaining: 5},
+				// Срок за горизонтом: в горизонт попадает только часть остатка
+				{EmployeeID: anna.ID, Remaining: 30, Start: date(10), End: date(21)},
+			},
+			expected: map[string][2]Load{
+				"anna": {{Available: 40}, {Available: 40, Allocated: 15}},
+				"ivan": {{Available: 40, Allocated: 10}, {Available: 40}},
+			},
+			unscheduled: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Compute(weeks, []Employee{anna, ivan}, tt.absences, tt.tasks, hoursFor)
+			if len(report.Employees) != 2 {
+				t.Fatalf("Compute() returned %d employees, expected 2", len(report.Employees))
+			}
+
+			total := [2]Load{}
+			for _, c := range report.Employees {
+				expected := tt.expected[c.Employee.Username]
+				for i := range c.Weeks {
+					got := c.Weeks[i].Load
+					if got.Available != expected[i].Available || got.Allocated != expected[i].Allocated {
+						t.Errorf("%s week %d = %+v, expected available %g allocated %g", c.Employee.Username, i, got, expected[i].Available, expected[i].Allocated)
+					}
+					if got.Overallocated != (expected[i].Allocated > expected[i].Available) {
+						t.Errorf("%s week %d overallocated = %v", c.Employee.Username, i, got.Overallocated)
+					}
+					total[i].add(expected[i])
+				}
+			}
+
+			if len(report.Directions) != 1 || report.Directions[0].Employees != 2 {
+				t.Fatalf("Compute() returned directions %+v, expected QA with 2 employees", report.Directions)
+			}
+			dir := report.Directions[0]
+			for i := range dir.Weeks {
+				if dir.Weeks[i].Available != total[i].Available || dir.Weeks[i].Allocated != total[i].Allocated {
+					t.Errorf("direction week %d = %+v, expected %+v", i, dir.Weeks[i].Load, total[i])
+				}
+			}
+			if dir.Unscheduled != tt.unscheduled {
+				t.Errorf("direction unscheduled = %g, expected %g", dir.Unscheduled, tt.unscheduled)
+			}
+		})
+	}
+}
+
+func TestComputeSortsByFreeHours(t *testing.T) {
+	date := func(d int) time.Time { return time.Date(2025, time.March, d, 0, 0, 0, 0, time.UTC) }
+	busy := Employee{ID: uuid.New(), Username: "busy", Direction: "Backend"}
+	free := Employee{ID: uuid.New(), Username: "free", Direction: "Frontend"}
+
+	report := Compute(Weeks(date(3), 1), []Employee{busy, free}, nil,
+		[]Task{{EmployeeID: busy.ID, Remaining: 50, Start: date(3), End: date(7)}},
+		func(*Employee) WorkingHoursFunc { return weekdayHours })
+
+	if report.Employees[0].Employee.Username != "free" || report.Employees[1].Total.Balance != -10 {
+		t.Errorf("Compute() employees = %+v, expected free first and busy overallocated by 10", report.Employees)
+	}
+	if report.Directions[0].Direction != "Backend" || !report.Directions[0].Total.Overallocated {
+		t.Errorf("Compute() directions = %+v, expected overallocated Backend first", report.Directions)
+	}
+}
+
+// TestComputeKeysTasksByProfileID: задачи сопоставляются с сотрудниками только по profile_id.
+// С Notion ID пользователя из tasks.executor_id задача не попадает ни к кому, и сотрудник выглядит свободным.
+func TestComputeKeysTasksByProfileID(t *testing.T) {
+	date := func(d int) time.Time { return time.Date(2025, time.March, d, 0, 0, 0, 0, time.UTC) }
+	profileID := uuid.MustParse("6a0b7c2e-1f4d-4f2a-9a51-3b8e2d1c0f11")
+	notionUserID := uuid.MustParse("1f2e3d4c-5b6a-4798-8a7b-6c5d4e3f2a10")
+	anna := Employee{ID: profileID, Username: "anna", Direction: "QA"}
+	hoursFor := func(*Employee) WorkingHoursFunc { return weekdayHours }
+
+	byProfile := Compute(Weeks(date(3), 1), []Employee{anna}, nil,
+		[]Task{{EmployeeID: profileID, Remaining: 20, Start: date(3), End: date(7)}}, hoursFor)
+	if got := byProfile.Employees[0].Total.Allocated; got != 20 {
+		t.Errorf("allocated by profile id = %g, expected 20", got)
+	}
+
+	byNotionUser := Compute(Weeks(date(3), 1), []Employee{anna}, nil,
+		[]Task{{EmployeeID: notionUserID, Remaining: 20, Start: date(3), End: date(7)}}, hoursFor)
+	if got := byNotionUser.Employees[0].Total.Allocated; got != 0 {
+		t.Errorf("allocated by notion user id = %g, expected the task to be unmatched", got)
+	}
+}
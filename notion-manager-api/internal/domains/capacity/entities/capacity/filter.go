@@ -0,0 +1,36 @@
+package capacity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Filter - горизонт планирования и отбор сотрудников. EmployeeID - profile_id сотрудника.
+type Filter struct {
+	From       time.Time `json:"from"`
+	Weeks      int       `json:"weeks"`
+	Direction  string    `json:"direction"`
+	EmployeeID uuid.UUID `json:"employee_id"`
+}
+
+func (f *Filter) Validate(maxWeeks int) error {
+	if f.From.IsZero() {
+		return fmt.Errorf("%w: from is required", ErrInvalidPeriod)
+	}
+	if f.Weeks < 1 || (maxWeeks > 0 && f.Weeks > maxWeeks) {
+		return fmt.Errorf("%w: weeks must be between 1 and %d", ErrInvalidPeriod, maxWeeks)
+	}
+	return nil
+}
+
+func (f *Filter) Match(e *Employee) bool {
+	if f.Direction != "" && e.Direction != f.Direction {
+		return false
+	}
+	if f.EmployeeID != uuid.Nil && e.ID != f.EmployeeID {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/capacity/entities/capacity"
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type employeeDB struct {
+	ID        uuid.UUID `db:"profile_id"`
+	Username  string    `db:"username"`
+	Direction string    `db:"direction"`
+	Geo       string    `db:"geo"`
+}
+
+// ListEmployees возвращает сотрудников с профилем, кроме сотрудников с excludedStatuses
+func (r *CapacityPostgresRepository) ListEmployees(ctx context.Context, excludedStatuses []string) ([]capacity.Employee, error) {
+	employeesDB := []employeeDB{}
+	if err := r.DB().SelectContext(ctx, &employeesDB, `
+		SELECT profile_id, username, direction, geo
+		FROM employees
+		WHERE profile_id <> '' AND NOT (status = ANY($1))
+		ORDER BY username
+	`, pq.Array(excludedStatuses)); err != nil {
+		slog.Error("Error listing capacity employees", "error", err)
+		return nil, err
+	}
+
+	employees := make([]capacity.Employee, 0, len(employeesDB))
+	for _, e := range employeesDB {
+		employees = append(employees, capacity.Employee(e))
+	}
+
+	return employees, nil
+}
+
+type absenceDB struct {
+	EmployeeID uuid.UUID `db:"employee_id"`
+	Start      time.Time `db:"start_time"`
+	End        time.Time `db:"end_time"`
+}
+
+// ListAbsences возвращает отсутствия, пересекающиеся с днями [from, to]
+func (r *CapacityPostgresRepository) ListAbsences(ctx context.Context, from, to time.Time) ([]capacity.Absence, error) {
+	absencesDB := []absenceDB{}
+	if err := r.DB().SelectContext(ctx, &absencesDB, `
+		SELECT employee_id, start_time, end_time
+		FROM weekdays
+		WHERE start_time::date <= $2::date AND GREATEST(start_time, end_time)::date >= $1::date
+	`, from, to); err != nil {
+		slog.Error("Error listing capacity absences", "error", err)
+		return nil, err
+	}
+
+	absences := make([]capacity.Absence, 0, len(absencesDB))
+	for _, a := range absencesDB {
+		absences = append(absences, capacity.Absence(a))
+	}
+
+	return absences, nil
+}
+
+type taskDB struct {
+	ID         uuid.UUID `db:"task_id"`
+	EmployeeID uuid.UUID `db:"executor_id"`
+	Remaining  float64   `db:"remaining"`
+	Start      time.Time `db:"start"`
+	End        time.Time `db:"end"`
+}
+
+// ListOpenTasks возвращает незавершенные задачи с неизрасходованной оценкой и сроком не раньше endFrom.
+// Задачи без срока возвращаются всегда. В tasks исполнитель хранится Notion ID пользователя,
+// а сотрудники отчета - по profile_id, поэтому исполнитель переводится в profile_id.
+func (r *CapacityPostgresRepository) ListOpenTasks(ctx context.Context, endFrom time.Time) ([]capacity.Task, error) {
+	tasksDB := []taskDB{}
+	if err := r.DB().SelectContext(ctx, &tasksDB, `
+		SELECT
+			tasks.task_id,
+			employees.profile_id AS executor_id,
+			GREATEST(tasks.estimate - tasks.total_hours, 0) AS remaining,
+			tasks.start,
+			tasks."end"
+		FROM tasks
+		JOIN employees ON employees.employee_id = tasks.executor_id
+		WHERE employees.profile_id <> ''
+			AND NOT (tasks.status = ANY($1))
+			AND tasks.estimate > tasks.total_hours
+			AND (tasks."end" >= $2 OR tasks."end" = '0001-01-01 00:00:00+00')
+	`, pq.Array([]string{string(task.StatusDone), string(task.StatusCancelled)}), endFrom); err != nil {
+		slog.Error("Error listing capacity tasks", "error", err)
+		return nil, err
+	}
+
+	tasks := make([]capacity.Task, 0, len(tasksDB))
+	for _, t := range tasksDB {
+		tasks = append(tasks, capacity.Task(t))
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,13 @@
+package postgres
+
+import (
+	"github.com/Corray333/employee_dashboard/internal/postgres"
+)
+
+type CapacityPostgresRepository struct {
+	*postgres.PostgresClient
+}
+
+func NewCapacityPostgresRepository(client *postgres.PostgresClient) *CapacityPostgresRepository {
+	return &CapacityPostgresRepository{client}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/calendar"
+	"github.com/Corray333/employee_dashboard/internal/domains/capacity/entities/capacity"
+	"github.com/spf13/viper"
+)
+
+type CapacityService struct {
+	employeesLister employeesLister
+	absencesLister  absencesLister
+	tasksLister     tasksLister
+	calendars       *calendar.Calendars
+}
+
+type postgresRepository interface {
+	employeesLister
+	absencesLister
+	tasksLister
+}
+
+type employeesLister interface {
+	ListEmployees(ctx context.Context, excludedStatuses []string) ([]capacity.Employee, error)
+}
+
+type absencesLister interface {
+	ListAbsences(ctx context.Context, from, to time.Time) ([]capacity.Absence, error)
+}
+
+type tasksLister interface {
+	ListOpenTasks(ctx context.Context, endFrom time.Time) ([]capacity.Task, error)
+}
+
+type option func(*CapacityService)
+
+func NewCapacityService(opts ...option) *CapacityService {
+	service := &CapacityService{
+		calendars: calendar.New(nil, nil, ""),
+	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	return service
+}
+
+func WithPostgresRepository(repository postgresRepository) option {
+	return func(s *CapacityService) {
+		s.employeesLister = repository
+		s.absencesLister = repository
+		s.tasksLister = repository
+	}
+}
+
+func WithCalendars(calendars *calendar.Calendars) option {
+	return func(s *CapacityService) {
+		s.calendars = calendars
+	}
+}
+
+// GetCapacity считает доступные и распределенные часы сотрудников и направлений на filter.Weeks недель вперед.
+// Просроченные задачи учитываются, только если срок прошел не раньше capacity.overdue_lookback назад.
+func (s *CapacityService) GetCapacity(ctx context.Context, filter *capacity.Filter) (*capacity.Report, error) {
+	if err := filter.Validate(viper.GetInt("capacity.max_weeks")); err != nil {
+		return nil, err
+	}
+
+	weeks := capacity.Weeks(filter.From, filter.Weeks)
+	from, to := weeks[0].Start, weeks[len(weeks)-1].End
+
+	all, err := s.employeesLister.ListEmployees(ctx, viper.GetStringSlice("capacity.excluded_statuses"))
+	if err != nil {
+		return nil, err
+	}
+	employees := []capacity.Employee{}
+	for i := range all {
+		if filter.Match(&all[i]) {
+			employees = append(employees, all[i])
+		}
+	}
+
+	absences, err := s.absencesLister.ListAbsences(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.tasksLister.ListOpenTasks(ctx, from.Add(-viper.GetDuration("capacity.overdue_lookback")))
+	if err != nil {
+		return nil, err
+	}
+
+	return capacity.Compute(weeks, employees, absences, tasks, func(e *capacity.Employee) capacity.WorkingHoursFunc {
+		return s.calendars.ForGeo(e.Geo).WorkingHours
+	}), nil
+}
@@ -0,0 +1,94 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/capacity/entities/capacity"
+	"github.com/Corray333/employee_dashboard/internal/transport"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type service interface {
+	GetCapacity(ctx context.Context, filter *capacity.Filter) (*capacity.Report, error)
+}
+
+type CapacityTransport struct {
+	service service
+	router  *chi.Mux
+}
+
+func NewCapacityTransport(router *chi.Mux, service service) *CapacityTransport {
+	return &CapacityTransport{
+		service: service,
+		router:  router,
+	}
+}
+
+func (t *CapacityTransport) RegisterRoutes() {
+	t.router.Group(func(r chi.Router) {
+		r.Use(transport.NewTaskTrackerAuthMiddleware())
+
+		r.Get("/api/capacity", t.getCapacity)
+	})
+}
+
+// parseFilter разбирает параметры from (YYYY-MM-DD, по умолчанию сегодня), weeks, direction и employee_id
+func parseFilter(r *http.Request) (*capacity.Filter, error) {
+	q := r.URL.Query()
+	filter := &capacity.Filter{
+		From:      time.Now(),
+		Weeks:     viper.GetInt("capacity.default_weeks"),
+		Direction: q.Get("direction"),
+	}
+
+	var err error
+	if from := q.Get("from"); from != "" {
+		if filter.From, err = time.Parse(time.DateOnly, from); err != nil {
+			return nil, err
+		}
+	}
+	if weeks := q.Get("weeks"); weeks != "" {
+		if filter.Weeks, err = strconv.Atoi(weeks); err != nil {
+			return nil, err
+		}
+	}
+	if id := q.Get("employee_id"); id != "" {
+		if filter.EmployeeID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+func (t *CapacityTransport) getCapacity(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := t.service.GetCapacity(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, capacity.ErrInvalidPeriod) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error getting capacity", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Error("Error encoding capacity", "error", err)
+	}
+}
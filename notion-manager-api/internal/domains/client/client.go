@@ -1,13 +1,16 @@
 package client
 
 import (
+	audit_service "github.com/Corray333/employee_dashboard/internal/domains/audit/service"
 	notion_repo "github.com/Corray333/employee_dashboard/internal/domains/client/repositories/notion"
 	postgres_repo "github.com/Corray333/employee_dashboard/internal/domains/client/repositories/postgres"
 	sheets_repo "github.com/Corray333/employee_dashboard/internal/domains/client/repositories/sheets"
 	"github.com/Corray333/employee_dashboard/internal/domains/client/service"
 	project_service "github.com/Corray333/employee_dashboard/internal/domains/project/service"
+	"github.com/Corray333/employee_dashboard/internal/export"
 	"github.com/Corray333/employee_dashboard/internal/postgres"
 	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+	"github.com/Corray333/employee_dashboard/internal/telegram"
 	notion "github.com/Corray333/employee_dashboard/pkg/notion/v2"
 )
 
@@ -19,10 +22,10 @@ type ClientController struct {
 	// transport    *transport.ClientTransport
 }
 
-func NewClientController(store *postgres.PostgresClient, notionClient *notion.Client, sheetsClient *gsheets.Client, projectService *project_service.ProjectService) *ClientController {
+func NewClientController(store *postgres.PostgresClient, notionClient *notion.Client, sheetsClient *gsheets.Client, tgClient *telegram.TelegramClient, projectService *project_service.ProjectService, auditService *audit_service.AuditService) *ClientController {
 	postgresRepo := postgres_repo.NewClientPostgresRepository(store)
 	notionRepo := notion_repo.NewClientNotionRepository(notionClient)
-	sheetsRepo := sheets_repo.NewClientSheetsRepository(sheetsClient)
+	sheetsRepo := sheets_repo.NewClientSheetsRepository(sheetsClient, export.NewSink("clients", sheetsClient, tgClient))
 
 	if projectService != nil {
 		service := service.NewClientService(
@@ -30,6 +33,7 @@ func NewClientController(store *postgres.PostgresClient, notionClient *notion.Cl
 			service.WithNotionRepository(notionRepo),
 			service.WithSheetsRepository(sheetsRepo),
 			service.WithProjectService(projectService),
+			service.WithChangesRecorder(auditService),
 		)
 
 		return &ClientController{
@@ -43,6 +47,7 @@ func NewClientController(store *postgres.PostgresClient, notionClient *notion.Cl
 			service.WithPostgresRepository(postgresRepo),
 			service.WithNotionRepository(notionRepo),
 			service.WithSheetsRepository(sheetsRepo),
+			service.WithChangesRecorder(auditService),
 		)
 
 		return &ClientController{
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +20,23 @@ type Client struct {
 	UniqueID   int64       `json:"unique_id"`
 	CreatedAt  time.Time   `json:"created_at"`
 	UpdatedAt  time.Time   `json:"updated_at"`
+	UpdatedBy  string      `json:"updated_by"`
 	ProjectIDs []uuid.UUID `json:"project_ids"`
 	Projects   []projectI  `json:"projects"`
 }
+
+// AuditFields возвращает поля клиента, изменения которых пишутся в журнал
+func (c *Client) AuditFields() map[string]string {
+	projectIDs := make([]string, 0, len(c.ProjectIDs))
+	for _, id := range c.ProjectIDs {
+		projectIDs = append(projectIDs, id.String())
+	}
+	slices.Sort(projectIDs)
+
+	return map[string]string{
+		"name":        c.Name,
+		"status":      string(c.Status),
+		"source":      c.Source,
+		"project_ids": strings.Join(projectIDs, ","),
+	}
+}
@@ -54,7 +54,10 @@ type Client struct {
 	ID             uuid.UUID `json:"id"`
 	CreatedTime    string    `json:"created_time"`
 	LastEditedTime string    `json:"last_edited_time"`
-	Properties     struct {
+	LastEditedBy   struct {
+		ID string `json:"id"`
+	} `json:"last_edited_by"`
+	Properties struct {
 		ID struct {
 			UniqueID struct {
 				Prefix string `json:"prefix"`
@@ -116,6 +119,7 @@ func (c *Client) ToEntity() *client.Client {
 		UniqueID:   uniqueID,
 		CreatedAt:  createdTime,
 		UpdatedAt:  lastEditedTime,
+		UpdatedBy:  c.LastEditedBy.ID,
 		ProjectIDs: projectIDs,
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/Corray333/employee_dashboard/internal/domains/client/entities/client"
+	"github.com/Corray333/employee_dashboard/internal/export"
 	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
 	"github.com/spf13/viper"
 	"google.golang.org/api/sheets/v4"
@@ -14,14 +15,18 @@ import (
 
 type ClientSheetsRepository struct {
 	client *gsheets.Client
+	sink   export.Sink
 }
 
-func NewClientSheetsRepository(client *gsheets.Client) *ClientSheetsRepository {
+func NewClientSheetsRepository(client *gsheets.Client, sink export.Sink) *ClientSheetsRepository {
 	return &ClientSheetsRepository{
 		client: client,
+		sink:   sink,
 	}
 }
 
+var clientHeader = []string{"Клиент", "Статус", "Источник", "ID"}
+
 func (r *ClientSheetsRepository) UpdateSheetsClients(ctx context.Context, sheetID string, clients []client.Client) error {
 	if len(clients) == 0 {
 		return nil
@@ -32,32 +37,24 @@ func (r *ClientSheetsRepository) UpdateSheetsClients(ctx context.Context, sheetI
 		sheetID = viper.GetString("sheets.id")
 	}
 
-	appendRange := viper.GetString("sheets.clients_sheet") + "!A2:"
-	rowLen := len(entityToSheetsClient(&clients[0]))
-	lastColLetter := string(rune('A' + rowLen - 1))
-	appendRange += lastColLetter
-
-	clearValues := &sheets.ClearValuesRequest{}
-	_, err := r.client.Svc().Spreadsheets.Values.Clear(sheetID, appendRange, clearValues).Do()
-	if err != nil {
-		slog.Error("Error clearing old values", "error", err)
-		return err
-	}
-
-	var vr sheets.ValueRange
-
+	rows := make([][]interface{}, 0, len(clients))
 	for _, client := range clients {
-		vr.Values = append(vr.Values, entityToSheetsClient(&client))
+		rows = append(rows, entityToSheetsClient(&client))
 	}
 
-	_, err = r.client.Svc().Spreadsheets.Values.Append(sheetID, appendRange, &vr).ValueInputOption("USER_ENTERED").InsertDataOption("INSERT_ROWS").Do()
-	if err != nil {
+	table := export.Table{Name: viper.GetString("sheets.clients_sheet"), Header: clientHeader}
+	if err := r.sink.WriteTable(ctx, sheetID, table, rows, gsheets.NotionLinkKey(0)); err != nil {
 		slog.Error("Error updating Google Sheets", "error", err)
 		return err
 	}
 
+	// Форматирование есть только у листа Google
+	if _, ok := r.sink.(*export.SheetsSink); !ok {
+		return nil
+	}
+
 	// Clear formatting to prevent inheriting bold text from header row
-	err = r.clearFormatting(ctx, sheetID, appendRange, len(clients))
+	err := r.clearFormatting(ctx, sheetID, len(clients))
 	if err != nil {
 		slog.Error("Error clearing formatting", "error", err)
 		// Don't return error here as data was successfully added
@@ -66,29 +63,13 @@ func (r *ClientSheetsRepository) UpdateSheetsClients(ctx context.Context, sheetI
 	return nil
 }
 
-// getSheetIDByName retrieves the sheet ID by sheet name
-func (r *ClientSheetsRepository) getSheetIDByName(ctx context.Context, spreadsheetID, sheetName string) (int64, error) {
-	spreadsheet, err := r.client.Svc().Spreadsheets.Get(spreadsheetID).Do()
-	if err != nil {
-		return 0, err
-	}
-
-	for _, sheet := range spreadsheet.Sheets {
-		if sheet.Properties.Title == sheetName {
-			return sheet.Properties.SheetId, nil
-		}
-	}
-
-	return 0, fmt.Errorf("sheet with name '%s' not found", sheetName)
-}
-
 // clearFormatting removes formatting from the specified range to prevent inheriting bold text from header
-func (r *ClientSheetsRepository) clearFormatting(ctx context.Context, sheetID, appendRange string, rowCount int) error {
+func (r *ClientSheetsRepository) clearFormatting(ctx context.Context, sheetID string, rowCount int) error {
 	// Get sheet name from config
 	sheetName := viper.GetString("sheets.clients_sheet")
 
 	// Get the actual sheet ID by name
-	actualSheetID, err := r.getSheetIDByName(ctx, sheetID, sheetName)
+	actualSheetID, err := r.client.SheetID(ctx, sheetID, sheetName)
 	if err != nil {
 		slog.Error("Error getting sheet ID by name", "error", err, "sheetName", sheetName)
 		// Fallback to sheet ID 0 if we can't find the sheet
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
 	"github.com/Corray333/employee_dashboard/internal/domains/client/entities/client"
 	"github.com/google/uuid"
 )
@@ -30,6 +31,10 @@ type sheetsClientsUpdater interface {
 	UpdateSheetsClients(ctx context.Context, sheetID string, clients []client.Client) error
 }
 
+type changesRecorder interface {
+	RecordChanges(ctx context.Context, entity audit.Entity, entityID uuid.UUID, before, after map[string]string, editedAt time.Time, editedBy string) error
+}
+
 func (s *ClientService) ClientsSync(ctx context.Context) {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
@@ -61,24 +66,56 @@ func (s *ClientService) updateClients(ctx context.Context) error {
 		return nil
 	}
 
-	ctx, err = s.transactioner.Begin(ctx)
+	txCtx, err := s.transactioner.Begin(ctx)
 	if err != nil {
 		return err
 	}
-	defer s.transactioner.Rollback(ctx)
+	defer s.transactioner.Rollback(txCtx)
 
-	for _, c := range clients {
-		if err := s.clientSetter.SetClient(ctx, &c); err != nil {
+	befores := make([]map[string]string, len(clients))
+	for i := range clients {
+		before, err := s.setClient(txCtx, &clients[i])
+		if err != nil {
 			return err
 		}
+		befores[i] = before
 	}
-	if err := s.transactioner.Commit(ctx); err != nil {
+	if err := s.transactioner.Commit(txCtx); err != nil {
 		return err
 	}
 
+	// Журнал пишется после коммита, чтобы ошибка записи не прерывала транзакцию синхронизации
+	if s.changesRecorder != nil {
+		for i, c := range clients {
+			if err := s.changesRecorder.RecordChanges(ctx, audit.EntityClient, c.ID, befores[i], c.AuditFields(), c.UpdatedAt, c.UpdatedBy); err != nil {
+				slog.Error("Error recording client changes", "error", err, "client_id", c.ID)
+			}
+		}
+	}
+
 	return nil
 }
 
+// setClient сохраняет клиента и возвращает его поля до изменения для журнала
+func (s *ClientService) setClient(ctx context.Context, c *client.Client) (map[string]string, error) {
+	var before map[string]string
+	if s.changesRecorder != nil {
+		old, err := s.clientLister.GetClientsByIDs(ctx, []uuid.UUID{c.ID})
+		if err != nil {
+			return nil, err
+		}
+		if len(old) > 0 {
+			before = old[0].AuditFields()
+		}
+	}
+
+	if err := s.clientSetter.SetClient(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return before, nil
+}
+
 func (s *ClientService) UpdateSheets(ctx context.Context) error {
 	clients, err := s.clientLister.ListClients(ctx, &client.Filter{})
 	if err != nil {
@@ -17,6 +17,7 @@ type ClientService struct {
 	clientLister               clientLister
 	sheetsClientsUpdater       sheetsClientsUpdater
 	projectsByIDsGetter        projectsByIDsGetter
+	changesRecorder            changesRecorder
 }
 
 type postgresRepository interface {
@@ -77,12 +78,14 @@ func WithProjectService(projectService projectsByIDsGetter) option {
 	}
 }
 
-func (s *ClientService) Run() {
-	go s.ClientsSync(context.Background())
+func WithChangesRecorder(recorder changesRecorder) option {
+	return func(s *ClientService) {
+		s.changesRecorder = recorder
+	}
 }
 
-func (s *ClientService) AcceptUpdate(ctx context.Context) {
-	go s.UpdateSheets(ctx)
+func (s *ClientService) Run() {
+	go s.ClientsSync(context.Background())
 }
 
 func (s *ClientService) GetClientsByIDs(ctx context.Context, clientIDs []uuid.UUID) ([]client.Client, error) {
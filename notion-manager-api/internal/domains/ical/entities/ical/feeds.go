@@ -0,0 +1,70 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Absence - отсутствие сотрудника из weekdays
+type Absence struct {
+	ID        uuid.UUID
+	Username  string
+	Category  string
+	Reason    string
+	Start     time.Time
+	End       time.Time
+	UpdatedAt time.Time
+}
+
+func (a *Absence) Event() Event {
+	return Event{
+		UID:         UID("absence", a.ID),
+		Summary:     fmt.Sprintf("%s: %s", a.Username, a.Category),
+		Description: a.Reason,
+		URL:         notionURL(a.ID),
+		Start:       a.Start,
+		End:         a.End,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+// Deadline - сроки задачи
+type Deadline struct {
+	TaskID      uuid.UUID
+	Title       string
+	Status      string
+	ProjectName string
+	Executor    string
+	Start       time.Time
+	End         time.Time
+	UpdatedAt   time.Time
+}
+
+func (d *Deadline) Event() Event {
+	summary := d.Title
+	if d.ProjectName != "" {
+		summary = fmt.Sprintf("[%s] %s", d.ProjectName, d.Title)
+	}
+
+	description := []string{"Статус: " + d.Status}
+	if d.Executor != "" {
+		description = append(description, "Исполнитель: "+d.Executor)
+	}
+
+	return Event{
+		UID:         UID("task", d.TaskID),
+		Summary:     summary,
+		Description: strings.Join(description, "\n"),
+		URL:         notionURL(d.TaskID),
+		Start:       d.Start,
+		End:         d.End,
+		UpdatedAt:   d.UpdatedAt,
+	}
+}
+
+func notionURL(id uuid.UUID) string {
+	return fmt.Sprintf("https://notion.so/%s", strings.ReplaceAll(id.String(), "-", ""))
+}
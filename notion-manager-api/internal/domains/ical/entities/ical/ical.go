@@ -0,0 +1,128 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+	// maxLineLength - максимальная длина строки в октетах по RFC 5545, длинные строки переносятся
+	maxLineLength = 75
+)
+
+// Event - событие календаря. Отсутствия и сроки задач выгружаются событиями на целый день,
+// поэтому End - последний день события включительно.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	UpdatedAt   time.Time
+}
+
+// Calendar - фид событий в формате iCalendar
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Marshal кодирует календарь по RFC 5545. now попадает в DTSTAMP событий без даты изменения.
+func (c *Calendar) Marshal(now time.Time) []byte {
+	b := &strings.Builder{}
+	line := func(name, value string) {
+		b.WriteString(fold(name + ":" + value))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//employee_dashboard//notion-manager-api//RU")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+
+	for _, e := range c.Events {
+		first, last := e.Days()
+		stamp := e.UpdatedAt
+		if stamp.IsZero() {
+			stamp = now
+		}
+
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp.UTC().Format(dateTimeFormat))
+		// DTEND для событий на целый день не входит в событие
+		line("DTSTART;VALUE=DATE", first.Format(dateFormat))
+		line("DTEND;VALUE=DATE", last.AddDate(0, 0, 1).Format(dateFormat))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// Days возвращает первый и последний день события. Событие без даты окончания длится один день.
+func (e *Event) Days() (time.Time, time.Time) {
+	first := day(e.Start)
+	if e.Start.IsZero() {
+		first = day(e.End)
+	}
+	last := first
+	if !e.End.IsZero() && day(e.End).After(first) {
+		last = day(e.End)
+	}
+	return first, last
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// fold переносит строку длиннее 75 октетов, не разрывая символы UTF-8
+func fold(s string) string {
+	if len(s) <= maxLineLength {
+		return s
+	}
+
+	b := &strings.Builder{}
+	size := 0
+	limit := maxLineLength
+	for _, r := range s {
+		n := len(string(r))
+		if size+n > limit {
+			b.WriteString("\r\n ")
+			size = 0
+			// Перенесенная строка начинается с пробела, который тоже считается
+			limit = maxLineLength - 1
+		}
+		b.WriteRune(r)
+		size += n
+	}
+	return b.String()
+}
+
+// UID строит глобально уникальный идентификатор события
+func UID(kind string, id fmt.Stringer) string {
+	return fmt.Sprintf("%s-%s@employee_dashboard", kind, id)
+}
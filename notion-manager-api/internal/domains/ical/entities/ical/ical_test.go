@@ -0,0 +1,105 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMarshal(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 9, 30, 0, 0, time.UTC)
+	id := uuid.MustParse("6a0b7c2e-1f4d-4f2a-9a51-3b8e2d1c0f11")
+
+	tests := []struct {
+		name     string
+		event    Event
+		expected []string
+	}{
+		{
+			name:  "one day without end",
+			event: Event{UID: UID("absence", id), Summary: "anna: Отгул", Start: time.Date(2025, time.March, 3, 10, 0, 0, 0, time.UTC)},
+			expected: []string{
+				"UID:absence-6a0b7c2e-1f4d-4f2a-9a51-3b8e2d1c0f11@employee_dashboard",
+				"DTSTAMP:20250301T093000Z",
+				"DTSTART;VALUE=DATE:20250303",
+				"DTEND;VALUE=DATE:20250304",
+				"SUMMARY:anna: Отгул",
+			},
+		},
+		{
+			name: "end is inclusive",
+			event: Event{
+				UID: "x", Summary: "Отпуск", Start: time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC), End: time.Date(2025, time.March, 7, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2025, time.February, 10, 12, 0, 0, 0, time.UTC),
+			},
+			expected: []string{
+				"DTSTAMP:20250210T120000Z",
+				"DTSTART;VALUE=DATE:20250303",
+				"DTEND;VALUE=DATE:20250308",
+			},
+		},
+		{
+			name:     "deadline without start",
+			event:    Event{UID: "x", Summary: "Задача", End: time.Date(2025, time.March, 14, 18, 0, 0, 0, time.UTC)},
+			expected: []string{"DTSTART;VALUE=DATE:20250314", "DTEND;VALUE=DATE:20250315"},
+		},
+		{
+			name:     "escaping",
+			event:    Event{UID: "x", Summary: "CRM; релиз, этап 2", Description: "строка 1\nстрока 2 \\ конец", Start: now},
+			expected: []string{`SUMMARY:CRM\; релиз\, этап 2`, `DESCRIPTION:строка 1\nстрока 2 \\ конец`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Calendar{Name: "Команда", Events: []Event{tt.event}}
+			got := string(c.Marshal(now))
+			if !strings.HasPrefix(got, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(got, "END:VEVENT\r\nEND:VCALENDAR\r\n") {
+				t.Fatalf("Marshal() has invalid envelope:\n%s", got)
+			}
+			lines := strings.Split(got, "\r\n")
+			for _, e := range tt.expected {
+				found := false
+				for _, l := range lines {
+					if l == e {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("Marshal() has no line %q:\n%s", e, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "short", value: "SUMMARY:Отпуск"},
+		{name: "ascii", value: "DESCRIPTION:" + strings.Repeat("a", 200)},
+		{name: "cyrillic", value: "SUMMARY:" + strings.Repeat("ж", 100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fold(tt.value)
+			lines := strings.Split(got, "\r\n")
+			for i, l := range lines {
+				if len(l) > maxLineLength {
+					t.Errorf("line %d has %d octets", i, len(l))
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Errorf("continuation line %d does not start with space", i)
+				}
+			}
+			if unfolded := strings.ReplaceAll(got, "\r\n ", ""); unfolded != tt.value {
+				t.Errorf("fold() changed value: %q", unfolded)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package ical
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTokenNotFound    = errors.New("ical token not found")
+	ErrEmployeeNotFound = errors.New("employee not found")
+)
+
+// tokenSize - 256 бит случайных данных, токен нельзя подобрать
+const tokenSize = 32
+
+// Token - персональный токен для подписки на календари. Сам токен не хранится, только его хэш.
+type Token struct {
+	EmployeeID uuid.UUID  `json:"employee_id"`
+	Token      string     `json:"token,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// NewToken генерирует новый токен сотрудника. employeeID - profile_id сотрудника, как в weekdays и tasks.
+func NewToken(employeeID uuid.UUID) (*Token, error) {
+	buf := make([]byte, tokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	return &Token{
+		EmployeeID: employeeID,
+		Token:      base64.RawURLEncoding.EncodeToString(buf),
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// HashToken возвращает хэш, по которому токен ищется в базе
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Feeds - ссылки на календари, доступные по токену
+type Feeds struct {
+	Team     string `json:"team"`
+	Personal string `json:"personal"`
+	Project  string `json:"project"`
+}
@@ -0,0 +1,40 @@
+package ical
+
+import (
+	postgres_repo "github.com/Corray333/employee_dashboard/internal/domains/ical/repositories/postgres"
+	"github.com/Corray333/employee_dashboard/internal/domains/ical/service"
+	"github.com/Corray333/employee_dashboard/internal/domains/ical/transport"
+	"github.com/Corray333/employee_dashboard/internal/postgres"
+	"github.com/go-chi/chi/v5"
+)
+
+type ICalController struct {
+	postgresRepo *postgres_repo.ICalPostgresRepository
+	service      *service.ICalService
+	transport    *transport.ICalTransport
+}
+
+func NewICalController(router *chi.Mux, store *postgres.PostgresClient) *ICalController {
+	postgresRepo := postgres_repo.NewICalPostgresRepository(store)
+
+	service := service.NewICalService(service.WithPostgresRepository(postgresRepo))
+
+	transport := transport.NewICalTransport(router, service)
+
+	return &ICalController{
+		postgresRepo: postgresRepo,
+		service:      service,
+		transport:    transport,
+	}
+}
+
+func (c *ICalController) Build() {
+	c.transport.RegisterRoutes()
+}
+
+func (c *ICalController) Run() {
+}
+
+func (c *ICalController) GetService() *service.ICalService {
+	return c.service
+}
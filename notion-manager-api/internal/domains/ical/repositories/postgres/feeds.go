@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/ical/entities/ical"
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type absenceDB struct {
+	ID        uuid.UUID `db:"weekday_id"`
+	Username  string    `db:"username"`
+	Category  string    `db:"category"`
+	Reason    string    `db:"reason"`
+	Start     time.Time `db:"start_time"`
+	End       time.Time `db:"end_time"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// ListAbsences возвращает отсутствия, пересекающиеся с [from, to). employeeID - profile_id сотрудника, uuid.Nil - все сотрудники.
+func (r *ICalPostgresRepository) ListAbsences(ctx context.Context, employeeID uuid.UUID, from, to time.Time) ([]ical.Absence, error) {
+	// Однодневное отсутствие хранится без даты окончания
+	builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select(
+			"weekdays.weekday_id", "COALESCE(employees.username, '') AS username", "weekdays.category",
+			"weekdays.reason", "weekdays.start_time", "weekdays.end_time", "weekdays.updated_at",
+		).
+		From("weekdays").
+		LeftJoin("employees ON employees.profile_id = weekdays.employee_id::text").
+		Where(squirrel.Lt{"weekdays.start_time": to}).
+		Where(squirrel.GtOrEq{"GREATEST(weekdays.start_time, weekdays.end_time)": from}).
+		OrderBy("weekdays.start_time", "username")
+
+	if employeeID != uuid.Nil {
+		builder = builder.Where(squirrel.Eq{"weekdays.employee_id": employeeID})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	absencesDB := []absenceDB{}
+	if err := r.DB().SelectContext(ctx, &absencesDB, query, args...); err != nil {
+		slog.Error("Error listing absences for ical", "error", err)
+		return nil, err
+	}
+
+	absences := make([]ical.Absence, 0, len(absencesDB))
+	for _, a := range absencesDB {
+		absences = append(absences, ical.Absence(a))
+	}
+
+	return absences, nil
+}
+
+type deadlineDB struct {
+	TaskID      uuid.UUID `db:"task_id"`
+	Title       string    `db:"title"`
+	Status      string    `db:"status"`
+	ProjectName string    `db:"project_name"`
+	Executor    string    `db:"executor"`
+	Start       time.Time `db:"start"`
+	End         time.Time `db:"end"`
+	UpdatedAt   time.Time `db:"last_edited_time"`
+}
+
+// ListDeadlines возвращает неотмененные задачи со сроком в [from, to).
+// executorID - profile_id исполнителя, projectID - проект, uuid.Nil - без отбора.
+func (r *ICalPostgresRepository) ListDeadlines(ctx context.Context, executorID, projectID uuid.UUID, from, to time.Time) ([]ical.Deadline, error) {
+	builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select(
+			"tasks.task_id", "tasks.title", "tasks.status", "COALESCE(projects.name, '') AS project_name",
+			"COALESCE(executor.username, '') AS executor", "tasks.start", `tasks."end"`, "tasks.last_edited_time",
+		).
+		From("tasks").
+		LeftJoin("projects ON projects.project_id = tasks.project_id::text").
+		LeftJoin("employees executor ON executor.employee_id = tasks.executor_id").
+		Where(squirrel.GtOrEq{`tasks."end"`: from}).
+		Where(squirrel.Lt{`tasks."end"`: to}).
+		Where(squirrel.NotEq{"tasks.status": string(task.StatusCancelled)}).
+		OrderBy(`tasks."end"`, "tasks.title")
+
+	if executorID != uuid.Nil {
+		// В tasks исполнитель хранится Notion ID пользователя, а фид выдается по профилю
+		builder = builder.Where(squirrel.Eq{"executor.profile_id": executorID.String()})
+	}
+	if projectID != uuid.Nil {
+		builder = builder.Where(squirrel.Eq{"tasks.project_id": projectID})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	deadlinesDB := []deadlineDB{}
+	if err := r.DB().SelectContext(ctx, &deadlinesDB, query, args...); err != nil {
+		slog.Error("Error listing deadlines for ical", "error", err)
+		return nil, err
+	}
+
+	deadlines := make([]ical.Deadline, 0, len(deadlinesDB))
+	for _, d := range deadlinesDB {
+		deadlines = append(deadlines, ical.Deadline(d))
+	}
+
+	return deadlines, nil
+}
@@ -0,0 +1,13 @@
+package postgres
+
+import (
+	"github.com/Corray333/employee_dashboard/internal/postgres"
+)
+
+type ICalPostgresRepository struct {
+	*postgres.PostgresClient
+}
+
+func NewICalPostgresRepository(client *postgres.PostgresClient) *ICalPostgresRepository {
+	return &ICalPostgresRepository{client}
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/ical/entities/ical"
+	"github.com/google/uuid"
+)
+
+// CreateToken сохраняет хэш нового токена сотрудника и отзывает его прежние токены
+func (r *ICalPostgresRepository) CreateToken(ctx context.Context, token *ical.Token) error {
+	query := `
+		WITH revoked AS (
+			UPDATE ical_tokens SET revoked_at = $3
+			WHERE employee_id = $2 AND revoked_at IS NULL
+		)
+		INSERT INTO ical_tokens (token_hash, employee_id, created_at)
+		SELECT $1, $2, $3
+		WHERE EXISTS (SELECT 1 FROM employees WHERE profile_id = $2::text)
+	`
+
+	res, err := r.DB().ExecContext(ctx, query, ical.HashToken(token.Token), token.EmployeeID, token.CreatedAt)
+	if err != nil {
+		slog.Error("Error creating ical token", "error", err)
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ical.ErrEmployeeNotFound
+	}
+
+	return nil
+}
+
+func (r *ICalPostgresRepository) RevokeTokens(ctx context.Context, employeeID uuid.UUID) error {
+	if _, err := r.DB().ExecContext(ctx, `UPDATE ical_tokens SET revoked_at = NOW() WHERE employee_id = $1 AND revoked_at IS NULL`, employeeID); err != nil {
+		slog.Error("Error revoking ical tokens", "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetTokenEmployee возвращает сотрудника, которому выдан действующий токен
+func (r *ICalPostgresRepository) GetTokenEmployee(ctx context.Context, token string) (uuid.UUID, error) {
+	employeeID := uuid.Nil
+	err := r.DB().GetContext(ctx, &employeeID, `SELECT employee_id FROM ical_tokens WHERE token_hash = $1 AND revoked_at IS NULL`, ical.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, ical.ErrTokenNotFound
+	}
+	if err != nil {
+		slog.Error("Error getting ical token", "error", err)
+		return uuid.Nil, err
+	}
+
+	return employeeID, nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/ical/entities/ical"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type ICalService struct {
+	tokenCreator        tokenCreator
+	tokensRevoker       tokensRevoker
+	tokenEmployeeGetter tokenEmployeeGetter
+	absencesLister      absencesLister
+	deadlinesLister     deadlinesLister
+}
+
+type postgresRepository interface {
+	tokenCreator
+	tokensRevoker
+	tokenEmployeeGetter
+	absencesLister
+	deadlinesLister
+}
+
+type tokenCreator interface {
+	CreateToken(ctx context.Context, token *ical.Token) error
+}
+
+type tokensRevoker interface {
+	RevokeTokens(ctx context.Context, employeeID uuid.UUID) error
+}
+
+type tokenEmployeeGetter interface {
+	GetTokenEmployee(ctx context.Context, token string) (uuid.UUID, error)
+}
+
+type absencesLister interface {
+	ListAbsences(ctx context.Context, employeeID uuid.UUID, from, to time.Time) ([]ical.Absence, error)
+}
+
+type deadlinesLister interface {
+	ListDeadlines(ctx context.Context, executorID, projectID uuid.UUID, from, to time.Time) ([]ical.Deadline, error)
+}
+
+type option func(*ICalService)
+
+func NewICalService(opts ...option) *ICalService {
+	service := &ICalService{}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	return service
+}
+
+func WithPostgresRepository(repository postgresRepository) option {
+	return func(s *ICalService) {
+		s.tokenCreator = repository
+		s.tokensRevoker = repository
+		s.tokenEmployeeGetter = repository
+		s.absencesLister = repository
+		s.deadlinesLister = repository
+	}
+}
+
+// IssueToken выдает сотруднику новый токен вместо прежних и возвращает ссылки на его календари
+func (s *ICalService) IssueToken(ctx context.Context, employeeID uuid.UUID) (*ical.Token, *ical.Feeds, error) {
+	token, err := ical.NewToken(employeeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.tokenCreator.CreateToken(ctx, token); err != nil {
+		return nil, nil, err
+	}
+
+	base := fmt.Sprintf("%s/api/ical/%s", viper.GetString("server.public_url"), token.Token)
+	return token, &ical.Feeds{
+		Team:     base + "/team.ics",
+		Personal: base + "/me.ics",
+		Project:  base + "/projects/{project_id}.ics",
+	}, nil
+}
+
+func (s *ICalService) RevokeTokens(ctx context.Context, employeeID uuid.UUID) error {
+	return s.tokensRevoker.RevokeTokens(ctx, employeeID)
+}
+
+// feedPeriod - период, за который выгружаются события: ical.past_days назад и ical.future_days вперед
+func feedPeriod() (time.Time, time.Time) {
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	return now.AddDate(0, 0, -viper.GetInt("ical.past_days")), now.AddDate(0, 0, viper.GetInt("ical.future_days")+1)
+}
+
+// TeamFeed возвращает календарь отсутствий всей команды
+func (s *ICalService) TeamFeed(ctx context.Context, token string) ([]byte, error) {
+	if _, err := s.tokenEmployeeGetter.GetTokenEmployee(ctx, token); err != nil {
+		return nil, err
+	}
+
+	from, to := feedPeriod()
+	absences, err := s.absencesLister.ListAbsences(ctx, uuid.Nil, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &ical.Calendar{Name: "Отсутствия команды"}
+	for i := range absences {
+		calendar.Events = append(calendar.Events, absences[i].Event())
+	}
+
+	return calendar.Marshal(time.Now()), nil
+}
+
+// PersonalFeed возвращает отсутствия владельца токена и сроки задач, где он исполнитель
+func (s *ICalService) PersonalFeed(ctx context.Context, token string) ([]byte, error) {
+	employeeID, err := s.tokenEmployeeGetter.GetTokenEmployee(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := feedPeriod()
+	absences, err := s.absencesLister.ListAbsences(ctx, employeeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	deadlines, err := s.deadlinesLister.ListDeadlines(ctx, employeeID, uuid.Nil, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &ical.Calendar{Name: "Мои отсутствия и задачи"}
+	for i := range absences {
+		calendar.Events = append(calendar.Events, absences[i].Event())
+	}
+	for i := range deadlines {
+		calendar.Events = append(calendar.Events, deadlines[i].Event())
+	}
+
+	return calendar.Marshal(time.Now()), nil
+}
+
+// ProjectFeed возвращает сроки задач проекта
+func (s *ICalService) ProjectFeed(ctx context.Context, token string, projectID uuid.UUID) ([]byte, error) {
+	if _, err := s.tokenEmployeeGetter.GetTokenEmployee(ctx, token); err != nil {
+		return nil, err
+	}
+
+	from, to := feedPeriod()
+	deadlines, err := s.deadlinesLister.ListDeadlines(ctx, uuid.Nil, projectID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &ical.Calendar{Name: "Сроки задач проекта"}
+	if len(deadlines) > 0 && deadlines[0].ProjectName != "" {
+		calendar.Name = "Сроки задач: " + deadlines[0].ProjectName
+	}
+	for i := range deadlines {
+		calendar.Events = append(calendar.Events, deadlines[i].Event())
+	}
+
+	return calendar.Marshal(time.Now()), nil
+}
@@ -0,0 +1,137 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/ical/entities/ical"
+	"github.com/Corray333/employee_dashboard/internal/transport"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type service interface {
+	IssueToken(ctx context.Context, employeeID uuid.UUID) (*ical.Token, *ical.Feeds, error)
+	RevokeTokens(ctx context.Context, employeeID uuid.UUID) error
+	TeamFeed(ctx context.Context, token string) ([]byte, error)
+	PersonalFeed(ctx context.Context, token string) ([]byte, error)
+	ProjectFeed(ctx context.Context, token string, projectID uuid.UUID) ([]byte, error)
+}
+
+type ICalTransport struct {
+	service service
+	router  *chi.Mux
+}
+
+func NewICalTransport(router *chi.Mux, service service) *ICalTransport {
+	t := &ICalTransport{
+		service: service,
+		router:  router,
+	}
+
+	return t
+}
+
+func (t *ICalTransport) RegisterRoutes() {
+	t.router.Group(func(r chi.Router) {
+		r.Use(transport.NewTaskTrackerAuthMiddleware())
+
+		r.Post("/api/ical/tokens/{employeeID}", t.issueToken)
+		r.Delete("/api/ical/tokens/{employeeID}", t.revokeTokens)
+	})
+
+	// Календарные клиенты не умеют передавать заголовки, поэтому фиды защищены токеном в ссылке.
+	// Фиды лежат под /api: nginx проксирует в сервис только его.
+	t.router.Get("/api/ical/{token}/team.ics", t.teamFeed)
+	t.router.Get("/api/ical/{token}/me.ics", t.personalFeed)
+	t.router.Get("/api/ical/{token}/projects/{projectID}.ics", t.projectFeed)
+}
+
+func (t *ICalTransport) issueToken(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := uuid.Parse(chi.URLParam(r, "employeeID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token, feeds, err := t.service.IssueToken(r.Context(), employeeID)
+	if err != nil {
+		if errors.Is(err, ical.ErrEmployeeNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		slog.Error("Error issuing ical token", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := struct {
+		*ical.Token
+		Feeds *ical.Feeds `json:"feeds"`
+	}{token, feeds}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("Error encoding ical token", "error", err)
+	}
+}
+
+func (t *ICalTransport) revokeTokens(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := uuid.Parse(chi.URLParam(r, "employeeID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.RevokeTokens(r.Context(), employeeID); err != nil {
+		slog.Error("Error revoking ical tokens", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *ICalTransport) teamFeed(w http.ResponseWriter, r *http.Request) {
+	feed, err := t.service.TeamFeed(r.Context(), chi.URLParam(r, "token"))
+	writeFeed(w, "team.ics", feed, err)
+}
+
+func (t *ICalTransport) personalFeed(w http.ResponseWriter, r *http.Request) {
+	feed, err := t.service.PersonalFeed(r.Context(), chi.URLParam(r, "token"))
+	writeFeed(w, "me.ics", feed, err)
+}
+
+func (t *ICalTransport) projectFeed(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	feed, err := t.service.ProjectFeed(r.Context(), chi.URLParam(r, "token"), projectID)
+	writeFeed(w, "project.ics", feed, err)
+}
+
+func writeFeed(w http.ResponseWriter, filename string, feed []byte, err error) {
+	if err != nil {
+		// Не различаем отозванный и несуществующий токен
+		if errors.Is(err, ical.ErrTokenNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		slog.Error("Error building ical feed", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="`+filename+`"`)
+	if _, err := w.Write(feed); err != nil {
+		slog.Error("Error writing ical feed", "error", err)
+	}
+}
@@ -0,0 +1,122 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrJobNotFound    = errors.New("job not found")
+	ErrUnknownJobKind = errors.New("unknown job kind")
+)
+
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// StepFunc - шаг задания. Шаги выполняются по порядку, ошибка шага не останавливает следующие.
+type StepFunc struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type Step struct {
+	Name       string     `json:"name"`
+	Status     Status     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Job - фоновое задание. Задания с одинаковым Key выполняются строго по очереди,
+// а повторный запрос того же Kind и Key, пока задание еще в очереди, возвращает его же.
+type Job struct {
+	ID         uuid.UUID  `json:"id"`
+	Kind       string     `json:"kind"`
+	Key        string     `json:"key"`
+	Status     Status     `json:"status"`
+	Steps      []Step     `json:"steps"`
+	Progress   int        `json:"progress"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+func New(kind, key string, steps []StepFunc, now time.Time) *Job {
+	j := &Job{
+		ID:        uuid.New(),
+		Kind:      kind,
+		Key:       key,
+		Status:    StatusQueued,
+		CreatedAt: now,
+	}
+	j.SetSteps(steps)
+	return j
+}
+
+// SetSteps заменяет шаги задания, например если набор шагов изменился, пока задание ждало в очереди
+func (j *Job) SetSteps(steps []StepFunc) {
+	j.Steps = make([]Step, 0, len(steps))
+	for _, step := range steps {
+		j.Steps = append(j.Steps, Step{Name: step.Name, Status: StatusQueued})
+	}
+	j.Progress = 0
+}
+
+func (j *Job) StartStep(i int, now time.Time) {
+	j.Steps[i].Status = StatusRunning
+	j.Steps[i].StartedAt = &now
+}
+
+func (j *Job) FinishStep(i int, err error, now time.Time) {
+	j.Steps[i].FinishedAt = &now
+	j.Steps[i].Status = StatusDone
+	if err != nil {
+		j.Steps[i].Status = StatusFailed
+		j.Steps[i].Error = err.Error()
+	}
+
+	finished := 0
+	for _, step := range j.Steps {
+		if step.Status == StatusDone || step.Status == StatusFailed {
+			finished++
+		}
+	}
+	j.Progress = finished * 100 / len(j.Steps)
+}
+
+// Finish завершает задание: оно считается проваленным, если провалился хотя бы один шаг
+func (j *Job) Finish(now time.Time) {
+	j.FinishedAt = &now
+	j.Progress = 100
+
+	errs := []string{}
+	for _, step := range j.Steps {
+		if step.Status == StatusFailed {
+			errs = append(errs, step.Name+": "+step.Error)
+		}
+	}
+
+	j.Status = StatusDone
+	if len(errs) > 0 {
+		j.Status = StatusFailed
+		j.Error = strings.Join(errs, "; ")
+	}
+}
+
+// Fail завершает задание ошибкой, не относящейся к конкретному шагу
+func (j *Job) Fail(err error, now time.Time) {
+	j.FinishedAt = &now
+	j.Status = StatusFailed
+	j.Error = err.Error()
+}
@@ -0,0 +1,64 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobSteps(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	noop := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name     string
+		errs     []error
+		progress []int
+		status   Status
+		err      string
+	}{
+		{
+			name:     "all steps succeed",
+			errs:     []error{nil, nil, nil},
+			progress: []int{33, 66, 100},
+			status:   StatusDone,
+		},
+		{
+			name:     "failed steps are reported",
+			errs:     []error{nil, errors.New("quota"), errors.New("not found")},
+			progress: []int{33, 66, 100},
+			status:   StatusFailed,
+			err:      "step1: quota; step2: not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := []StepFunc{{Name: "step0", Run: noop}, {Name: "step1", Run: noop}, {Name: "step2", Run: noop}}
+			j := New("sheets_export", "sheet", steps, now)
+			if j.Status != StatusQueued || len(j.Steps) != 3 {
+				t.Fatalf("unexpected new job: %+v", j)
+			}
+
+			for i, err := range tt.errs {
+				j.StartStep(i, now)
+				if j.Steps[i].Status != StatusRunning {
+					t.Errorf("step %d status = %s, want running", i, j.Steps[i].Status)
+				}
+				j.FinishStep(i, err, now)
+				if j.Progress != tt.progress[i] {
+					t.Errorf("progress after step %d = %d, want %d", i, j.Progress, tt.progress[i])
+				}
+			}
+
+			j.Finish(now)
+			if j.Status != tt.status {
+				t.Errorf("status = %s, want %s", j.Status, tt.status)
+			}
+			if j.Error != tt.err {
+				t.Errorf("error = %q, want %q", j.Error, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package job
+
+import (
+	postgres_repo "github.com/Corray333/employee_dashboard/internal/domains/job/repositories/postgres"
+	"github.com/Corray333/employee_dashboard/internal/domains/job/service"
+	"github.com/Corray333/employee_dashboard/internal/postgres"
+)
+
+type JobController struct {
+	postgresRepo *postgres_repo.JobPostgresRepository
+	service      *service.JobService
+}
+
+func NewJobController(store *postgres.PostgresClient) *JobController {
+	postgresRepo := postgres_repo.NewJobPostgresRepository(store)
+
+	service := service.NewJobService(service.WithPostgresRepository(postgresRepo))
+
+	return &JobController{
+		postgresRepo: postgresRepo,
+		service:      service,
+	}
+}
+
+func (c *JobController) Build() {
+}
+
+func (c *JobController) Run() {
+	c.service.Run()
+}
+
+func (c *JobController) GetService() *service.JobService {
+	return c.service
+}
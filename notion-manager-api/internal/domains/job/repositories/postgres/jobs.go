@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/job/entities/job"
+	"github.com/google/uuid"
+)
+
+type jobDB struct {
+	ID         uuid.UUID  `db:"job_id"`
+	Kind       string     `db:"kind"`
+	Key        string     `db:"key"`
+	Status     string     `db:"status"`
+	Steps      []byte     `db:"steps"`
+	Progress   int        `db:"progress"`
+	Error      string     `db:"error"`
+	CreatedAt  time.Time  `db:"created_at"`
+	StartedAt  *time.Time `db:"started_at"`
+	FinishedAt *time.Time `db:"finished_at"`
+}
+
+const jobColumns = `job_id, kind, key, status, steps, progress, error, created_at, started_at, finished_at`
+
+func (j *jobDB) toEntity() (*job.Job, error) {
+	steps := []job.Step{}
+	if err := json.Unmarshal(j.Steps, &steps); err != nil {
+		return nil, err
+	}
+
+	return &job.Job{
+		ID:         j.ID,
+		Kind:       j.Kind,
+		Key:        j.Key,
+		Status:     job.Status(j.Status),
+		Steps:      steps,
+		Progress:   j.Progress,
+		Error:      j.Error,
+		CreatedAt:  j.CreatedAt,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+	}, nil
+}
+
+// CreateOrGetQueuedJob ставит задание в очередь. Если задание того же вида для того же ключа
+// уже ждет в очереди, новое не создается и возвращается ожидающее.
+func (r *JobPostgresRepository) CreateOrGetQueuedJob(ctx context.Context, j *job.Job) (*job.Job, error) {
+	steps, err := json.Marshal(j.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.DB().ExecContext(ctx, `
+		INSERT INTO jobs (job_id, kind, key, status, steps, progress, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (kind, key) WHERE status = 'queued' DO NOTHING
+	`, j.ID, j.Kind, j.Key, string(job.StatusQueued), steps, j.Progress, j.CreatedAt); err != nil {
+		slog.Error("Error creating job", "error", err)
+		return nil, err
+	}
+
+	queued := jobDB{}
+	if err := r.DB().GetContext(ctx, &queued, `SELECT `+jobColumns+` FROM jobs WHERE kind = $1 AND key = $2 AND status = $3`, j.Kind, j.Key, string(job.StatusQueued)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Задание успели взять в работу между вставкой и чтением
+			return r.GetJob(ctx, j.ID)
+		}
+		slog.Error("Error getting queued job", "error", err)
+		return nil, err
+	}
+
+	return queued.toEntity()
+}
+
+func (r *JobPostgresRepository) GetJob(ctx context.Context, id uuid.UUID) (*job.Job, error) {
+	j := jobDB{}
+	if err := r.DB().GetContext(ctx, &j, `SELECT `+jobColumns+` FROM jobs WHERE job_id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, job.ErrJobNotFound
+		}
+		slog.Error("Error getting job", "error", err)
+		return nil, err
+	}
+
+	return j.toEntity()
+}
+
+// ClaimNextJob переводит самое старое ожидающее задание с ключом key в работу. Возвращает nil, если очередь пуста.
+func (r *JobPostgresRepository) ClaimNextJob(ctx context.Context, key string) (*job.Job, error) {
+	j := jobDB{}
+	if err := r.DB().GetContext(ctx, &j, `
+		UPDATE jobs SET status = $2, started_at = NOW()
+		WHERE job_id = (
+			SELECT job_id FROM jobs WHERE key = $1 AND status = $3
+			ORDER BY created_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns, key, string(job.StatusRunning), string(job.StatusQueued)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		slog.Error("Error claiming job", "error", err)
+		return nil, err
+	}
+
+	return j.toEntity()
+}
+
+func (r *JobPostgresRepository) UpdateJob(ctx context.Context, j *job.Job) error {
+	steps, err := json.Marshal(j.Steps)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.DB().ExecContext(ctx, `
+		UPDATE jobs SET status = $2, steps = $3, progress = $4, error = $5, started_at = $6, finished_at = $7
+		WHERE job_id = $1
+	`, j.ID, string(j.Status), steps, j.Progress, j.Error, j.StartedAt, j.FinishedAt); err != nil {
+		slog.Error("Error updating job", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FailInterruptedJobs помечает проваленными задания, которые выполнялись в момент остановки сервиса
+func (r *JobPostgresRepository) FailInterruptedJobs(ctx context.Context, reason string) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		UPDATE jobs SET status = $1, error = $2, finished_at = NOW() WHERE status = $3
+	`, string(job.StatusFailed), reason, string(job.StatusRunning)); err != nil {
+		slog.Error("Error failing interrupted jobs", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *JobPostgresRepository) ListQueuedJobKeys(ctx context.Context) ([]string, error) {
+	keys := []string{}
+	if err := r.DB().SelectContext(ctx, &keys, `SELECT DISTINCT key FROM jobs WHERE status = $1`, string(job.StatusQueued)); err != nil {
+		slog.Error("Error listing queued job keys", "error", err)
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,13 @@
+package postgres
+
+import (
+	"github.com/Corray333/employee_dashboard/internal/postgres"
+)
+
+type JobPostgresRepository struct {
+	*postgres.PostgresClient
+}
+
+func NewJobPostgresRepository(client *postgres.PostgresClient) *JobPostgresRepository {
+	return &JobPostgresRepository{client}
+}
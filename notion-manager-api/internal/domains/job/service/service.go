@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/job/entities/job"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+const (
+	resultSaveAttempts = 3
+	resultSaveDelay    = time.Second
+)
+
+type JobService struct {
+	queuedJobCreator      queuedJobCreator
+	jobGetter             jobGetter
+	nextJobClaimer        nextJobClaimer
+	jobUpdater            jobUpdater
+	interruptedJobsFailer interruptedJobsFailer
+	queuedJobKeysLister   queuedJobKeysLister
+
+	mu      sync.Mutex
+	kinds   map[string]func() []job.StepFunc
+	runners map[string]bool
+}
+
+type postgresRepository interface {
+	queuedJobCreator
+	jobGetter
+	nextJobClaimer
+	jobUpdater
+	interruptedJobsFailer
+	queuedJobKeysLister
+}
+
+type queuedJobCreator interface {
+	CreateOrGetQueuedJob(ctx context.Context, j *job.Job) (*job.Job, error)
+}
+
+type jobGetter interface {
+	GetJob(ctx context.Context, id uuid.UUID) (*job.Job, error)
+}
+
+type nextJobClaimer interface {
+	ClaimNextJob(ctx context.Context, key string) (*job.Job, error)
+}
+
+type jobUpdater interface {
+	UpdateJob(ctx context.Context, j *job.Job) error
+}
+
+type interruptedJobsFailer interface {
+	FailInterruptedJobs(ctx context.Context, reason string) error
+}
+
+type queuedJobKeysLister interface {
+	ListQueuedJobKeys(ctx context.Context) ([]string, error)
+}
+
+type option func(*JobService)
+
+func NewJobService(opts ...option) *JobService {
+	service := &JobService{
+		kinds:   map[string]func() []job.StepFunc{},
+		runners: map[string]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	return service
+}
+
+func WithPostgresRepository(repository postgresRepository) option {
+	return func(s *JobService) {
+		s.queuedJobCreator = repository
+		s.jobGetter = repository
+		s.nextJobClaimer = repository
+		s.jobUpdater = repository
+		s.interruptedJobsFailer = repository
+		s.queuedJobKeysLister = repository
+	}
+}
+
+// RegisterKind задает шаги заданий вида kind. Шаги запрашиваются в момент запуска задания.
+func (s *JobService) RegisterKind(kind string, steps func() []job.StepFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kinds[kind] = steps
+}
+
+// Run завершает задания, прерванные перезапуском, и продолжает выполнение очереди
+func (s *JobService) Run() {
+	ctx := context.Background()
+
+	if err := s.interruptedJobsFailer.FailInterruptedJobs(ctx, "interrupted by restart"); err != nil {
+		return
+	}
+
+	keys, err := s.queuedJobKeysLister.ListQueuedJobKeys(ctx)
+	if err != nil {
+		return
+	}
+	for _, key := range keys {
+		s.startRunner(key)
+	}
+}
+
+// Enqueue ставит задание в очередь ключа key. Если такое же задание уже ждет своей очереди,
+// возвращается оно.
+func (s *JobService) Enqueue(ctx context.Context, kind, key string) (*job.Job, error) {
+	s.mu.Lock()
+	steps, ok := s.kinds[kind]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", job.ErrUnknownJobKind, kind)
+	}
+
+	j, err := s.queuedJobCreator.CreateOrGetQueuedJob(ctx, job.New(kind, key, steps(), time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	s.startRunner(key)
+
+	return j, nil
+}
+
+func (s *JobService) GetJob(ctx context.Context, id uuid.UUID) (*job.Job, error) {
+	return s.jobGetter.GetJob(ctx, id)
+}
+
+func (s *JobService) startRunner(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.runners[key] {
+		return
+	}
+	s.runners[key] = true
+	go s.runQueue(key)
+}
+
+// runQueue по одному выполняет задания ключа key, пока очередь не опустеет
+func (s *JobService) runQueue(key string) {
+	ctx := context.Background()
+
+	for {
+		j, err := s.nextJobClaimer.ClaimNextJob(ctx, key)
+		if err == nil && j == nil {
+			// Перепроверяем под блокировкой, чтобы не потерять задание, поставленное в очередь прямо сейчас
+			s.mu.Lock()
+			j, err = s.nextJobClaimer.ClaimNextJob(ctx, key)
+			if err == nil && j == nil {
+				delete(s.runners, key)
+				s.mu.Unlock()
+				return
+			}
+			s.mu.Unlock()
+		}
+		if err != nil {
+			s.mu.Lock()
+			delete(s.runners, key)
+			s.mu.Unlock()
+			return
+		}
+
+		if err := s.execute(ctx, j); err != nil {
+			slog.Error("Error saving job status", "job_id", j.ID, "kind", j.Kind, "status", j.Status, "error", err)
+		}
+	}
+}
+
+// execute выполняет шаги задания. Ошибки записи прогресса не прерывают шаги, но возвращаются
+// вместе с ошибкой записи итога, чтобы задание не оставалось в статусе running незамеченным.
+func (s *JobService) execute(ctx context.Context, j *job.Job) error {
+	s.mu.Lock()
+	stepsFn, ok := s.kinds[j.Kind]
+	s.mu.Unlock()
+
+	if !ok {
+		j.Fail(fmt.Errorf("%w: %s", job.ErrUnknownJobKind, j.Kind), time.Now())
+		return s.saveResult(j)
+	}
+
+	timeout := viper.GetDuration("jobs.timeout")
+	if timeout <= 0 {
+		timeout = 30 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var errs []error
+	update := func() {
+		if err := s.jobUpdater.UpdateJob(ctx, j); err != nil {
+			slog.Error("Error saving job progress", "job_id", j.ID, "kind", j.Kind, "error", err)
+			errs = append(errs, err)
+		}
+	}
+
+	steps := stepsFn()
+	j.SetSteps(steps)
+	update()
+
+	for i, step := range steps {
+		j.StartStep(i, time.Now())
+		update()
+
+		err := runStep(ctx, step)
+		if err != nil {
+			slog.Error("Job step failed", "job_id", j.ID, "kind", j.Kind, "step", step.Name, "error", err)
+		}
+
+		j.FinishStep(i, err, time.Now())
+		update()
+	}
+
+	j.Finish(time.Now())
+	if err := s.saveResult(j); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// saveResult сохраняет итог задания. Итог сохраняем даже если вышел таймаут и повторяем запись,
+// иначе задание останется в статусе running до перезапуска сервиса.
+func (s *JobService) saveResult(j *job.Job) error {
+	var err error
+	for attempt := 0; attempt < resultSaveAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(resultSaveDelay * time.Duration(attempt))
+		}
+		if err = s.jobUpdater.UpdateJob(context.Background(), j); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("save job result after %d attempts: %w", resultSaveAttempts, err)
+}
+
+func runStep(ctx context.Context, step job.StepFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return step.Run(ctx)
+}
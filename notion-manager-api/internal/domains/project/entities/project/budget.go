@@ -0,0 +1,96 @@
+package project
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidBudget = errors.New("invalid project budget")
+
+// Budget - бюджет проекта в часах и, опционально, в деньгах (часы * ставка)
+type Budget struct {
+	ProjectID uuid.UUID `json:"projectID"`
+	Hours     float64   `json:"hours"`
+	Money     float64   `json:"money"`
+	HourRate  float64   `json:"hourRate"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (b *Budget) Validate() error {
+	if b.Hours <= 0 {
+		return errors.Join(ErrInvalidBudget, errors.New("hours must be positive"))
+	}
+	if b.Money < 0 || b.HourRate < 0 {
+		return errors.Join(ErrInvalidBudget, errors.New("money and hour rate must not be negative"))
+	}
+	if b.Money > 0 && b.HourRate == 0 {
+		return errors.Join(ErrInvalidBudget, errors.New("hour rate is required for money budget"))
+	}
+	return nil
+}
+
+type BudgetStatus struct {
+	Budget
+	ProjectName string `json:"projectName"`
+	ManagerTgID int64  `json:"-"`
+
+	SpentHours     float64 `json:"spentHours"`
+	EstimatedHours float64 `json:"estimatedHours"`
+	SpentMoney     float64 `json:"spentMoney"`
+	// Consumption - израсходованная доля бюджета в процентах, по часам или деньгам, что больше
+	Consumption float64 `json:"consumption"`
+	// EstimateOverrun - оценки задач проекта уже превышают бюджет
+	EstimateOverrun bool `json:"estimateOverrun"`
+
+	// RecentHours - часы, списанные за последнее окно, BurnRate - они же в пересчете на неделю
+	RecentHours      float64    `json:"recentHours"`
+	BurnRate         float64    `json:"burnRate"`
+	ProjectedOverrun *time.Time `json:"projectedOverrun,omitempty"`
+}
+
+// Calculate считает расход бюджета и прогноз его исчерпания по часам,
+// списанным за последнее окно window
+func (s *BudgetStatus) Calculate(window time.Duration, now time.Time) {
+	s.SpentMoney = s.SpentHours * s.HourRate
+	s.Consumption = s.SpentHours / s.Hours * 100
+	if s.Money > 0 {
+		s.Consumption = max(s.Consumption, s.SpentMoney/s.Money*100)
+	}
+	s.EstimateOverrun = s.EstimatedHours > s.Hours
+
+	s.ProjectedOverrun = nil
+	if window <= 0 {
+		return
+	}
+	perDay := s.RecentHours * 24 / window.Hours()
+	s.BurnRate = math.Round(perDay*7*100) / 100
+
+	remaining := s.Hours - s.SpentHours
+	if s.Money > 0 && s.HourRate > 0 {
+		remaining = min(remaining, (s.Money-s.SpentMoney)/s.HourRate)
+	}
+	switch {
+	case remaining <= 0:
+		overrun := now
+		s.ProjectedOverrun = &overrun
+	case perDay > 0:
+		overrun := now.Add(time.Duration(remaining / perDay * float64(24*time.Hour))).Round(time.Minute)
+		s.ProjectedOverrun = &overrun
+	}
+}
+
+// ReachedThresholds возвращает достигнутые пороги расхода (в процентах) по возрастанию
+func (s *BudgetStatus) ReachedThresholds(thresholds []int) []int {
+	reached := []int{}
+	for _, threshold := range thresholds {
+		if s.Consumption >= float64(threshold) {
+			reached = append(reached, threshold)
+		}
+	}
+	sort.Ints(reached)
+	return reached
+}
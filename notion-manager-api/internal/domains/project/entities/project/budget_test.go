@@ -0,0 +1,87 @@
+package project
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudgetStatusCalculate(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	window := 28 * 24 * time.Hour
+
+	tests := []struct {
+		name        string
+		status      BudgetStatus
+		recentHours float64
+		consumption float64
+		burnRate    float64
+		overrun     *time.Time
+		thresholds  []int
+	}{
+		{
+			name:        "hours budget half spent",
+			status:      BudgetStatus{Budget: Budget{Hours: 200}, SpentHours: 100, EstimatedHours: 180},
+			recentHours: 40,
+			consumption: 50,
+			burnRate:    10,
+			overrun:     ptr(now.AddDate(0, 0, 70)),
+			thresholds:  []int{50},
+		},
+		{
+			name:        "money budget spent faster than hours",
+			status:      BudgetStatus{Budget: Budget{Hours: 200, Money: 100000, HourRate: 1000}, SpentHours: 90},
+			recentHours: 28,
+			consumption: 90,
+			burnRate:    7,
+			overrun:     ptr(now.AddDate(0, 0, 10)),
+			thresholds:  []int{50, 80},
+		},
+		{
+			name:        "budget exceeded",
+			status:      BudgetStatus{Budget: Budget{Hours: 100}, SpentHours: 120},
+			consumption: 120,
+			overrun:     ptr(now),
+			thresholds:  []int{50, 80, 100},
+		},
+		{
+			name:        "no recent work",
+			status:      BudgetStatus{Budget: Budget{Hours: 100}, SpentHours: 10},
+			consumption: 10,
+			thresholds:  []int{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.status.RecentHours = tc.recentHours
+			tc.status.Calculate(window, now)
+
+			if tc.status.Consumption != tc.consumption {
+				t.Errorf("expected consumption %v, got %v", tc.consumption, tc.status.Consumption)
+			}
+			if tc.status.BurnRate != tc.burnRate {
+				t.Errorf("expected burn rate %v, got %v", tc.burnRate, tc.status.BurnRate)
+			}
+			switch {
+			case tc.overrun == nil && tc.status.ProjectedOverrun != nil:
+				t.Errorf("expected no overrun, got %v", tc.status.ProjectedOverrun)
+			case tc.overrun != nil && (tc.status.ProjectedOverrun == nil || !tc.status.ProjectedOverrun.Equal(*tc.overrun)):
+				t.Errorf("expected overrun %v, got %v", tc.overrun, tc.status.ProjectedOverrun)
+			}
+
+			reached := tc.status.ReachedThresholds([]int{100, 50, 80})
+			if len(reached) != len(tc.thresholds) {
+				t.Fatalf("expected thresholds %v, got %v", tc.thresholds, reached)
+			}
+			for i := range reached {
+				if reached[i] != tc.thresholds[i] {
+					t.Errorf("expected thresholds %v, got %v", tc.thresholds, reached)
+				}
+			}
+		})
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
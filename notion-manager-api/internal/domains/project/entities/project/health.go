@@ -0,0 +1,137 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HealthLevel string
+
+const (
+	HealthGreen  HealthLevel = "green"
+	HealthYellow HealthLevel = "yellow"
+	HealthRed    HealthLevel = "red"
+)
+
+// HealthMetrics - сырые показатели проекта, из которых считается оценка здоровья
+type HealthMetrics struct {
+	ProjectID   uuid.UUID `json:"projectID"`
+	ProjectName string    `json:"projectName"`
+	ManagerTgID int64     `json:"-"`
+
+	OpenTasks             int `json:"openTasks"`
+	OverdueTasks          int `json:"overdueTasks"`
+	StaleTasks            int `json:"staleTasks"`
+	UnassignedTasks       int `json:"unassignedTasks"`
+	HighPriorityFeedbacks int `json:"highPriorityFeedbacks"`
+	// BudgetConsumption - расход бюджета в процентах, nil если бюджет не задан
+	BudgetConsumption *float64 `json:"budgetConsumption,omitempty"`
+}
+
+// HealthPenalty - штраф за каждую проблемную единицу, но не больше Max
+type HealthPenalty struct {
+	Each int `mapstructure:"each"`
+	Max  int `mapstructure:"max"`
+}
+
+func (p HealthPenalty) apply(count int) int {
+	return min(count*p.Each, p.Max)
+}
+
+// HealthRules - веса факторов и границы уровней. Оценка начинается со 100 и уменьшается на штрафы.
+type HealthRules struct {
+	Overdue           HealthPenalty `mapstructure:"overdue"`
+	Stale             HealthPenalty `mapstructure:"stale"`
+	Unassigned        HealthPenalty `mapstructure:"unassigned"`
+	Feedbacks         HealthPenalty `mapstructure:"feedbacks"`
+	BudgetWarnFrom    float64       `mapstructure:"budget_warn_from"`
+	BudgetWarnPenalty int           `mapstructure:"budget_warn_penalty"`
+	BudgetOverPenalty int           `mapstructure:"budget_over_penalty"`
+	GreenFrom         int           `mapstructure:"green_from"`
+	YellowFrom        int           `mapstructure:"yellow_from"`
+}
+
+func DefaultHealthRules() HealthRules {
+	return HealthRules{
+		Overdue:           HealthPenalty{Each: 10, Max: 30},
+		Stale:             HealthPenalty{Each: 5, Max: 20},
+		Unassigned:        HealthPenalty{Each: 3, Max: 15},
+		Feedbacks:         HealthPenalty{Each: 10, Max: 20},
+		BudgetWarnFrom:    80,
+		BudgetWarnPenalty: 15,
+		BudgetOverPenalty: 30,
+		GreenFrom:         80,
+		YellowFrom:        50,
+	}
+}
+
+// HealthFactor - причина снижения оценки
+type HealthFactor struct {
+	Code    string `json:"code"`
+	Penalty int    `json:"penalty"`
+	Reason  string `json:"reason"`
+}
+
+type Health struct {
+	HealthMetrics
+	Score   int            `json:"score"`
+	Level   HealthLevel    `json:"level"`
+	Factors []HealthFactor `json:"factors"`
+}
+
+// Explanation - причины снижения оценки одной строкой
+func (h *Health) Explanation() string {
+	reasons := make([]string, 0, len(h.Factors))
+	for _, f := range h.Factors {
+		reasons = append(reasons, f.Reason)
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func CalculateHealth(m HealthMetrics, rules HealthRules) Health {
+	h := Health{HealthMetrics: m, Score: 100, Factors: []HealthFactor{}}
+
+	add := func(code string, penalty int, reason string) {
+		if penalty <= 0 {
+			return
+		}
+		h.Score -= penalty
+		h.Factors = append(h.Factors, HealthFactor{Code: code, Penalty: penalty, Reason: reason})
+	}
+
+	add("overdue", rules.Overdue.apply(m.OverdueTasks), fmt.Sprintf("просрочено задач: %d", m.OverdueTasks))
+	if m.BudgetConsumption != nil {
+		switch consumption := *m.BudgetConsumption; {
+		case consumption >= 100:
+			add("budget", rules.BudgetOverPenalty, fmt.Sprintf("бюджет превышен: %.0f%%", consumption))
+		case consumption >= rules.BudgetWarnFrom:
+			add("budget", rules.BudgetWarnPenalty, fmt.Sprintf("израсходовано %.0f%% бюджета", consumption))
+		}
+	}
+	add("feedbacks", rules.Feedbacks.apply(m.HighPriorityFeedbacks), fmt.Sprintf("открытых приоритетных замечаний: %d", m.HighPriorityFeedbacks))
+	add("stale", rules.Stale.apply(m.StaleTasks), fmt.Sprintf("зависших задач: %d", m.StaleTasks))
+	add("unassigned", rules.Unassigned.apply(m.UnassignedTasks), fmt.Sprintf("задач без исполнителя: %d", m.UnassignedTasks))
+
+	h.Score = max(h.Score, 0)
+	switch {
+	case h.Score >= rules.GreenFrom:
+		h.Level = HealthGreen
+	case h.Score >= rules.YellowFrom:
+		h.Level = HealthYellow
+	default:
+		h.Level = HealthRed
+	}
+
+	return h
+}
+
+// HealthFilter - какие проекты оценивать и что считать зависшей задачей и приоритетным замечанием
+type HealthFilter struct {
+	ProjectStatuses        []string
+	StaleBefore            time.Time
+	FeedbackPriorities     []string
+	FeedbackClosedStatuses []string
+}
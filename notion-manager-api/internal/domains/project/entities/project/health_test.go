@@ -0,0 +1,83 @@
+package project
+
+import (
+	"testing"
+)
+
+func TestCalculateHealth(t *testing.T) {
+	rules := DefaultHealthRules()
+
+	tests := []struct {
+		name    string
+		metrics HealthMetrics
+		score   int
+		level   HealthLevel
+		factors []string
+	}{
+		{
+			name:    "healthy project",
+			metrics: HealthMetrics{OpenTasks: 10, BudgetConsumption: consumption(40.0)},
+			score:   100,
+			level:   HealthGreen,
+			factors: []string{},
+		},
+		{
+			name:    "one stale and two unassigned tasks",
+			metrics: HealthMetrics{StaleTasks: 1, UnassignedTasks: 2},
+			score:   89,
+			level:   HealthGreen,
+			factors: []string{"stale", "unassigned"},
+		},
+		{
+			name:    "overdue tasks and budget warning",
+			metrics: HealthMetrics{OverdueTasks: 2, BudgetConsumption: consumption(85.0)},
+			score:   65,
+			level:   HealthYellow,
+			factors: []string{"overdue", "budget"},
+		},
+		{
+			name: "penalties are capped",
+			metrics: HealthMetrics{
+				OverdueTasks:          10,
+				HighPriorityFeedbacks: 5,
+				StaleTasks:            10,
+				UnassignedTasks:       10,
+				BudgetConsumption:     consumption(120.0),
+			},
+			score:   0,
+			level:   HealthRed,
+			factors: []string{"overdue", "budget", "feedbacks", "stale", "unassigned"},
+		},
+		{
+			name:    "no budget is not penalized",
+			metrics: HealthMetrics{HighPriorityFeedbacks: 3, OverdueTasks: 3},
+			score:   50,
+			level:   HealthYellow,
+			factors: []string{"overdue", "feedbacks"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := CalculateHealth(tt.metrics, rules)
+			if h.Score != tt.score {
+				t.Errorf("score = %d, want %d", h.Score, tt.score)
+			}
+			if h.Level != tt.level {
+				t.Errorf("level = %s, want %s", h.Level, tt.level)
+			}
+			if len(h.Factors) != len(tt.factors) {
+				t.Fatalf("factors = %+v, want %v", h.Factors, tt.factors)
+			}
+			for i, code := range tt.factors {
+				if h.Factors[i].Code != code {
+					t.Errorf("factor %d = %s, want %s", i, h.Factors[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func consumption(v float64) *float64 {
+	return &v
+}
@@ -21,6 +21,7 @@ type Project struct {
 	UniqueID   int64          `json:"uniqueID"`
 	ClientID   *uuid.UUID     `json:"clientID,omitempty"`
 	Client     *client.Client `json:"client,omitempty"`
+	Health     *Health        `json:"health,omitempty"`
 
 	// TotalHours float64   `json:"totalHours"`
 	// CreatedAt  time.Time `json:"createdAt"`
@@ -0,0 +1,36 @@
+package project
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProjectNotFound     = errors.New("project not found")
+	ErrSheetsAlreadyLinked = errors.New("project already has a linked spreadsheet")
+)
+
+// ProvisionTarget - проект, для которого создается таблица, и почта менеджера, которому ее выдать
+type ProvisionTarget struct {
+	Project
+	ManagerEmail string
+}
+
+// ProvisionResult - итог создания таблицы для проекта. Error заполняется, если на каком-то шаге
+// произошла ошибка; SheetsLink при этом может быть уже заполнен, если таблица успела создаться.
+type ProvisionResult struct {
+	ProjectID   uuid.UUID `json:"projectID"`
+	ProjectName string    `json:"projectName"`
+	SheetsLink  string    `json:"sheetsLink,omitempty"`
+	SharedWith  string    `json:"sharedWith,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// SheetsUpdateResult - итог обновления таблицы одного проекта всеми ProjectSheetsUpdater
+type SheetsUpdateResult struct {
+	ProjectID   uuid.UUID `json:"projectID"`
+	ProjectName string    `json:"projectName"`
+	DurationMS  int64     `json:"durationMs"`
+	Errors      []string  `json:"errors,omitempty"`
+}
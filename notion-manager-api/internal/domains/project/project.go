@@ -4,11 +4,14 @@ import (
 	notion_repo "github.com/Corray333/employee_dashboard/internal/domains/project/repositories/notion"
 	postgres_repo "github.com/Corray333/employee_dashboard/internal/domains/project/repositories/postgres"
 	sheets_repo "github.com/Corray333/employee_dashboard/internal/domains/project/repositories/sheets"
+	tg_repo "github.com/Corray333/employee_dashboard/internal/domains/project/repositories/tg"
 	"github.com/Corray333/employee_dashboard/internal/domains/project/service"
 	"github.com/Corray333/employee_dashboard/internal/domains/project/transport"
 	client_service "github.com/Corray333/employee_dashboard/internal/domains/client/service"
+	"github.com/Corray333/employee_dashboard/internal/export"
 	"github.com/Corray333/employee_dashboard/internal/postgres"
 	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+	"github.com/Corray333/employee_dashboard/internal/telegram"
 	notion "github.com/Corray333/employee_dashboard/pkg/notion/v2"
 	"github.com/go-chi/chi/v5"
 )
@@ -20,16 +23,18 @@ type ProjectController struct {
 	transport    *transport.ProjectTransport
 }
 
-func NewProjectController(router *chi.Mux, store *postgres.PostgresClient, notionClient *notion.Client, sheetsClient *gsheets.Client, clientService *client_service.ClientService) *ProjectController {
+func NewProjectController(router *chi.Mux, store *postgres.PostgresClient, notionClient *notion.Client, sheetsClient *gsheets.Client, tgClient *telegram.TelegramClient, clientService *client_service.ClientService) *ProjectController {
 
 	postgresRepo := postgres_repo.NewProjectPostgresRepository(store)
 	notionRepo := notion_repo.NewProjectNotionRepository(notionClient)
-	sheetsRepo := sheets_repo.NewProjectSheetsRepository(sheetsClient)
+	sheetsRepo := sheets_repo.NewProjectSheetsRepository(sheetsClient, export.NewSink("projects", sheetsClient, tgClient))
+	tgRepo := tg_repo.NewProjectTelegramRepository(tgClient)
 
 	service := service.NewProjectService(
 		service.WithPostgresRepository(postgresRepo),
 		service.WithNotionRepository(notionRepo),
 		service.WithSheetsRepository(sheetsRepo),
+		service.WithTelegramRepository(tgRepo),
 		service.WithClientService(clientService),
 	)
 
@@ -53,7 +58,7 @@ func (c *ProjectController) Build() {
 }
 
 func (c *ProjectController) Run() {
-	// c.service.Run()
+	c.service.Run()
 }
 
 func (c *ProjectController) GetService() *service.ProjectService {
@@ -0,0 +1,24 @@
+package notion
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// SetProjectSheetsLink записывает ссылку на таблицу в поле GSL страницы проекта
+func (r *ProjectNotionRepository) SetProjectSheetsLink(ctx context.Context, projectID uuid.UUID, link string) error {
+	properties := map[string]interface{}{
+		"GSL": map[string]interface{}{
+			"url": link,
+		},
+	}
+
+	if _, err := r.client.UpdatePage(projectID.String(), properties); err != nil {
+		slog.Error("Error setting project sheets link in notion", "error", err, "project_id", projectID)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	"github.com/google/uuid"
+)
+
+type budgetStatusDB struct {
+	ProjectID      uuid.UUID `db:"project_id"`
+	ProjectName    string    `db:"project_name"`
+	Hours          float64   `db:"hours"`
+	Money          float64   `db:"money"`
+	HourRate       float64   `db:"hour_rate"`
+	UpdatedAt      time.Time `db:"updated_at"`
+	ManagerTgID    int64     `db:"manager_tg_id"`
+	SpentHours     float64   `db:"spent_hours"`
+	RecentHours    float64   `db:"recent_hours"`
+	EstimatedHours float64   `db:"estimated_hours"`
+}
+
+// SetBudget задает бюджет проекта. При изменении бюджета отправленные оповещения сбрасываются,
+// чтобы пороги считались заново.
+func (r *ProjectPostgresRepository) SetBudget(ctx context.Context, budget *project.Budget) error {
+	tx, isNew, err := r.GetTx(ctx)
+	if err != nil {
+		return err
+	}
+	if isNew {
+		defer tx.Rollback()
+	}
+
+	if err := tx.QueryRowxContext(ctx, `
+		INSERT INTO project_budgets (project_id, hours, money, hour_rate, updated_at) VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (project_id) DO UPDATE SET
+			hours = EXCLUDED.hours, money = EXCLUDED.money, hour_rate = EXCLUDED.hour_rate, updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`, budget.ProjectID, budget.Hours, budget.Money, budget.HourRate).Scan(&budget.UpdatedAt); err != nil {
+		slog.Error("Error setting project budget", "error", err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM project_budget_alerts WHERE project_id = $1`, budget.ProjectID); err != nil {
+		slog.Error("Error resetting project budget alerts", "error", err)
+		return err
+	}
+
+	if isNew {
+		return tx.Commit()
+	}
+
+	return nil
+}
+
+func (r *ProjectPostgresRepository) DeleteBudget(ctx context.Context, projectID uuid.UUID) error {
+	if _, err := r.DB().ExecContext(ctx, `DELETE FROM project_budgets WHERE project_id = $1`, projectID); err != nil {
+		slog.Error("Error deleting project budget", "error", err)
+		return err
+	}
+	return nil
+}
+
+// ListBudgetStatuses возвращает бюджеты проектов со списанными (всего и с recentFrom) и оцененными часами
+func (r *ProjectPostgresRepository) ListBudgetStatuses(ctx context.Context, recentFrom time.Time) ([]project.BudgetStatus, error) {
+	statusesDB := []budgetStatusDB{}
+	if err := r.DB().SelectContext(ctx, &statusesDB, `
+		SELECT
+			b.project_id, b.hours, b.money, b.hour_rate, b.updated_at,
+			COALESCE(p.name, '') AS project_name,
+			COALESCE(manager.tg_id, 0) AS manager_tg_id,
+			COALESCE((SELECT SUM(t.total_hours) FROM times t WHERE t.project_id = b.project_id), 0) AS spent_hours,
+			COALESCE((SELECT SUM(t.total_hours) FROM times t WHERE t.project_id = b.project_id AND t.work_date >= $1), 0) AS recent_hours,
+			COALESCE((SELECT SUM(tasks.estimate) FROM tasks WHERE tasks.project_id = b.project_id::text), 0) AS estimated_hours
+		FROM project_budgets b
+		LEFT JOIN projects p ON p.project_id = b.project_id::text
+		LEFT JOIN employees manager ON manager.profile_id = p.manager_id
+		ORDER BY p.name
+	`, recentFrom); err != nil {
+		slog.Error("Error listing project budget statuses", "error", err)
+		return nil, err
+	}
+
+	statuses := make([]project.BudgetStatus, 0, len(statusesDB))
+	for _, s := range statusesDB {
+		statuses = append(statuses, project.BudgetStatus{
+			Budget: project.Budget{
+				ProjectID: s.ProjectID,
+				Hours:     s.Hours,
+				Money:     s.Money,
+				HourRate:  s.HourRate,
+				UpdatedAt: s.UpdatedAt,
+			},
+			ProjectName:    s.ProjectName,
+			ManagerTgID:    s.ManagerTgID,
+			SpentHours:     s.SpentHours,
+			EstimatedHours: s.EstimatedHours,
+			RecentHours:    s.RecentHours,
+		})
+	}
+
+	return statuses, nil
+}
+
+// ListSentBudgetAlerts возвращает уже отправленные пороги по проектам
+func (r *ProjectPostgresRepository) ListSentBudgetAlerts(ctx context.Context) (map[uuid.UUID]map[int]bool, error) {
+	rows := []struct {
+		ProjectID uuid.UUID `db:"project_id"`
+		Threshold int       `db:"threshold"`
+	}{}
+	if err := r.DB().SelectContext(ctx, &rows, `SELECT project_id, threshold FROM project_budget_alerts`); err != nil {
+		slog.Error("Error listing project budget alerts", "error", err)
+		return nil, err
+	}
+
+	sent := map[uuid.UUID]map[int]bool{}
+	for _, row := range rows {
+		if sent[row.ProjectID] == nil {
+			sent[row.ProjectID] = map[int]bool{}
+		}
+		sent[row.ProjectID][row.Threshold] = true
+	}
+	return sent, nil
+}
+
+func (r *ProjectPostgresRepository) MarkBudgetAlertsSent(ctx context.Context, projectID uuid.UUID, thresholds []int, sentAt time.Time) error {
+	tx, isNew, err := r.GetTx(ctx)
+	if err != nil {
+		return err
+	}
+	if isNew {
+		defer tx.Rollback()
+	}
+
+	for _, threshold := range thresholds {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO project_budget_alerts (project_id, threshold, sent_at) VALUES ($1, $2, $3)
+			ON CONFLICT (project_id, threshold) DO NOTHING
+		`, projectID, threshold, sentAt); err != nil {
+			slog.Error("Error marking project budget alert sent", "error", err)
+			return err
+		}
+	}
+
+	if isNew {
+		return tx.Commit()
+	}
+
+	return nil
+}
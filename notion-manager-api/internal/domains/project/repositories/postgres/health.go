@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type healthMetricsDB struct {
+	ProjectID             uuid.UUID `db:"project_id"`
+	ProjectName           string    `db:"project_name"`
+	ManagerTgID           int64     `db:"manager_tg_id"`
+	OpenTasks             int       `db:"open_tasks"`
+	OverdueTasks          int       `db:"overdue_tasks"`
+	StaleTasks            int       `db:"stale_tasks"`
+	UnassignedTasks       int       `db:"unassigned_tasks"`
+	HighPriorityFeedbacks int       `db:"high_priority_feedbacks"`
+}
+
+// ListHealthMetrics считает по проектам незавершенные, просроченные, зависшие и неназначенные задачи
+// и открытые приоритетные замечания. Задачи на паузе и в формировании зависшими не считаются.
+func (r *ProjectPostgresRepository) ListHealthMetrics(ctx context.Context, filter project.HealthFilter) ([]project.HealthMetrics, error) {
+	query := `
+		WITH open_tasks AS (
+			SELECT
+				t.task_id, t.project_id, t.executor_id, t.status, t."end",
+				COALESCE((
+					SELECT MAX(a.edited_at) FROM audit_log a
+					WHERE a.entity = 'task' AND a.entity_id = t.task_id AND a.field = 'status'
+				), t.last_edited_time) AS status_since
+			FROM tasks t
+			WHERE t.status NOT IN ($1, $2)
+		)
+		SELECT
+			p.project_id,
+			p.name AS project_name,
+			COALESCE(manager.tg_id, 0) AS manager_tg_id,
+			COUNT(o.task_id) AS open_tasks,
+			COUNT(o.task_id) FILTER (WHERE o."end" > '1970-01-01' AND o."end" < NOW()) AS overdue_tasks,
+			COUNT(o.task_id) FILTER (WHERE o.status NOT IN ($3, $4) AND o.status_since < $5) AS stale_tasks,
+			COUNT(o.task_id) FILTER (WHERE o.executor_id IS NULL OR o.executor_id = $6) AS unassigned_tasks,
+			(
+				SELECT COUNT(*) FROM feedbacks f
+				WHERE f.project_id::text = p.project_id AND f.priority = ANY($7) AND NOT (f.status = ANY($8))
+			) AS high_priority_feedbacks
+		FROM projects p
+		LEFT JOIN employees manager ON manager.profile_id = p.manager_id
+		LEFT JOIN open_tasks o ON o.project_id::text = p.project_id
+		WHERE cardinality($9::text[]) = 0 OR p.status = ANY($9)
+		GROUP BY p.project_id, p.name, manager.tg_id
+		ORDER BY p.name
+	`
+
+	metricsDB := []healthMetricsDB{}
+	if err := r.DB().SelectContext(ctx, &metricsDB, query,
+		string(task.StatusDone), string(task.StatusCancelled),
+		string(task.StatusOnHold), string(task.StatusForming),
+		filter.StaleBefore, uuid.Nil,
+		pq.StringArray(filter.FeedbackPriorities), pq.StringArray(filter.FeedbackClosedStatuses),
+		pq.StringArray(filter.ProjectStatuses),
+	); err != nil {
+		slog.Error("Error listing project health metrics", "error", err)
+		return nil, err
+	}
+
+	metrics := make([]project.HealthMetrics, 0, len(metricsDB))
+	for _, m := range metricsDB {
+		metrics = append(metrics, project.HealthMetrics{
+			ProjectID:             m.ProjectID,
+			ProjectName:           m.ProjectName,
+			ManagerTgID:           m.ManagerTgID,
+			OpenTasks:             m.OpenTasks,
+			OverdueTasks:          m.OverdueTasks,
+			StaleTasks:            m.StaleTasks,
+			UnassignedTasks:       m.UnassignedTasks,
+			HighPriorityFeedbacks: m.HighPriorityFeedbacks,
+		})
+	}
+
+	return metrics, nil
+}
+
+// GetHealthSummarySentAt возвращает время отправки сводки за неделю, начинающуюся weekStart
+func (r *ProjectPostgresRepository) GetHealthSummarySentAt(ctx context.Context, weekStart time.Time) (*time.Time, error) {
+	var sentAt time.Time
+	if err := r.DB().GetContext(ctx, &sentAt, `SELECT sent_at FROM project_health_summaries WHERE week_start = $1`, weekStart); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		slog.Error("Error getting project health summary", "error", err)
+		return nil, err
+	}
+	return &sentAt, nil
+}
+
+func (r *ProjectPostgresRepository) MarkHealthSummarySent(ctx context.Context, weekStart, sentAt time.Time) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		INSERT INTO project_health_summaries (week_start, sent_at) VALUES ($1, $2)
+		ON CONFLICT (week_start) DO UPDATE SET sent_at = EXCLUDED.sent_at
+	`, weekStart, sentAt); err != nil {
+		slog.Error("Error marking project health summary sent", "error", err)
+		return err
+	}
+	return nil
+}
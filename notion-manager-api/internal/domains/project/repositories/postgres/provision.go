@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type provisionTargetDB struct {
+	projectPostgres
+	ManagerEmail string `db:"manager_email"`
+}
+
+const provisionTargetsQuery = `
+	SELECT
+		p.project_id, p.name, p.icon, p.icon_type, p.status, p.type, p.manager_id, p.sheets_link,
+		COALESCE(manager.email, '') AS manager_email
+	FROM projects p
+	LEFT JOIN employees manager ON manager.profile_id = p.manager_id
+`
+
+func (r *ProjectPostgresRepository) GetProvisionTarget(ctx context.Context, projectID uuid.UUID) (*project.ProvisionTarget, error) {
+	target := provisionTargetDB{}
+	if err := r.DB().GetContext(ctx, &target, provisionTargetsQuery+`WHERE p.project_id = $1`, projectID.String()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, project.ErrProjectNotFound
+		}
+		slog.Error("Error getting project for sheets provisioning", "error", err)
+		return nil, err
+	}
+
+	return &project.ProvisionTarget{Project: target.ToEntity(), ManagerEmail: target.ManagerEmail}, nil
+}
+
+// ListProvisionTargets возвращает проекты в статусах statuses, у которых нет ссылки на таблицу
+func (r *ProjectPostgresRepository) ListProvisionTargets(ctx context.Context, statuses []string) ([]project.ProvisionTarget, error) {
+	targetsDB := []provisionTargetDB{}
+	if err := r.DB().SelectContext(ctx, &targetsDB, provisionTargetsQuery+`
+		WHERE p.sheets_link = '' AND (cardinality($1::text[]) = 0 OR p.status = ANY($1))
+		ORDER BY p.name
+	`, pq.StringArray(statuses)); err != nil {
+		slog.Error("Error listing projects for sheets provisioning", "error", err)
+		return nil, err
+	}
+
+	targets := make([]project.ProvisionTarget, 0, len(targetsDB))
+	for _, t := range targetsDB {
+		targets = append(targets, project.ProvisionTarget{Project: t.ToEntity(), ManagerEmail: t.ManagerEmail})
+	}
+
+	return targets, nil
+}
+
+func (r *ProjectPostgresRepository) SetProjectSheetsLink(ctx context.Context, projectID uuid.UUID, link string) error {
+	if _, err := r.DB().ExecContext(ctx, `UPDATE projects SET sheets_link = $2 WHERE project_id = $1`, projectID.String(), link); err != nil {
+		slog.Error("Error setting project sheets link", "error", err)
+		return err
+	}
+	return nil
+}
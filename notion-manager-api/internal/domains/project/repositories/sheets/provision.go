@@ -0,0 +1,53 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+	"github.com/spf13/viper"
+	"google.golang.org/api/drive/v3"
+)
+
+// CopySpreadsheet копирует таблицу-шаблон и возвращает ID и ссылку на копию
+func (r *ProjectSheetsRepository) CopySpreadsheet(ctx context.Context, templateID, title string) (string, string, error) {
+	file := &drive.File{Name: title}
+	if folderID := viper.GetString("sheets.provisioning.folder_id"); folderID != "" {
+		file.Parents = []string{folderID}
+	}
+
+	copied, err := r.client.Drive().Files.Copy(templateID, file).SupportsAllDrives(true).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Error copying spreadsheet template", "error", err, "template_id", templateID)
+		return "", "", gsheets.WrapDriveError(err)
+	}
+
+	return copied.Id, fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/edit", copied.Id), nil
+}
+
+// ShareSpreadsheet выдает пользователю права на редактирование таблицы
+func (r *ProjectSheetsRepository) ShareSpreadsheet(ctx context.Context, spreadsheetID, email string) error {
+	permission := &drive.Permission{
+		Type:         "user",
+		Role:         "writer",
+		EmailAddress: email,
+	}
+
+	if _, err := r.client.Drive().Permissions.Create(spreadsheetID, permission).SupportsAllDrives(true).SendNotificationEmail(true).Context(ctx).Do(); err != nil {
+		slog.Error("Error sharing spreadsheet", "error", err, "spreadsheet_id", spreadsheetID, "email", email)
+		return gsheets.WrapDriveError(err)
+	}
+
+	return nil
+}
+
+// DeleteSpreadsheet удаляет копию, созданную при неудачном создании таблицы проекта
+func (r *ProjectSheetsRepository) DeleteSpreadsheet(ctx context.Context, spreadsheetID string) error {
+	if err := r.client.Drive().Files.Delete(spreadsheetID).SupportsAllDrives(true).Context(ctx).Do(); err != nil {
+		slog.Error("Error deleting spreadsheet", "error", err, "spreadsheet_id", spreadsheetID)
+		return gsheets.WrapDriveError(err)
+	}
+
+	return nil
+}
@@ -7,21 +7,25 @@ import (
 	"strings"
 
 	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	"github.com/Corray333/employee_dashboard/internal/export"
 	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
 	"github.com/spf13/viper"
-	"google.golang.org/api/sheets/v4"
 )
 
 type ProjectSheetsRepository struct {
 	client *gsheets.Client
+	sink   export.Sink
 }
 
-func NewProjectSheetsRepository(client *gsheets.Client) *ProjectSheetsRepository {
+func NewProjectSheetsRepository(client *gsheets.Client, sink export.Sink) *ProjectSheetsRepository {
 	return &ProjectSheetsRepository{
 		client: client,
+		sink:   sink,
 	}
 }
 
+var projectHeader = []string{"Проект", "Тип", "Менеджер", "Статус", "Клиент", "Здоровье", "Причины"}
+
 func (r *ProjectSheetsRepository) UpdateSheetsProjects(ctx context.Context, sheetID string, projects []project.Project) error {
 	if len(projects) == 0 {
 		return nil
@@ -32,26 +36,13 @@ func (r *ProjectSheetsRepository) UpdateSheetsProjects(ctx context.Context, shee
 		sheetID = viper.GetString("sheets.id")
 	}
 
-	appendRange := viper.GetString("sheets.projects_sheet") + "!A2:"
-	rowLen := len(entityToSheetsProject(&projects[0]))
-	lastColLetter := string(rune('A' + rowLen - 1))
-	appendRange += lastColLetter
-
-	clearValues := &sheets.ClearValuesRequest{}
-	_, err := r.client.Svc().Spreadsheets.Values.Clear(sheetID, appendRange, clearValues).Do()
-	if err != nil {
-		slog.Error("Error clearing old values", "error", err)
-		return err
-	}
-
-	var vr sheets.ValueRange
-
+	rows := make([][]interface{}, 0, len(projects))
 	for _, project := range projects {
-		vr.Values = append(vr.Values, entityToSheetsProject(&project))
+		rows = append(rows, entityToSheetsProject(&project))
 	}
 
-	_, err = r.client.Svc().Spreadsheets.Values.Append(sheetID, appendRange, &vr).ValueInputOption("USER_ENTERED").InsertDataOption("INSERT_ROWS").Do()
-	if err != nil {
+	table := export.Table{Name: viper.GetString("sheets.projects_sheet"), Header: projectHeader}
+	if err := r.sink.WriteTable(ctx, sheetID, table, rows, gsheets.NotionLinkKey(0)); err != nil {
 		slog.Error("Error updating Google Sheets", "error", err)
 		return err
 	}
@@ -65,11 +56,19 @@ func entityToSheetsProject(project *project.Project) []interface{} {
 		clientName = project.Client.Name
 	}
 
+	health, healthNotes := "", ""
+	if project.Health != nil {
+		health = fmt.Sprintf("%s (%d)", project.Health.Level, project.Health.Score)
+		healthNotes = project.Health.Explanation()
+	}
+
 	return []interface{}{
 		fmt.Sprintf(`=HYPERLINK("%s"; "%s")`, fmt.Sprintf("https://notion.so/%s", strings.ReplaceAll(project.ID.String(), "-", "")), project.Name),
 		project.Type,
 		project.ManagerID,
 		project.Status,
 		clientName,
+		health,
+		healthNotes,
 	}
 }
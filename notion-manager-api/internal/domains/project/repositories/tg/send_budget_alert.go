@@ -0,0 +1,43 @@
+package tg
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func (r *ProjectTelegramRepository) SendBudgetAlert(ctx context.Context, chatID int64, status *project.BudgetStatus, threshold int) error {
+	var sb strings.Builder
+	if threshold >= 100 {
+		fmt.Fprintf(&sb, "<b>Бюджет проекта %s исчерпан</b>\n", html.EscapeString(status.ProjectName))
+	} else {
+		fmt.Fprintf(&sb, "<b>Проект %s израсходовал %d%% бюджета</b>\n", html.EscapeString(status.ProjectName), threshold)
+	}
+	fmt.Fprintf(&sb, "Списано %.1f из %.1f ч. (%.0f%%)\n", status.SpentHours, status.Hours, status.Consumption)
+	if status.Money > 0 {
+		fmt.Fprintf(&sb, "Деньги: %.0f из %.0f\n", status.SpentMoney, status.Money)
+	}
+	if status.BurnRate > 0 {
+		fmt.Fprintf(&sb, "Скорость: %.1f ч. в неделю\n", status.BurnRate)
+	}
+	if status.ProjectedOverrun != nil && threshold < 100 {
+		fmt.Fprintf(&sb, "Бюджет закончится примерно %s\n", status.ProjectedOverrun.Format("02.01.2006"))
+	}
+	if status.EstimateOverrun {
+		fmt.Fprintf(&sb, "Оценки задач (%.1f ч.) уже превышают бюджет\n", status.EstimatedHours)
+	}
+
+	if _, err := r.GetBot().SendMessage(chatID, sb.String(), &gotgbot.SendMessageOpts{
+		ParseMode: gotgbot.ParseModeHTML,
+	}); err != nil {
+		slog.Error("Error sending project budget alert", "error", err, "chat_id", chatID)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package tg
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+var healthLevelIcons = map[project.HealthLevel]string{
+	project.HealthGreen:  "🟢",
+	project.HealthYellow: "🟡",
+	project.HealthRed:    "🔴",
+}
+
+func (r *ProjectTelegramRepository) SendHealthSummary(ctx context.Context, chatID int64, healths []project.Health) error {
+	var sb strings.Builder
+	sb.WriteString("<b>Здоровье проектов за неделю</b>\n")
+	for _, h := range healths {
+		fmt.Fprintf(&sb, "\n%s <b>%s</b> — %d", healthLevelIcons[h.Level], html.EscapeString(h.ProjectName), h.Score)
+		if explanation := h.Explanation(); explanation != "" {
+			fmt.Fprintf(&sb, "\n%s", html.EscapeString(explanation))
+		}
+		sb.WriteString("\n")
+	}
+
+	if _, err := r.GetBot().SendMessage(chatID, sb.String(), &gotgbot.SendMessageOpts{
+		ParseMode: gotgbot.ParseModeHTML,
+	}); err != nil {
+		slog.Error("Error sending project health summary", "error", err, "chat_id", chatID)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package tg
+
+import (
+	"github.com/Corray333/employee_dashboard/internal/telegram"
+)
+
+type ProjectTelegramRepository struct {
+	*telegram.TelegramClient
+}
+
+func NewProjectTelegramRepository(client *telegram.TelegramClient) *ProjectTelegramRepository {
+	return &ProjectTelegramRepository{client}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type budgetSetter interface {
+	SetBudget(ctx context.Context, budget *project.Budget) error
+}
+
+type budgetDeleter interface {
+	DeleteBudget(ctx context.Context, projectID uuid.UUID) error
+}
+
+type budgetStatusesLister interface {
+	ListBudgetStatuses(ctx context.Context, recentFrom time.Time) ([]project.BudgetStatus, error)
+}
+
+type sentBudgetAlertsLister interface {
+	ListSentBudgetAlerts(ctx context.Context) (map[uuid.UUID]map[int]bool, error)
+}
+
+type budgetAlertsSentMarker interface {
+	MarkBudgetAlertsSent(ctx context.Context, projectID uuid.UUID, thresholds []int, sentAt time.Time) error
+}
+
+type budgetAlertSender interface {
+	SendBudgetAlert(ctx context.Context, chatID int64, status *project.BudgetStatus, threshold int) error
+}
+
+func (s *ProjectService) SetBudget(ctx context.Context, budget *project.Budget) error {
+	if err := budget.Validate(); err != nil {
+		return err
+	}
+	return s.budgetSetter.SetBudget(ctx, budget)
+}
+
+func (s *ProjectService) DeleteBudget(ctx context.Context, projectID uuid.UUID) error {
+	return s.budgetDeleter.DeleteBudget(ctx, projectID)
+}
+
+func budgetBurnRateWindow() time.Duration {
+	window := viper.GetDuration("project_budgets.burn_rate_window")
+	if window <= 0 {
+		window = 28 * 24 * time.Hour
+	}
+	return window
+}
+
+// ListBudgetStatuses возвращает расход бюджетов проектов, скорость расхода и прогноз перерасхода
+func (s *ProjectService) ListBudgetStatuses(ctx context.Context) ([]project.BudgetStatus, error) {
+	now := time.Now()
+	window := budgetBurnRateWindow()
+
+	statuses, err := s.budgetStatusesLister.ListBudgetStatuses(ctx, now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range statuses {
+		statuses[i].Calculate(window, now)
+	}
+
+	return statuses, nil
+}
+
+func (s *ProjectService) StartBudgetAlertsWorker(ctx context.Context) {
+	interval := viper.GetDuration("project_budgets.check_interval")
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.sendBudgetAlerts(ctx); err != nil {
+			slog.Error("Error sending project budget alerts", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sendBudgetAlerts оповещает менеджера проекта о новых достигнутых порогах.
+// Если за раз достигнуто несколько порогов, отправляется только самый большой.
+func (s *ProjectService) sendBudgetAlerts(ctx context.Context) error {
+	thresholds := viper.GetIntSlice("project_budgets.thresholds")
+	if len(thresholds) == 0 {
+		thresholds = []int{50, 80, 100}
+	}
+
+	statuses, err := s.ListBudgetStatuses(ctx)
+	if err != nil {
+		return err
+	}
+
+	sent, err := s.sentBudgetAlertsLister.ListSentBudgetAlerts(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range statuses {
+		status := &statuses[i]
+
+		reached := []int{}
+		for _, threshold := range status.ReachedThresholds(thresholds) {
+			if !sent[status.ProjectID][threshold] {
+				reached = append(reached, threshold)
+			}
+		}
+		if len(reached) == 0 {
+			continue
+		}
+
+		if status.ManagerTgID == 0 {
+			slog.Warn("Project manager has no telegram, skipping budget alert", "project_id", status.ProjectID)
+			continue
+		}
+
+		if err := s.budgetAlertSender.SendBudgetAlert(ctx, status.ManagerTgID, status, reached[len(reached)-1]); err != nil {
+			slog.Error("Error sending project budget alert", "error", err, "project_id", status.ProjectID)
+			continue
+		}
+
+		if err := s.budgetAlertsSentMarker.MarkBudgetAlertsSent(ctx, status.ProjectID, reached, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
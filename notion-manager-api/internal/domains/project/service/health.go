@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type healthMetricsLister interface {
+	ListHealthMetrics(ctx context.Context, filter project.HealthFilter) ([]project.HealthMetrics, error)
+}
+
+type healthSummarySentAtGetter interface {
+	GetHealthSummarySentAt(ctx context.Context, weekStart time.Time) (*time.Time, error)
+}
+
+type healthSummarySentMarker interface {
+	MarkHealthSummarySent(ctx context.Context, weekStart, sentAt time.Time) error
+}
+
+type healthSummarySender interface {
+	SendHealthSummary(ctx context.Context, chatID int64, healths []project.Health) error
+}
+
+func healthRules() project.HealthRules {
+	rules := project.DefaultHealthRules()
+	if err := viper.UnmarshalKey("project_health.rules", &rules); err != nil {
+		slog.Error("Error reading project health rules, using defaults", "error", err)
+		return project.DefaultHealthRules()
+	}
+	return rules
+}
+
+func healthFilter(now time.Time) project.HealthFilter {
+	staleAfter := viper.GetDuration("project_health.stale_after")
+	if staleAfter <= 0 {
+		staleAfter = 7 * 24 * time.Hour
+	}
+	return project.HealthFilter{
+		ProjectStatuses:        viper.GetStringSlice("project_health.project_statuses"),
+		StaleBefore:            now.Add(-staleAfter),
+		FeedbackPriorities:     viper.GetStringSlice("project_health.feedback_priorities"),
+		FeedbackClosedStatuses: viper.GetStringSlice("project_health.feedback_closed_statuses"),
+	}
+}
+
+// ListProjectsHealth оценивает здоровье проектов по просрочкам, бюджету, замечаниям,
+// зависшим и неназначенным задачам
+func (s *ProjectService) ListProjectsHealth(ctx context.Context) ([]project.Health, error) {
+	metrics, err := s.healthMetricsLister.ListHealthMetrics(ctx, healthFilter(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.ListBudgetStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	consumptions := make(map[uuid.UUID]float64, len(budgets))
+	for _, b := range budgets {
+		consumptions[b.ProjectID] = b.Consumption
+	}
+
+	rules := healthRules()
+	healths := make([]project.Health, 0, len(metrics))
+	for _, m := range metrics {
+		if consumption, ok := consumptions[m.ProjectID]; ok {
+			m.BudgetConsumption = &consumption
+		}
+		healths = append(healths, project.CalculateHealth(m, rules))
+	}
+
+	return healths, nil
+}
+
+func (s *ProjectService) StartHealthSummaryWorker(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if err := s.sendHealthSummary(ctx, time.Now()); err != nil {
+			slog.Error("Error sending project health summary", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// weekStart возвращает полночь понедельника недели, в которую попадает t
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// sendHealthSummary раз в неделю, в настроенные день и час, отправляет каждому менеджеру сводку по его проектам
+func (s *ProjectService) sendHealthSummary(ctx context.Context, now time.Time) error {
+	day := strings.ToLower(viper.GetString("project_health.summary.weekday"))
+	if day == "" {
+		day = "monday"
+	}
+	if strings.ToLower(now.Weekday().String()) != day || now.Hour() < viper.GetInt("project_health.summary.hour") {
+		return nil
+	}
+
+	week := weekStart(now)
+	sentAt, err := s.healthSummarySentAtGetter.GetHealthSummarySentAt(ctx, week)
+	if err != nil {
+		return err
+	}
+	if sentAt != nil {
+		return nil
+	}
+
+	healths, err := s.ListProjectsHealth(ctx)
+	if err != nil {
+		return err
+	}
+
+	byManager := map[int64][]project.Health{}
+	for _, h := range healths {
+		if h.ManagerTgID == 0 {
+			continue
+		}
+		byManager[h.ManagerTgID] = append(byManager[h.ManagerTgID], h)
+	}
+
+	var sendErr error
+	delivered := 0
+	for chatID, managerHealths := range byManager {
+		if err := s.healthSummarySender.SendHealthSummary(ctx, chatID, managerHealths); err != nil {
+			slog.Error("Error sending project health summary to manager", "error", err, "chat_id", chatID)
+			sendErr = err
+			continue
+		}
+		delivered++
+	}
+	// Если сводка не дошла ни до одного менеджера, неделя не отмечается и отправка повторится
+	if len(byManager) > 0 && delivered == 0 {
+		return sendErr
+	}
+
+	return s.healthSummarySentMarker.MarkHealthSummarySent(ctx, week, now)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type provisionTargetGetter interface {
+	GetProvisionTarget(ctx context.Context, projectID uuid.UUID) (*project.ProvisionTarget, error)
+}
+
+type provisionTargetsLister interface {
+	ListProvisionTargets(ctx context.Context, statuses []string) ([]project.ProvisionTarget, error)
+}
+
+type projectSheetsLinkSetter interface {
+	SetProjectSheetsLink(ctx context.Context, projectID uuid.UUID, link string) error
+}
+
+type spreadsheetCopier interface {
+	CopySpreadsheet(ctx context.Context, templateID, title string) (string, string, error)
+}
+
+type spreadsheetSharer interface {
+	ShareSpreadsheet(ctx context.Context, spreadsheetID, email string) error
+}
+
+type spreadsheetDeleter interface {
+	DeleteSpreadsheet(ctx context.Context, spreadsheetID string) error
+}
+
+// ProvisionProjectSheets создает проекту таблицу из шаблона, выдает доступ менеджеру,
+// записывает ссылку в Notion и выгружает в таблицу задачи и списания
+func (s *ProjectService) ProvisionProjectSheets(ctx context.Context, projectID uuid.UUID) (*project.ProvisionResult, error) {
+	target, err := s.provisionTargetGetter.GetProvisionTarget(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if target.SheetsLink != "" {
+		return nil, project.ErrSheetsAlreadyLinked
+	}
+
+	result := s.provision(ctx, target)
+	if result.Error != "" {
+		return result, errors.New(result.Error)
+	}
+	return result, nil
+}
+
+// ProvisionMissingProjectSheets создает таблицы всем проектам в рабочих статусах, у которых их нет.
+// Ошибка по одному проекту не останавливает остальные.
+func (s *ProjectService) ProvisionMissingProjectSheets(ctx context.Context) ([]project.ProvisionResult, error) {
+	targets, err := s.provisionTargetsLister.ListProvisionTargets(ctx, viper.GetStringSlice("sheets.provisioning.project_statuses"))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]project.ProvisionResult, 0, len(targets))
+	for i := range targets {
+		results = append(results, *s.provision(ctx, &targets[i]))
+	}
+
+	return results, nil
+}
+
+func (s *ProjectService) provision(ctx context.Context, target *project.ProvisionTarget) *project.ProvisionResult {
+	result := &project.ProvisionResult{
+		ProjectID:   target.ID,
+		ProjectName: target.Name,
+	}
+	fail := func(step string, err error) *project.ProvisionResult {
+		slog.Error("Error provisioning project sheets", "step", step, "error", err, "project_id", target.ID)
+		result.Error = step + ": " + err.Error()
+		return result
+	}
+
+	templateID := viper.GetString("sheets.provisioning.template_id")
+	if templateID == "" {
+		return fail("copy", errors.New("sheets.provisioning.template_id is not configured"))
+	}
+	title := strings.ReplaceAll(viper.GetString("sheets.provisioning.title"), "{project}", target.Name)
+	if title == "" {
+		title = target.Name
+	}
+
+	spreadsheetID, link, err := s.spreadsheetCopier.CopySpreadsheet(ctx, templateID, title)
+	if err != nil {
+		return fail("copy", err)
+	}
+	result.SheetsLink = link
+
+	// Ссылка сохраняется сразу после копирования, чтобы повторный запуск не создал вторую копию.
+	// Если дальше что-то упадет до записи в Notion, копия удаляется, иначе ее затрет синхронизация проектов
+	if err := s.projectSheetsLinkSetter.SetProjectSheetsLink(ctx, target.ID, link); err != nil {
+		s.discardSpreadsheet(ctx, target, spreadsheetID, result)
+		return fail("save", err)
+	}
+
+	if target.ManagerEmail != "" {
+		if err := s.spreadsheetSharer.ShareSpreadsheet(ctx, spreadsheetID, target.ManagerEmail); err != nil {
+			s.discardSpreadsheet(ctx, target, spreadsheetID, result)
+			return fail("share", err)
+		}
+		result.SharedWith = target.ManagerEmail
+	}
+
+	if err := s.notionSheetsLinkSetter.SetProjectSheetsLink(ctx, target.ID, link); err != nil {
+		s.discardSpreadsheet(ctx, target, spreadsheetID, result)
+		return fail("notion", err)
+	}
+
+	if err := s.UpdateProjectSheets(ctx, target.ID); err != nil {
+		return fail("export", err)
+	}
+
+	return result
+}
+
+// discardSpreadsheet удаляет копию шаблона и ссылку на нее после ошибки создания таблицы.
+// Если удалить копию не вышло, ссылка остается, чтобы копия не потерялась
+func (s *ProjectService) discardSpreadsheet(ctx context.Context, target *project.ProvisionTarget, spreadsheetID string, result *project.ProvisionResult) {
+	if err := s.spreadsheetDeleter.DeleteSpreadsheet(ctx, spreadsheetID); err != nil {
+		slog.Error("Error deleting provisioned spreadsheet", "error", err, "project_id", target.ID, "spreadsheet_id", spreadsheetID)
+		return
+	}
+	result.SheetsLink = ""
+	result.SharedWith = ""
+
+	if err := s.projectSheetsLinkSetter.SetProjectSheetsLink(ctx, target.ID, ""); err != nil {
+		slog.Error("Error clearing project sheets link", "error", err, "project_id", target.ID)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/Corray333/employee_dashboard/internal/domains/client/entities/client"
 	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
@@ -18,6 +19,26 @@ type ProjectService struct {
 	sheetsRepository        sheetsRepository
 
 	projectSheetsUpdaters []ProjectSheetsUpdater
+
+	budgetSetter           budgetSetter
+	budgetDeleter          budgetDeleter
+	budgetStatusesLister   budgetStatusesLister
+	sentBudgetAlertsLister sentBudgetAlertsLister
+	budgetAlertsSentMarker budgetAlertsSentMarker
+	budgetAlertSender      budgetAlertSender
+
+	healthMetricsLister       healthMetricsLister
+	healthSummarySentAtGetter healthSummarySentAtGetter
+	healthSummarySentMarker   healthSummarySentMarker
+	healthSummarySender       healthSummarySender
+
+	provisionTargetGetter   provisionTargetGetter
+	provisionTargetsLister  provisionTargetsLister
+	projectSheetsLinkSetter projectSheetsLinkSetter
+	notionSheetsLinkSetter  projectSheetsLinkSetter
+	spreadsheetCopier       spreadsheetCopier
+	spreadsheetSharer       spreadsheetSharer
+	spreadsheetDeleter      spreadsheetDeleter
 }
 
 type ProjectSheetsUpdater interface {
@@ -28,6 +49,22 @@ type postgresRepository interface {
 	projectsLister
 	projectWithSheetsLister
 	projectsByIDsGetter
+	budgetSetter
+	budgetDeleter
+	budgetStatusesLister
+	sentBudgetAlertsLister
+	budgetAlertsSentMarker
+	healthMetricsLister
+	healthSummarySentAtGetter
+	healthSummarySentMarker
+	provisionTargetGetter
+	provisionTargetsLister
+	projectSheetsLinkSetter
+}
+
+type telegramRepository interface {
+	budgetAlertSender
+	healthSummarySender
 }
 
 type clientsByIDsGetter interface {
@@ -36,9 +73,13 @@ type clientsByIDsGetter interface {
 
 type sheetsRepository interface {
 	UpdateSheetsProjects(ctx context.Context, sheetID string, projects []project.Project) error
+	spreadsheetCopier
+	spreadsheetSharer
+	spreadsheetDeleter
 }
 type notionRepository interface {
 	// feedbacksRawLister
+	projectSheetsLinkSetter
 }
 
 type option func(*ProjectService)
@@ -62,6 +103,24 @@ func WithPostgresRepository(repository postgresRepository) option {
 		s.projectsLister = repository
 		s.projectWithSheetsLister = repository
 		s.projectsByIDsGetter = repository
+		s.budgetSetter = repository
+		s.budgetDeleter = repository
+		s.budgetStatusesLister = repository
+		s.sentBudgetAlertsLister = repository
+		s.budgetAlertsSentMarker = repository
+		s.healthMetricsLister = repository
+		s.healthSummarySentAtGetter = repository
+		s.healthSummarySentMarker = repository
+		s.provisionTargetGetter = repository
+		s.provisionTargetsLister = repository
+		s.projectSheetsLinkSetter = repository
+	}
+}
+
+func WithTelegramRepository(repository telegramRepository) option {
+	return func(s *ProjectService) {
+		s.budgetAlertSender = repository
+		s.healthSummarySender = repository
 	}
 }
 
@@ -74,6 +133,9 @@ func WithClientService(clientService clientsByIDsGetter) option {
 func WithSheetsRepository(repo sheetsRepository) option {
 	return func(s *ProjectService) {
 		s.sheetsRepository = repo
+		s.spreadsheetCopier = repo
+		s.spreadsheetSharer = repo
+		s.spreadsheetDeleter = repo
 	}
 }
 
@@ -84,15 +146,14 @@ func WithProjectsLister(lister projectsLister) option {
 }
 
 func WithNotionRepository(repository notionRepository) option {
-	return func(s *ProjectService) {}
+	return func(s *ProjectService) {
+		s.notionSheetsLinkSetter = repository
+	}
 }
 
 func (s *ProjectService) Run() {
-
-}
-
-func (s *ProjectService) AcceptUpdate(ctx context.Context) {
-	go s.UpdateSheets(ctx)
+	go s.StartBudgetAlertsWorker(context.Background())
+	go s.StartHealthSummaryWorker(context.Background())
 }
 
 func (s *ProjectService) UpdateProjectSheets(ctx context.Context, projectID uuid.UUID) error {
@@ -125,5 +186,36 @@ func (s *ProjectService) UpdateSheets(ctx context.Context) error {
 		}
 	}
 
-	return s.sheetsRepository.UpdateSheetsProjects(ctx, "", projects)
+	healths, err := s.ListProjectsHealth(ctx)
+	if err != nil {
+		slog.Error("Error calculating projects health for sheets", "error", err)
+	}
+	healthByProject := make(map[uuid.UUID]*project.Health, len(healths))
+	for i := range healths {
+		healthByProject[healths[i].ProjectID] = &healths[i]
+	}
+	for i := range projects {
+		projects[i].Health = healthByProject[projects[i].ID]
+	}
+
+	if err := s.sheetsRepository.UpdateSheetsProjects(ctx, "", projects); err != nil {
+		return err
+	}
+
+	// Вместе с общим листом проектов обновляются и таблицы самих проектов
+	results, err := s.UpdateAllProjectSheets(ctx)
+	if err != nil {
+		return err
+	}
+	failed := []string{}
+	for _, r := range results {
+		if len(r.Errors) > 0 {
+			failed = append(failed, r.ProjectName)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to update sheets of projects: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
 }
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+	"github.com/spf13/viper"
+)
+
+func sheetsBackoff() gsheets.Backoff {
+	b := gsheets.Backoff{
+		Attempts: viper.GetInt("sheets.fan_out.attempts"),
+		Initial:  viper.GetDuration("sheets.fan_out.initial_backoff"),
+		Max:      viper.GetDuration("sheets.fan_out.max_backoff"),
+	}
+	if b.Attempts <= 0 {
+		b.Attempts = 5
+	}
+	if b.Initial <= 0 {
+		b.Initial = 2 * time.Second
+	}
+	if b.Max < b.Initial {
+		b.Max = time.Minute
+	}
+	return b
+}
+
+// UpdateAllProjectSheets обновляет таблицы всех проектов со ссылкой на таблицу ограниченным
+// числом воркеров. Ошибка одного проекта не мешает остальным и попадает в его результат.
+func (s *ProjectService) UpdateAllProjectSheets(ctx context.Context) ([]project.SheetsUpdateResult, error) {
+	projects, err := s.projectWithSheetsLister.ListProjectsWithLinkedSheets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := viper.GetInt("sheets.fan_out.workers")
+	if workers <= 0 {
+		workers = 4
+	}
+	backoff := sheetsBackoff()
+
+	results := make([]project.SheetsUpdateResult, len(projects))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(workers, len(projects)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = s.updateProjectSheetsWithBackoff(ctx, &projects[i], backoff)
+			}
+		}()
+	}
+
+	for i := range projects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if len(r.Errors) > 0 {
+			failed++
+		}
+	}
+	slog.Info("Projects sheets updated", "total", len(results), "failed", failed)
+
+	return results, nil
+}
+
+func (s *ProjectService) updateProjectSheetsWithBackoff(ctx context.Context, p *project.Project, backoff gsheets.Backoff) (result project.SheetsUpdateResult) {
+	result = project.SheetsUpdateResult{
+		ProjectID:   p.ID,
+		ProjectName: p.Name,
+	}
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("panic: %v", r))
+		}
+		result.DurationMS = time.Since(start).Milliseconds()
+	}()
+
+	for _, updater := range s.projectSheetsUpdaters {
+		if err := ctx.Err(); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			return result
+		}
+
+		if err := backoff.Do(ctx, func() error {
+			return updater.UpdateProjectSheets(ctx, p.ID)
+		}); err != nil {
+			slog.Error("Error updating project sheets", "error", err, "project_id", p.ID)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+
+	return result
+}
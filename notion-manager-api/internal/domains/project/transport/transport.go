@@ -3,9 +3,12 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
+	"github.com/Corray333/employee_dashboard/internal/transport"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -14,6 +17,15 @@ type service interface {
 	UpdateProjectSheets(ctx context.Context, projectID uuid.UUID) error
 
 	ListProjectsWithLinkedSheets(ctx context.Context) ([]project.Project, error)
+
+	SetBudget(ctx context.Context, budget *project.Budget) error
+	DeleteBudget(ctx context.Context, projectID uuid.UUID) error
+	ListBudgetStatuses(ctx context.Context) ([]project.BudgetStatus, error)
+	ListProjectsHealth(ctx context.Context) ([]project.Health, error)
+
+	ProvisionProjectSheets(ctx context.Context, projectID uuid.UUID) (*project.ProvisionResult, error)
+	ProvisionMissingProjectSheets(ctx context.Context) ([]project.ProvisionResult, error)
+	UpdateAllProjectSheets(ctx context.Context) ([]project.SheetsUpdateResult, error)
 }
 type ProjectTransport struct {
 	service service
@@ -33,9 +45,22 @@ func (t *ProjectTransport) RegisterRoutes() {
 	t.router.Group(func(r chi.Router) {
 		// r.Use(transport.NewTaskTrackerAuthMiddleware())
 
+		r.Post("/api/projects/update-sheets", t.updateAllSheets)
 		r.Post("/api/projects/{projectID}/update-sheets", t.updateSheets)
 		r.Get("/api/projects/with-sheets", t.listProjectsWithLinkedSheets)
 	})
+
+	t.router.Group(func(r chi.Router) {
+		r.Use(transport.NewTaskTrackerAuthMiddleware())
+
+		r.Get("/api/projects/budgets", t.listBudgetStatuses)
+		r.Get("/api/projects/health", t.listProjectsHealth)
+		r.Put("/api/projects/{projectID}/budget", t.setBudget)
+		r.Delete("/api/projects/{projectID}/budget", t.deleteBudget)
+
+		r.Post("/api/projects/provision-sheets", t.provisionMissingProjectSheets)
+		r.Post("/api/projects/{projectID}/provision-sheets", t.provisionProjectSheets)
+	})
 }
 
 func (t *ProjectTransport) updateSheets(w http.ResponseWriter, r *http.Request) {
@@ -53,6 +78,19 @@ func (t *ProjectTransport) updateSheets(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+func (t *ProjectTransport) updateAllSheets(w http.ResponseWriter, r *http.Request) {
+	results, err := t.service.UpdateAllProjectSheets(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		slog.Error("Error encoding sheets update results", "error", err)
+	}
+}
+
 func (t *ProjectTransport) listProjectsWithLinkedSheets(w http.ResponseWriter, r *http.Request) {
 	projects, err := t.service.ListProjectsWithLinkedSheets(r.Context())
 	if err != nil {
@@ -67,3 +105,124 @@ func (t *ProjectTransport) listProjectsWithLinkedSheets(w http.ResponseWriter, r
 	w.WriteHeader(http.StatusOK)
 
 }
+
+func (t *ProjectTransport) listBudgetStatuses(w http.ResponseWriter, r *http.Request) {
+	statuses, err := t.service.ListBudgetStatuses(r.Context())
+	if err != nil {
+		slog.Error("Error listing project budgets", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		slog.Error("Error encoding project budgets", "error", err)
+	}
+}
+
+func (t *ProjectTransport) setBudget(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	budget := &project.Budget{}
+	if err := json.NewDecoder(r.Body).Decode(budget); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	budget.ProjectID = projectID
+
+	if err := t.service.SetBudget(r.Context(), budget); err != nil {
+		if errors.Is(err, project.ErrInvalidBudget) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error setting project budget", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(budget); err != nil {
+		slog.Error("Error encoding project budget", "error", err)
+	}
+}
+
+func (t *ProjectTransport) deleteBudget(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.DeleteBudget(r.Context(), projectID); err != nil {
+		slog.Error("Error deleting project budget", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *ProjectTransport) listProjectsHealth(w http.ResponseWriter, r *http.Request) {
+	healths, err := t.service.ListProjectsHealth(r.Context())
+	if err != nil {
+		slog.Error("Error listing projects health", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(healths); err != nil {
+		slog.Error("Error encoding projects health", "error", err)
+	}
+}
+
+func (t *ProjectTransport) provisionProjectSheets(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := t.service.ProvisionProjectSheets(r.Context(), projectID)
+	if err != nil {
+		switch {
+		case errors.Is(err, project.ErrProjectNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, project.ErrSheetsAlreadyLinked):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case result != nil:
+			// Таблица могла успеть создаться, поэтому возвращаем результат вместе с ошибкой
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadGateway)
+			if err := json.NewEncoder(w).Encode(result); err != nil {
+				slog.Error("Error encoding provision result", "error", err)
+			}
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		slog.Error("Error encoding provision result", "error", err)
+	}
+}
+
+func (t *ProjectTransport) provisionMissingProjectSheets(w http.ResponseWriter, r *http.Request) {
+	results, err := t.service.ProvisionMissingProjectSheets(r.Context())
+	if err != nil {
+		slog.Error("Error provisioning projects sheets", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		slog.Error("Error encoding provision results", "error", err)
+	}
+}
@@ -0,0 +1,34 @@
+package task
+
+import (
+	"fmt"
+	"time"
+)
+
+type ReminderKind string
+
+const ReminderOverdue ReminderKind = "overdue"
+
+// ReminderBefore - напоминание за days дней до дедлайна
+func ReminderBefore(days int) ReminderKind {
+	return ReminderKind(fmt.Sprintf("before_%d", days))
+}
+
+// DeadlineTask - задача с дедлайном и получателями напоминаний о нем
+type DeadlineTask struct {
+	Task
+	Executor       string `json:"executor"`
+	ExecutorTgID   int64  `json:"executor_tg_id"`
+	ExecutorAbsent bool   `json:"executor_absent"`
+	ManagerTgID    int64  `json:"manager_tg_id"`
+	ManagerAbsent  bool   `json:"manager_absent"`
+}
+
+// DaysLeft возвращает количество календарных дней от day до дедлайна в часовом поясе loc
+func (t *DeadlineTask) DaysLeft(day time.Time, loc *time.Location) int {
+	end := t.End.In(loc)
+	endDate := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
+	d := day.In(loc)
+	dayDate := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
+	return int(endDate.Sub(dayDate).Hours() / 24)
+}
@@ -0,0 +1,51 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDaysLeft(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+
+	day := time.Date(2025, time.March, 10, 23, 30, 0, 0, loc)
+
+	tests := []struct {
+		name    string
+		end     time.Time
+		expects int
+	}{
+		{
+			name:    "date-only deadline tomorrow",
+			end:     time.Date(2025, time.March, 11, 0, 0, 0, 0, time.UTC),
+			expects: 1,
+		},
+		{
+			name:    "deadline in three days",
+			end:     time.Date(2025, time.March, 13, 0, 0, 0, 0, time.UTC),
+			expects: 3,
+		},
+		{
+			name:    "deadline today",
+			end:     time.Date(2025, time.March, 10, 18, 0, 0, 0, loc),
+			expects: 0,
+		},
+		{
+			name:    "overdue",
+			end:     time.Date(2025, time.March, 8, 0, 0, 0, 0, time.UTC),
+			expects: -2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := DeadlineTask{Task: Task{End: tt.end}}
+			if got := task.DaysLeft(day, loc); got != tt.expects {
+				t.Errorf("DaysLeft() = %d, expected %d", got, tt.expects)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EvalEstimate вычисляет формулу оценки задачи шаблона, например "8", "hours * 0.2"
+// или "max(4, hours * 0.15)". Поддерживаются + - * /, скобки, переменные из vars и
+// функции min/max. Пустая формула - нулевая оценка.
+func EvalEstimate(formula string, vars map[string]float64) (float64, error) {
+	if strings.TrimSpace(formula) == "" {
+		return 0, nil
+	}
+
+	p := &formulaParser{input: formula, vars: vars}
+	value, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected %q at %d in estimate formula", p.input[p.pos:], p.pos)
+	}
+	return value, nil
+}
+
+// estimateSampleHours - значение hours, на котором проверяется формула при сохранении шаблона.
+// С нулем формулы вида "40 / hours" ошибочно считались бы некорректными
+const estimateSampleHours = 100
+
+// ValidateEstimate проверяет синтаксис формулы оценки, не зная часов конкретного проекта
+func ValidateEstimate(formula string) error {
+	_, err := EvalEstimate(formula, map[string]float64{"hours": estimateSampleHours})
+	return err
+}
+
+type formulaParser struct {
+	input string
+	pos   int
+	vars  map[string]float64
+}
+
+func (p *formulaParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *formulaParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *formulaParser) expr() (float64, error) {
+	left, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '+', '-':
+			op := p.input[p.pos]
+			p.pos++
+			right, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			if op == '+' {
+				left += right
+			} else {
+				left -= right
+			}
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *formulaParser) term() (float64, error) {
+	left, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '*', '/':
+			op := p.input[p.pos]
+			p.pos++
+			right, err := p.factor()
+			if err != nil {
+				return 0, err
+			}
+			if op == '*' {
+				left *= right
+			} else {
+				if right == 0 {
+					return 0, fmt.Errorf("division by zero in estimate formula")
+				}
+				left /= right
+			}
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *formulaParser) factor() (float64, error) {
+	c := p.peek()
+	switch {
+	case c == '-':
+		p.pos++
+		value, err := p.factor()
+		return -value, err
+	case c == '(':
+		p.pos++
+		value, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing ) in estimate formula")
+		}
+		p.pos++
+		return value, nil
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		return strconv.ParseFloat(p.input[start:p.pos], 64)
+	case unicode.IsLetter(rune(c)):
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || p.input[p.pos] == '_') {
+			p.pos++
+		}
+		name := strings.ToLower(p.input[start:p.pos])
+		if p.peek() == '(' {
+			return p.call(name)
+		}
+		value, ok := p.vars[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown variable %q in estimate formula", name)
+		}
+		return value, nil
+	default:
+		return 0, fmt.Errorf("unexpected end of estimate formula")
+	}
+}
+
+func (p *formulaParser) call(name string) (float64, error) {
+	if name != "min" && name != "max" {
+		return 0, fmt.Errorf("unknown function %q in estimate formula", name)
+	}
+	p.pos++ // (
+	args := []float64{}
+	for {
+		value, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, value)
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if p.peek() != ')' {
+		return 0, fmt.Errorf("missing ) in estimate formula")
+	}
+	p.pos++
+
+	result := args[0]
+	for _, arg := range args[1:] {
+		if name == "min" {
+			result = min(result, arg)
+		} else {
+			result = max(result, arg)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package task
+
+import "testing"
+
+func TestEvalEstimate(t *testing.T) {
+	vars := map[string]float64{"hours": 100}
+
+	tests := []struct {
+		formula string
+		expects float64
+		wantErr bool
+	}{
+		{formula: "", expects: 0},
+		{formula: "8", expects: 8},
+		{formula: "hours * 0.2", expects: 20},
+		{formula: "HOURS*0.15 + 1", expects: 16},
+		{formula: "(hours - 40) / 2", expects: 30},
+		{formula: "max(4, hours * 0.01)", expects: 4},
+		{formula: "min(hours, 10, 12)", expects: 10},
+		{formula: "-2 + 5", expects: 3},
+		{formula: "days * 2", wantErr: true},
+		{formula: "hours / 0", wantErr: true},
+		{formula: "avg(1, 2)", wantErr: true},
+		{formula: "(1 + 2", wantErr: true},
+		{formula: "1 2", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.formula, func(t *testing.T) {
+			got, err := EvalEstimate(tc.formula, vars)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.expects {
+				t.Errorf("expected %v, got %v", tc.expects, got)
+			}
+		})
+	}
+}
+
+func TestValidateEstimate(t *testing.T) {
+	if err := ValidateEstimate("40 / hours"); err != nil {
+		t.Errorf("formula dividing by hours must be valid, got %v", err)
+	}
+	if err := ValidateEstimate("days * 2"); err == nil {
+		t.Error("expected error for unknown variable")
+	}
+}
@@ -0,0 +1,152 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidEditValue = errors.New("invalid value")
+
+// EditableField - поле задачи, которое можно править прямо в листе задач
+type EditableField string
+
+const (
+	EditableFieldPriority EditableField = "priority"
+	EditableFieldEstimate EditableField = "estimate"
+)
+
+// Value возвращает значение поля задачи в том виде, в каком оно сравнивается с таблицей
+func (f EditableField) Value(t *Task) string {
+	switch f {
+	case EditableFieldPriority:
+		return t.Priority
+	case EditableFieldEstimate:
+		return strconv.FormatFloat(t.Estimate, 'f', -1, 64)
+	}
+	return ""
+}
+
+// Set записывает в задачу уже проверенное значение поля
+func (f EditableField) Set(t *Task, value string) {
+	switch f {
+	case EditableFieldPriority:
+		t.Priority = value
+	case EditableFieldEstimate:
+		t.Estimate, _ = strconv.ParseFloat(value, 64)
+	}
+}
+
+// Normalize проверяет значение ячейки и приводит его к виду Value.
+// priorities - допустимые приоритеты, пустой список разрешает любой.
+func (f EditableField) Normalize(raw interface{}, priorities []string) (string, error) {
+	switch f {
+	case EditableFieldPriority:
+		value := strings.TrimSpace(fmt.Sprint(raw))
+		if raw == nil {
+			value = ""
+		}
+		if value != "" && len(priorities) > 0 && !slices.Contains(priorities, value) {
+			return "", fmt.Errorf("%w: unknown priority %q", ErrInvalidEditValue, value)
+		}
+		return value, nil
+	case EditableFieldEstimate:
+		var estimate float64
+		switch v := raw.(type) {
+		case float64:
+			estimate = v
+		case nil:
+		case string:
+			s := strings.ReplaceAll(strings.TrimSpace(v), ",", ".")
+			if s == "" {
+				break
+			}
+			var err error
+			if estimate, err = strconv.ParseFloat(s, 64); err != nil {
+				return "", fmt.Errorf("%w: estimate %q is not a number", ErrInvalidEditValue, v)
+			}
+		default:
+			return "", fmt.Errorf("%w: estimate %v is not a number", ErrInvalidEditValue, raw)
+		}
+		if estimate < 0 {
+			return "", fmt.Errorf("%w: estimate %v is negative", ErrInvalidEditValue, estimate)
+		}
+		return strconv.FormatFloat(estimate, 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("%w: field %q is not editable", ErrInvalidEditValue, f)
+}
+
+type SheetEditStatus string
+
+const (
+	SheetEditStatusPending  SheetEditStatus = "pending"
+	SheetEditStatusConflict SheetEditStatus = "conflict"
+	SheetEditStatusInvalid  SheetEditStatus = "invalid"
+	// SheetEditStatusFailed - правку так и не удалось отправить в Notion за отведенные попытки
+	SheetEditStatusFailed SheetEditStatus = "failed"
+)
+
+// SheetEdit - правка ячейки листа задач. Ожидающие правки отправляются в Notion через outbox,
+// конфликтные и некорректные остаются для отчета в листе конфликтов.
+type SheetEdit struct {
+	ID            int64           `json:"id"`
+	SpreadsheetID string          `json:"spreadsheet_id"`
+	TaskID        uuid.UUID       `json:"task_id"`
+	TaskName      string          `json:"task_name"`
+	Field         EditableField   `json:"field"`
+	Value         string          `json:"value"`
+	BaseValue     string          `json:"base_value"`
+	NotionValue   string          `json:"notion_value"`
+	Status        SheetEditStatus `json:"status"`
+	Reason        string          `json:"reason"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+}
+
+// SheetEditRetryDelay возвращает паузу перед следующей отправкой правки после attempts неудачных:
+// пауза удваивается с каждой попыткой, начиная с initial, но не превышает max
+func SheetEditRetryDelay(attempts int, initial, max time.Duration) time.Duration {
+	delay := initial
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	return min(delay, max)
+}
+
+type SheetEditKind int
+
+const (
+	SheetEditNone SheetEditKind = iota
+	SheetEditChanged
+	SheetEditConflict
+)
+
+// ClassifySheetEdit сравнивает значение в таблице со значением, выгруженным в нее в прошлый раз,
+// и с текущим значением задачи. Правкой считается только изменение в таблице; если с выгрузки
+// поменялись и таблица, и задача в Notion, и значения разошлись, это конфликт.
+func ClassifySheetEdit(sheet, exported, current string) SheetEditKind {
+	if sheet == exported || sheet == current {
+		return SheetEditNone
+	}
+	if current != exported {
+		return SheetEditConflict
+	}
+	return SheetEditChanged
+}
+
+// SheetEditFilter - отбор правок: пустые поля не ограничивают выборку
+type SheetEditFilter struct {
+	SpreadsheetID string
+	Statuses      []SheetEditStatus
+	Since         time.Time
+	// Due - только правки, очередная попытка отправки которых наступила к этому моменту
+	Due   time.Time
+	Limit int
+}
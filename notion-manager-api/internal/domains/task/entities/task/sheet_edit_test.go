@@ -0,0 +1,84 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClassifySheetEdit(t *testing.T) {
+	tests := []struct {
+		name                     string
+		sheet, exported, current string
+		expected                 SheetEditKind
+	}{
+		{name: "nothing changed", sheet: "5", exported: "5", current: "5", expected: SheetEditNone},
+		{name: "changed in sheet", sheet: "8", exported: "5", current: "5", expected: SheetEditChanged},
+		{name: "changed in notion only", sheet: "5", exported: "5", current: "3", expected: SheetEditNone},
+		{name: "changed on both sides", sheet: "8", exported: "5", current: "3", expected: SheetEditConflict},
+		{name: "same change on both sides", sheet: "8", exported: "5", current: "8", expected: SheetEditNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifySheetEdit(tt.sheet, tt.exported, tt.current); got != tt.expected {
+				t.Errorf("ClassifySheetEdit(%q, %q, %q) = %v, expected %v", tt.sheet, tt.exported, tt.current, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEditableFieldNormalize(t *testing.T) {
+	priorities := []string{"Низкий", "Высокий"}
+
+	tests := []struct {
+		name     string
+		field    EditableField
+		raw      interface{}
+		expected string
+		invalid  bool
+	}{
+		{name: "estimate number", field: EditableFieldEstimate, raw: 2.5, expected: "2.5"},
+		{name: "estimate string with comma", field: EditableFieldEstimate, raw: " 2,5 ", expected: "2.5"},
+		{name: "estimate empty", field: EditableFieldEstimate, raw: "", expected: "0"},
+		{name: "estimate not a number", field: EditableFieldEstimate, raw: "много", invalid: true},
+		{name: "estimate negative", field: EditableFieldEstimate, raw: -1.0, invalid: true},
+		{name: "known priority", field: EditableFieldPriority, raw: "Высокий ", expected: "Высокий"},
+		{name: "cleared priority", field: EditableFieldPriority, raw: nil, expected: ""},
+		{name: "unknown priority", field: EditableFieldPriority, raw: "Срочно", invalid: true},
+		{name: "not editable", field: EditableField("status"), raw: "Готово", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.field.Normalize(tt.raw, priorities)
+			if tt.invalid {
+				if !errors.Is(err, ErrInvalidEditValue) {
+					t.Errorf("Normalize(%v) error = %v, expected ErrInvalidEditValue", tt.raw, err)
+				}
+				return
+			}
+			if err != nil || got != tt.expected {
+				t.Errorf("Normalize(%v) = %q, %v, expected %q", tt.raw, got, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSheetEditRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: time.Minute},
+		{attempts: 2, expected: 2 * time.Minute},
+		{attempts: 4, expected: 8 * time.Minute},
+		{attempts: 10, expected: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := SheetEditRetryDelay(tt.attempts, time.Minute, time.Hour); got != tt.expected {
+			t.Errorf("SheetEditRetryDelay(%d) = %v, expected %v", tt.attempts, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package task
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidSnoozeSignature = errors.New("invalid snooze signature")
+	ErrSnoozeLinkExpired      = errors.New("snooze link expired")
+	ErrSnoozeSecretNotSet     = errors.New("SNOOZE_SECRET is not set")
+)
+
+// StaleTask - задача, которая дольше порога находится в одном статусе
+type StaleTask struct {
+	Task
+	StatusSince time.Time `json:"status_since"`
+
+	ResponsibleTgID int64 `json:"responsible_tg_id"`
+	ManagerTgID     int64 `json:"manager_tg_id"`
+}
+
+// StaleThreshold - сколько задача может находиться в статусе, прежде чем о ней напомнят
+type StaleThreshold struct {
+	Status Status        `mapstructure:"status"`
+	After  time.Duration `mapstructure:"after"`
+}
+
+// SnoozeLink - кнопка в дайджесте, откладывающая напоминание о задаче
+type SnoozeLink struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
@@ -1,12 +1,17 @@
 package task
 
 import (
+	"errors"
 	"slices"
+	"strconv"
 	"time"
 
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
 	"github.com/google/uuid"
 )
 
+var ErrProjectNotFound = errors.New("project not found")
+
 type Tag string
 
 const (
@@ -35,6 +40,7 @@ type Task struct {
 	ID             uuid.UUID `json:"id"`
 	CreatedTime    time.Time `json:"created_time"`
 	LastEditedTime time.Time `json:"last_edited_time"`
+	LastEditedBy   string    `json:"last_edited_by"`
 
 	Task          string    `json:"task"`
 	Priority      string    `json:"priority"`
@@ -80,6 +86,23 @@ func (t *Task) GetDirection() string {
 	return ""
 }
 
+// AuditFields возвращает поля задачи, изменения которых пишутся в журнал
+func (t *Task) AuditFields() map[string]string {
+	return map[string]string{
+		"title":          t.Task,
+		"priority":       t.Priority,
+		"status":         string(t.Status),
+		"parent_id":      audit.FormatUUID(t.ParentID),
+		"responsible_id": audit.FormatUUID(t.ResponsibleID),
+		"executor_id":    audit.FormatUUID(t.ExecutorID),
+		"project_id":     audit.FormatUUID(t.ProjectID),
+		"estimate":       strconv.FormatFloat(t.Estimate, 'f', -1, 64),
+		"start":          audit.FormatTime(t.Start),
+		"end":            audit.FormatTime(t.End),
+		"sh":             strconv.FormatFloat(t.SH, 'f', -1, 64),
+	}
+}
+
 // task, estimate, start, end, executor
 type TaskOutboxMsg struct {
 	ID         int64     `json:"id"`
@@ -90,6 +113,16 @@ type TaskOutboxMsg struct {
 	Priority   string    `json:"priority"`
 	Start      time.Time `json:"start"`
 	End        time.Time `json:"end"`
+
+	// ParentMsgID - сообщение родительской задачи, еще не созданной в Notion
+	ParentMsgID int64        `json:"parentMsgID,omitempty"`
+	ParentID    uuid.UUID    `json:"parentID,omitempty"`
+	Tags        []Tag        `json:"tags,omitempty"`
+	Role        TemplateRole `json:"role,omitempty"`
+
+	// CreatedTaskID - задача, уже созданная в Notion по этому сообщению.
+	// Если обработка упала после создания, при повторе задача не создается заново
+	CreatedTaskID uuid.UUID `json:"createdTaskID,omitempty"`
 }
 
 func (t *TaskOutboxMsg) ToEntity() *Task {
@@ -101,5 +134,7 @@ func (t *TaskOutboxMsg) ToEntity() *Task {
 		End:        t.End,
 		ExecutorID: t.ExecutorID,
 		ProjectID:  t.ProjectID,
+		ParentID:   t.ParentID,
+		Tags:       t.Tags,
 	}
 }
@@ -0,0 +1,74 @@
+package task
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("task template not found")
+	ErrInvalidTemplate  = errors.New("invalid task template")
+)
+
+// TemplateRole отмечает служебные задачи проекта, на которые ссылается проект.
+// Совпадает с ролью правила оценки служебных задач (overhead_rules), поэтому набор ролей открытый.
+type TemplateRole string
+
+const (
+	TemplateRoleNone       TemplateRole = ""
+	TemplateRoleManagement TemplateRole = "management"
+	TemplateRoleTesting    TemplateRole = "testing"
+)
+
+// ProjectNamePlaceholder в названии задачи шаблона заменяется на название проекта
+const ProjectNamePlaceholder = "{project}"
+
+type Template struct {
+	ID          int64          `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Items       []TemplateItem `json:"items"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// TemplateItem - задача шаблона. Дедлайн задается относительно даты старта проекта,
+// оценка - формулой от часов проекта (см. EvalEstimate).
+type TemplateItem struct {
+	ID              int64          `json:"id"`
+	Title           string         `json:"title"`
+	Role            TemplateRole   `json:"role"`
+	StartOffsetDays int            `json:"start_offset_days"`
+	DurationDays    int            `json:"duration_days"`
+	Tags            []Tag          `json:"tags"`
+	Estimate        string         `json:"estimate"`
+	Priority        string         `json:"priority"`
+	Subtasks        []TemplateItem `json:"subtasks"`
+}
+
+func (i *TemplateItem) RenderTitle(projectName string) string {
+	return strings.ReplaceAll(i.Title, ProjectNamePlaceholder, projectName)
+}
+
+func (i *TemplateItem) Deadline(projectStart time.Time) (time.Time, time.Time) {
+	start := projectStart.AddDate(0, 0, i.StartOffsetDays)
+	return start, start.AddDate(0, 0, i.DurationDays)
+}
+
+// InstantiateTemplateRequest - параметры создания задач проекта по шаблону.
+// Исполнитель служебных задач берется из RoleExecutors, остальных - ExecutorID.
+type InstantiateTemplateRequest struct {
+	ProjectID     uuid.UUID                  `json:"project_id"`
+	ExecutorID    uuid.UUID                  `json:"executor_id"`
+	RoleExecutors map[TemplateRole]uuid.UUID `json:"role_executors"`
+	Start         time.Time                  `json:"start"`
+}
+
+func (r *InstantiateTemplateRequest) Executor(role TemplateRole) uuid.UUID {
+	if id, ok := r.RoleExecutors[role]; ok && id != uuid.Nil {
+		return id
+	}
+	return r.ExecutorID
+}
@@ -0,0 +1,134 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// WIPStat - количество задач "В работе" у исполнителя
+type WIPStat struct {
+	ExecutorID   uuid.UUID `json:"executor_id"`
+	Executor     string    `json:"executor"`
+	Direction    string    `json:"direction"`
+	TgID         int64     `json:"-"`
+	ManagerTgIDs []int64   `json:"-"`
+	InProgress   int       `json:"in_progress"`
+	Limit        int       `json:"limit"`
+	OverLimit    bool      `json:"over_limit"`
+}
+
+// DirectionWIPLimit переопределяет лимиты для направления.
+// PerExecutor - лимит на каждого исполнителя, Total - на все направление; 0 - без ограничения.
+type DirectionWIPLimit struct {
+	Direction   string `mapstructure:"direction"`
+	PerExecutor int    `mapstructure:"per_executor"`
+	Total       int    `mapstructure:"total"`
+}
+
+type WIPLimits struct {
+	PerExecutor int                 `mapstructure:"per_executor"`
+	Directions  []DirectionWIPLimit `mapstructure:"directions"`
+}
+
+func (l WIPLimits) direction(direction string) DirectionWIPLimit {
+	for _, d := range l.Directions {
+		if d.Direction == direction {
+			return d
+		}
+	}
+	return DirectionWIPLimit{Direction: direction}
+}
+
+// ExecutorLimit возвращает лимит задач в работе для исполнителя из направления
+func (l WIPLimits) ExecutorLimit(direction string) int {
+	if d := l.direction(direction); d.PerExecutor > 0 {
+		return d.PerExecutor
+	}
+	return l.PerExecutor
+}
+
+// WIPViolation - превышение лимита исполнителем или направлением
+type WIPViolation struct {
+	Scope        string  `json:"scope"`
+	Executor     string  `json:"executor,omitempty"`
+	Direction    string  `json:"direction"`
+	InProgress   int     `json:"in_progress"`
+	Limit        int     `json:"limit"`
+	TgIDs        []int64 `json:"-"`
+	ManagerTgIDs []int64 `json:"-"`
+}
+
+func (v WIPViolation) Msg() string {
+	if v.Executor != "" {
+		return fmt.Sprintf("У %s %d задач в статусе «%s» при лимите %d", v.Executor, v.InProgress, StatusInProgress, v.Limit)
+	}
+	return fmt.Sprintf("В направлении %s %d задач в статусе «%s» при лимите %d", v.Direction, v.InProgress, StatusInProgress, v.Limit)
+}
+
+// ApplyWIPLimits проставляет лимиты в статистику и возвращает нарушения
+func ApplyWIPLimits(stats []WIPStat, limits WIPLimits) []WIPViolation {
+	violations := []WIPViolation{}
+
+	directions := map[string][]int{}
+	for i := range stats {
+		stats[i].Limit = limits.ExecutorLimit(stats[i].Direction)
+		stats[i].OverLimit = stats[i].Limit > 0 && stats[i].InProgress > stats[i].Limit
+		directions[stats[i].Direction] = append(directions[stats[i].Direction], i)
+
+		if stats[i].OverLimit {
+			v := WIPViolation{
+				Scope:        "executor:" + stats[i].ExecutorID.String(),
+				Executor:     stats[i].Executor,
+				Direction:    stats[i].Direction,
+				InProgress:   stats[i].InProgress,
+				Limit:        stats[i].Limit,
+				ManagerTgIDs: stats[i].ManagerTgIDs,
+			}
+			if stats[i].TgID != 0 {
+				v.TgIDs = []int64{stats[i].TgID}
+			}
+			violations = append(violations, v)
+		}
+	}
+
+	names := make([]string, 0, len(directions))
+	for name := range directions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		total := limits.direction(name).Total
+		if total <= 0 || name == "" {
+			continue
+		}
+
+		v := WIPViolation{
+			Scope:     "direction:" + name,
+			Direction: name,
+			Limit:     total,
+		}
+		managers := map[int64]bool{}
+		for _, i := range directions[name] {
+			v.InProgress += stats[i].InProgress
+			for _, id := range stats[i].ManagerTgIDs {
+				if !managers[id] {
+					managers[id] = true
+					v.ManagerTgIDs = append(v.ManagerTgIDs, id)
+				}
+			}
+		}
+		if v.InProgress > total {
+			violations = append(violations, v)
+		}
+	}
+
+	return violations
+}
+
+type WIPOverview struct {
+	Employees  []WIPStat      `json:"employees"`
+	Violations []WIPViolation `json:"violations"`
+}
@@ -0,0 +1,44 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestApplyWIPLimits(t *testing.T) {
+	limits := WIPLimits{
+		PerExecutor: 2,
+		Directions: []DirectionWIPLimit{
+			{Direction: "QA", PerExecutor: 4},
+			{Direction: "Backend", Total: 4},
+		},
+	}
+
+	stats := []WIPStat{
+		{ExecutorID: uuid.New(), Executor: "Mark", Direction: "Backend", TgID: 1, ManagerTgIDs: []int64{10}, InProgress: 3},
+		{ExecutorID: uuid.New(), Executor: "Anna", Direction: "Backend", TgID: 2, ManagerTgIDs: []int64{10, 11}, InProgress: 2},
+		{ExecutorID: uuid.New(), Executor: "Olga", Direction: "QA", TgID: 3, InProgress: 3},
+		{ExecutorID: uuid.New(), Executor: "Ivan", Direction: "", InProgress: 1},
+	}
+
+	violations := ApplyWIPLimits(stats, limits)
+
+	expectedLimits := []int{2, 2, 4, 2}
+	expectedOver := []bool{true, false, false, false}
+	for i, s := range stats {
+		if s.Limit != expectedLimits[i] || s.OverLimit != expectedOver[i] {
+			t.Errorf("stats[%d] = limit %d over %v, expected limit %d over %v", i, s.Limit, s.OverLimit, expectedLimits[i], expectedOver[i])
+		}
+	}
+
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %+v", violations)
+	}
+	if violations[0].Executor != "Mark" || violations[0].InProgress != 3 || len(violations[0].TgIDs) != 1 {
+		t.Errorf("unexpected executor violation %+v", violations[0])
+	}
+	if violations[1].Scope != "direction:Backend" || violations[1].InProgress != 5 || len(violations[1].ManagerTgIDs) != 2 {
+		t.Errorf("unexpected direction violation %+v", violations[1])
+	}
+}
@@ -2,6 +2,7 @@ package notion
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"time"
 
@@ -83,17 +84,49 @@ func (r *TaskNotionRepository) CreateTask(ctx context.Context, task *entity_task
 		},
 		"Приоритет": map[string]interface{}{
 			"type": "select",
-			"select": map[string]interface{}{
-				"name": task.Priority,
-			},
+			"select": func() interface{} {
+				// Пустой выбор Notion не принимает, задача создается без приоритета
+				if task.Priority == "" {
+					return nil
+				}
+				return map[string]interface{}{
+					"name": task.Priority,
+				}
+			}(),
 		},
 	}
 
-	if _, err := r.client.CreatePage(viper.GetString("notion.databases.tasks"), req, nil); err != nil {
+	if task.ParentID != uuid.Nil {
+		req["Родительская задача"] = map[string]interface{}{
+			"type": "relation",
+			"relation": []map[string]interface{}{
+				{
+					"id": task.ParentID.String(),
+				},
+			},
+		}
+	}
+
+	resp, err := r.client.CreatePage(viper.GetString("notion.databases.tasks"), req, nil)
+	if err != nil {
 		slog.Error("Error writing time of", "error", err)
 		return err
 	}
 
+	// ID созданной страницы нужен для связи с родителем и проектом
+	page := struct {
+		ID string `json:"id"`
+	}{}
+	if err := json.Unmarshal(resp, &page); err != nil {
+		slog.Error("Error unmarshalling created task", "error", err)
+		return err
+	}
+	task.ID, err = uuid.Parse(page.ID)
+	if err != nil {
+		slog.Error("Error parsing created task id", "error", err)
+		return err
+	}
+
 	return nil
 }
 
@@ -108,7 +141,10 @@ type taskNotion struct {
 	ID             string `json:"id"`
 	CreatedTime    string `json:"created_time"`
 	LastEditedTime string `json:"last_edited_time"`
-	Properties     struct {
+	LastEditedBy   struct {
+		ID string `json:"id"`
+	} `json:"last_edited_by"`
+	Properties struct {
 		Status struct {
 			Status struct {
 				Name string `json:"name"`
@@ -279,6 +315,7 @@ func (t *taskNotion) toEntity() *entity_task.Task {
 		ID:             parseUUIDOrNil(t.ID),
 		CreatedTime:    createdTime,
 		LastEditedTime: lastEditedTime,
+		LastEditedBy:   t.LastEditedBy.ID,
 		Priority:       t.Properties.Priority.Select.Name,
 		Task:           getPlainText(t.Properties.Task.Title),
 		Status:         entity_task.Status(t.Properties.Status.Status.Name),
@@ -0,0 +1,69 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	entity_task "github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+)
+
+// GetNotionTask читает текущее состояние страницы задачи напрямую из Notion, минуя синхронизацию
+func (r *TaskNotionRepository) GetNotionTask(ctx context.Context, taskID uuid.UUID) (*entity_task.Task, error) {
+	resp, err := r.client.GetPage(taskID.String())
+	if err != nil {
+		slog.Error("Error getting task page from notion", "error", err, "task_id", taskID)
+		return nil, err
+	}
+
+	page := taskNotion{}
+	if err := json.Unmarshal(resp, &page); err != nil {
+		slog.Error("Error unmarshalling task page", "error", err, "task_id", taskID)
+		return nil, err
+	}
+
+	t := page.toEntity()
+	if t == nil {
+		return nil, fmt.Errorf("unable to parse task page %s", taskID)
+	}
+	return t, nil
+}
+
+// UpdateTaskField записывает в страницу задачи значение редактируемого поля
+func (r *TaskNotionRepository) UpdateTaskField(ctx context.Context, taskID uuid.UUID, field entity_task.EditableField, value string) error {
+	var properties map[string]interface{}
+	switch field {
+	case entity_task.EditableFieldPriority:
+		var sel interface{}
+		if value != "" {
+			sel = map[string]interface{}{"name": value}
+		}
+		properties = map[string]interface{}{
+			"Приоритет": map[string]interface{}{
+				"select": sel,
+			},
+		}
+	case entity_task.EditableFieldEstimate:
+		estimate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		properties = map[string]interface{}{
+			"Оценка": map[string]interface{}{
+				"number": estimate,
+			},
+		}
+	default:
+		return fmt.Errorf("%w: field %q is not editable", entity_task.ErrInvalidEditValue, field)
+	}
+
+	if _, err := r.client.UpdatePage(taskID.String(), properties); err != nil {
+		slog.Error("Error updating task field in notion", "error", err, "task_id", taskID, "field", field)
+		return err
+	}
+
+	return nil
+}
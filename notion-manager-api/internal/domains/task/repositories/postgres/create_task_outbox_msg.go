@@ -8,6 +8,7 @@ import (
 
 	entity_task "github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type taskOutboxMsgDB struct {
@@ -19,6 +20,13 @@ type taskOutboxMsgDB struct {
 	End        time.Time `db:"deadline_end"`
 	ExecutorID uuid.UUID `db:"executor_id"`
 	ProjectID  uuid.UUID `db:"project_id"`
+
+	ParentMsgID  int64          `db:"parent_msg_id"`
+	ParentTaskID uuid.UUID      `db:"parent_task_id"`
+	Tags         pq.StringArray `db:"tags"`
+	Role         string         `db:"role"`
+
+	CreatedTaskID uuid.UUID `db:"created_task_id"`
 }
 
 func (m *taskOutboxMsgDB) toEntity() *entity_task.TaskOutboxMsg {
@@ -31,6 +39,13 @@ func (m *taskOutboxMsgDB) toEntity() *entity_task.TaskOutboxMsg {
 		End:        m.End,
 		ExecutorID: m.ExecutorID,
 		ProjectID:  m.ProjectID,
+
+		ParentMsgID: m.ParentMsgID,
+		ParentID:    m.ParentTaskID,
+		Tags:        tagsFromStrings(m.Tags),
+		Role:        entity_task.TemplateRole(m.Role),
+
+		CreatedTaskID: m.CreatedTaskID,
 	}
 }
 
@@ -44,9 +59,32 @@ func taskOutboxMsgDBFromEntity(msg *entity_task.TaskOutboxMsg) *taskOutboxMsgDB
 		End:        msg.End,
 		ExecutorID: msg.ExecutorID,
 		ProjectID:  msg.ProjectID,
+
+		ParentMsgID:  msg.ParentMsgID,
+		ParentTaskID: msg.ParentID,
+		Tags:         tagsToStrings(msg.Tags),
+		Role:         string(msg.Role),
+
+		CreatedTaskID: msg.CreatedTaskID,
 	}
 }
 
+func tagsFromStrings(tags []string) []entity_task.Tag {
+	result := make([]entity_task.Tag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, entity_task.Tag(tag))
+	}
+	return result
+}
+
+func tagsToStrings(tags []entity_task.Tag) pq.StringArray {
+	result := make(pq.StringArray, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, string(tag))
+	}
+	return result
+}
+
 func (r *TaskPostgresRepository) CreateTaskOutboxMsg(ctx context.Context, msg *entity_task.TaskOutboxMsg) error {
 	tx, isNew, err := r.GetTx(ctx)
 	if err != nil {
@@ -55,7 +93,12 @@ func (r *TaskPostgresRepository) CreateTaskOutboxMsg(ctx context.Context, msg *e
 	if isNew {
 		defer tx.Rollback()
 	}
-	if _, err := tx.NamedExec(`INSERT INTO task_outbox (task, estimate, priority, deadline_start, deadline_end, executor_id, project_id) VALUES (:task, :estimate, :priority, :deadline_start, :deadline_end, :executor_id, :project_id)`, taskOutboxMsgDBFromEntity(msg)); err != nil {
+	m := taskOutboxMsgDBFromEntity(msg)
+	if err := tx.QueryRowxContext(ctx, `
+		INSERT INTO task_outbox (task, estimate, priority, deadline_start, deadline_end, executor_id, project_id, parent_msg_id, parent_task_id, tags, role)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0), NULLIF($9, $10::uuid), $11, $12)
+		RETURNING task_msg_id
+	`, m.Task, m.Estimate, m.Priority, m.Start, m.End, m.ExecutorID, m.ProjectID, m.ParentMsgID, m.ParentTaskID, uuid.Nil, m.Tags, m.Role).Scan(&msg.ID); err != nil {
 		slog.Error("Error insert task outbox msg", "error", err)
 		return err
 	}
@@ -70,9 +113,21 @@ func (r *TaskPostgresRepository) CreateTaskOutboxMsg(ctx context.Context, msg *e
 	return nil
 }
 
-func (r *TaskPostgresRepository) GetTaskOutboxMsgs(ctx context.Context) ([]entity_task.TaskOutboxMsg, error) {
+func (r *TaskPostgresRepository) GetTaskOutboxMsgs(ctx context.Context, maxAttempts int) ([]entity_task.TaskOutboxMsg, error) {
 	msgs := make([]taskOutboxMsgDB, 0)
-	if err := r.DB().SelectContext(ctx, &msgs, `SELECT * FROM task_outbox LIMIT 50`); err != nil {
+	// Дочерние задачи ждут, пока будет создан родитель, исчерпавшие попытки сообщения пропускаются
+	if err := r.DB().SelectContext(ctx, &msgs, `
+		SELECT
+			task_msg_id, task, estimate, priority, deadline_start, deadline_end, executor_id, project_id,
+			COALESCE(parent_msg_id, 0) AS parent_msg_id,
+			COALESCE(parent_task_id, $1::uuid) AS parent_task_id,
+			tags, role,
+			COALESCE(created_task_id, $1::uuid) AS created_task_id
+		FROM task_outbox
+		WHERE parent_msg_id IS NULL AND attempts < $2
+		ORDER BY task_msg_id
+		LIMIT 50
+	`, uuid.Nil, maxAttempts); err != nil {
 		slog.Error("Error get task outbox msgs", "error", err)
 		return nil, err
 	}
@@ -110,3 +165,39 @@ func (r *TaskPostgresRepository) DeleteTaskOutboxMsg(ctx context.Context, msg *e
 
 	return nil
 }
+
+// ResolveTaskOutboxParent проставляет созданную в Notion задачу родителем для ожидающих ее сообщений
+func (r *TaskPostgresRepository) ResolveTaskOutboxParent(ctx context.Context, msgID int64, taskID uuid.UUID) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		UPDATE task_outbox SET parent_task_id = $2, parent_msg_id = NULL WHERE parent_msg_id = $1
+	`, msgID, taskID); err != nil {
+		slog.Error("Error resolving task outbox parent", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// SetTaskOutboxCreatedTask запоминает задачу Notion, созданную по сообщению, до остальных шагов обработки
+func (r *TaskPostgresRepository) SetTaskOutboxCreatedTask(ctx context.Context, msgID int64, taskID uuid.UUID) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		UPDATE task_outbox SET created_task_id = $2 WHERE task_msg_id = $1
+	`, msgID, taskID); err != nil {
+		slog.Error("Error setting task outbox created task", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FailTaskOutboxMsg засчитывает неудачную попытку обработать сообщение
+func (r *TaskPostgresRepository) FailTaskOutboxMsg(ctx context.Context, msgID int64, reason string) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		UPDATE task_outbox SET attempts = attempts + 1, last_error = $2 WHERE task_msg_id = $1
+	`, msgID, reason); err != nil {
+		slog.Error("Error failing task outbox msg", "error", err)
+		return err
+	}
+
+	return nil
+}
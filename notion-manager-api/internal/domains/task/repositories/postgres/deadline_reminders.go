@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+)
+
+type deadlineTaskDB struct {
+	taskDB
+	Executor       string `db:"executor"`
+	ExecutorTgID   int64  `db:"executor_tg_id"`
+	ExecutorAbsent bool   `db:"executor_absent"`
+	ManagerTgID    int64  `db:"manager_tg_id"`
+	ManagerAbsent  bool   `db:"manager_absent"`
+}
+
+// ListTasksForReminder возвращает незавершенные задачи с дедлайном в [endFrom, endTo),
+// по которым еще не отправлялось напоминание kind для текущего дедлайна.
+// Флаги отсутствия считаются по таблице weekdays на день day.
+func (r *TaskPostgresRepository) ListTasksForReminder(ctx context.Context, kind task.ReminderKind, endFrom, endTo, day time.Time) ([]task.DeadlineTask, error) {
+	query := `
+		SELECT
+			tasks.task_id, tasks.title, tasks.status, tasks.priority, tasks.project_id,
+			tasks.executor_id, tasks.responsible_id, tasks.start, tasks."end",
+			COALESCE(projects.name, '') AS project_name,
+			COALESCE(executor.username, '') AS executor,
+			COALESCE(executor.tg_id, 0) AS executor_tg_id,
+			COALESCE(manager.tg_id, 0) AS manager_tg_id,
+			EXISTS (
+				SELECT 1 FROM weekdays w
+				WHERE w.employee_id::text = executor.profile_id
+					AND w.start_time::date <= $4::date
+					AND GREATEST(w.end_time, w.start_time)::date >= $4::date
+			) AS executor_absent,
+			EXISTS (
+				SELECT 1 FROM weekdays w
+				WHERE w.employee_id::text = manager.profile_id
+					AND w.start_time::date <= $4::date
+					AND GREATEST(w.end_time, w.start_time)::date >= $4::date
+			) AS manager_absent
+		FROM tasks
+		LEFT JOIN projects ON projects.project_id = tasks.project_id::text
+		LEFT JOIN employees executor ON executor.employee_id = tasks.executor_id
+		LEFT JOIN employees manager ON manager.profile_id = projects.manager_id
+		WHERE tasks."end" >= $2 AND tasks."end" < $3
+			AND tasks.status NOT IN ($5, $6)
+			AND NOT EXISTS (
+				SELECT 1 FROM task_deadline_reminders rem
+				WHERE rem.task_id = tasks.task_id AND rem.kind = $1 AND rem.deadline = tasks."end"
+			)
+		ORDER BY tasks."end" ASC
+	`
+
+	tasksDB := []deadlineTaskDB{}
+	if err := r.DB().SelectContext(ctx, &tasksDB, query, string(kind), endFrom, endTo, day, string(task.StatusDone), string(task.StatusCancelled)); err != nil {
+		slog.Error("Error listing tasks for deadline reminder", "error", err)
+		return nil, err
+	}
+
+	tasks := make([]task.DeadlineTask, 0, len(tasksDB))
+	for _, t := range tasksDB {
+		tasks = append(tasks, task.DeadlineTask{
+			Task:           *t.toEntity(),
+			Executor:       t.Executor,
+			ExecutorTgID:   t.ExecutorTgID,
+			ExecutorAbsent: t.ExecutorAbsent,
+			ManagerTgID:    t.ManagerTgID,
+			ManagerAbsent:  t.ManagerAbsent,
+		})
+	}
+
+	return tasks, nil
+}
+
+func (r *TaskPostgresRepository) MarkReminderSent(ctx context.Context, taskID uuid.UUID, kind task.ReminderKind, deadline, sentAt time.Time) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		INSERT INTO task_deadline_reminders (task_id, kind, deadline, sent_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (task_id, kind, deadline) DO NOTHING
+	`, taskID, string(kind), deadline, sentAt); err != nil {
+		slog.Error("Error marking deadline reminder sent", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+)
+
+// GetTask возвращает задачу в том виде, в котором она сохранена, или nil, если ее еще нет
+func (r *TaskPostgresRepository) GetTask(ctx context.Context, taskID uuid.UUID) (*task.Task, error) {
+	t := taskDB{}
+	err := r.DB().GetContext(ctx, &t, `
+		SELECT task_id, created_time, last_edited_time, title, priority, status, parent_id,
+			creator_id, project_id, estimate, start, "end", previous_id, next_id, sh,
+			total_hours, tbh, cp, total_estimate, plan_fact, duration, cr, COALESCE(ikp, '') AS ikp, main_task,
+			executor_id, responsible_id
+		FROM tasks WHERE task_id = $1
+	`, taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		slog.Error("Error getting task", "error", err)
+		return nil, err
+	}
+
+	return t.toEntity(), nil
+}
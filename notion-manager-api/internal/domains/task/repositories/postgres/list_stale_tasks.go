@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+)
+
+type staleTaskDB struct {
+	taskDB
+	StatusSince     time.Time `db:"status_since"`
+	ResponsibleTgID int64     `db:"responsible_tg_id"`
+	ManagerTgID     int64     `db:"manager_tg_id"`
+}
+
+// ListStaleTasks возвращает задачи, которые находятся в статусе с момента раньше statusBefore.
+// Момент смены статуса берется из журнала изменений, а если его там нет - из last_edited_time.
+// Отложенные задачи и задачи, о которых уже напоминали после notifiedBefore, пропускаются.
+func (r *TaskPostgresRepository) ListStaleTasks(ctx context.Context, status task.Status, statusBefore, notifiedBefore time.Time) ([]task.StaleTask, error) {
+	query := `
+		SELECT * FROM (
+			SELECT
+				tasks.task_id, tasks.title, tasks.status, tasks.priority, tasks.project_id,
+				tasks.executor_id, tasks.responsible_id, tasks.last_edited_time,
+				COALESCE(projects.name, '') AS project_name,
+				COALESCE((
+					SELECT MAX(a.edited_at) FROM audit_log a
+					WHERE a.entity = 'task' AND a.entity_id = tasks.task_id AND a.field = 'status'
+				), tasks.last_edited_time) AS status_since,
+				COALESCE(responsible.tg_id, 0) AS responsible_tg_id,
+				COALESCE(manager.tg_id, 0) AS manager_tg_id
+			FROM tasks
+			LEFT JOIN projects ON projects.project_id = tasks.project_id::text
+			LEFT JOIN employees responsible ON responsible.employee_id = tasks.responsible_id
+			LEFT JOIN employees manager ON manager.profile_id = projects.manager_id
+			LEFT JOIN task_stale_alerts alerts ON alerts.task_id = tasks.task_id
+			WHERE tasks.status = $1
+				AND (alerts.snoozed_until IS NULL OR alerts.snoozed_until < NOW())
+				AND (alerts.notified_at IS NULL OR alerts.notified_at < $3)
+		) stale
+		WHERE stale.status_since < $2
+		ORDER BY stale.status_since ASC
+	`
+
+	tasksDB := []staleTaskDB{}
+	if err := r.DB().SelectContext(ctx, &tasksDB, query, string(status), statusBefore, notifiedBefore); err != nil {
+		slog.Error("Error listing stale tasks", "error", err)
+		return nil, err
+	}
+
+	tasks := make([]task.StaleTask, 0, len(tasksDB))
+	for _, t := range tasksDB {
+		tasks = append(tasks, task.StaleTask{
+			Task:            *t.toEntity(),
+			StatusSince:     t.StatusSince,
+			ResponsibleTgID: t.ResponsibleTgID,
+			ManagerTgID:     t.ManagerTgID,
+		})
+	}
+
+	return tasks, nil
+}
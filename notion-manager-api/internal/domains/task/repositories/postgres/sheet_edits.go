@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type sheetEditDB struct {
+	ID            int64     `db:"edit_id"`
+	SpreadsheetID string    `db:"spreadsheet_id"`
+	TaskID        uuid.UUID `db:"task_id"`
+	TaskName      string    `db:"task_name"`
+	Field         string    `db:"field"`
+	Value         string    `db:"value"`
+	BaseValue     string    `db:"base_value"`
+	NotionValue   string    `db:"notion_value"`
+	Status        string    `db:"status"`
+	Reason        string    `db:"reason"`
+	CreatedAt     time.Time `db:"created_at"`
+	Attempts      int       `db:"attempts"`
+	LastError     string    `db:"last_error"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+}
+
+func (e *sheetEditDB) toEntity() task.SheetEdit {
+	return task.SheetEdit{
+		ID:            e.ID,
+		SpreadsheetID: e.SpreadsheetID,
+		TaskID:        e.TaskID,
+		TaskName:      e.TaskName,
+		Field:         task.EditableField(e.Field),
+		Value:         e.Value,
+		BaseValue:     e.BaseValue,
+		NotionValue:   e.NotionValue,
+		Status:        task.SheetEditStatus(e.Status),
+		Reason:        e.Reason,
+		CreatedAt:     e.CreatedAt,
+		Attempts:      e.Attempts,
+		LastError:     e.LastError,
+		NextAttemptAt: e.NextAttemptAt,
+	}
+}
+
+// GetTaskSheetCells возвращает значения редактируемых ячеек, выгруженные в таблицу в прошлый раз
+func (r *TaskPostgresRepository) GetTaskSheetCells(ctx context.Context, spreadsheetID string) (map[uuid.UUID]map[task.EditableField]string, error) {
+	rows := []struct {
+		TaskID uuid.UUID `db:"task_id"`
+		Field  string    `db:"field"`
+		Value  string    `db:"value"`
+	}{}
+	if err := r.DB().SelectContext(ctx, &rows, `
+		SELECT task_id, field, value FROM task_sheet_cells WHERE spreadsheet_id = $1
+	`, spreadsheetID); err != nil {
+		slog.Error("Error getting task sheet cells", "error", err)
+		return nil, err
+	}
+
+	cells := make(map[uuid.UUID]map[task.EditableField]string, len(rows))
+	for _, row := range rows {
+		if cells[row.TaskID] == nil {
+			cells[row.TaskID] = map[task.EditableField]string{}
+		}
+		cells[row.TaskID][task.EditableField(row.Field)] = row.Value
+	}
+
+	return cells, nil
+}
+
+// SetTaskSheetCells заменяет сохраненные значения ячеек таблицы на только что выгруженные
+func (r *TaskPostgresRepository) SetTaskSheetCells(ctx context.Context, spreadsheetID string, cells map[uuid.UUID]map[task.EditableField]string) error {
+	tx, isNew, err := r.GetTx(ctx)
+	if err != nil {
+		return err
+	}
+	if isNew {
+		defer tx.Rollback()
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_sheet_cells WHERE spreadsheet_id = $1`, spreadsheetID); err != nil {
+		slog.Error("Error clearing task sheet cells", "error", err)
+		return err
+	}
+
+	taskIDs, fields, values := []string{}, pq.StringArray{}, pq.StringArray{}
+	for taskID, taskCells := range cells {
+		for field, value := range taskCells {
+			taskIDs = append(taskIDs, taskID.String())
+			fields = append(fields, string(field))
+			values = append(values, value)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO task_sheet_cells (spreadsheet_id, task_id, field, value)
+		SELECT $1, t.task_id::uuid, t.field, t.value FROM unnest($2::text[], $3::text[], $4::text[]) AS t(task_id, field, value)
+	`, spreadsheetID, pq.StringArray(taskIDs), fields, values); err != nil {
+		slog.Error("Error saving task sheet cells", "error", err)
+		return err
+	}
+
+	if isNew {
+		return tx.Commit()
+	}
+
+	return nil
+}
+
+// CreateSheetEdit сохраняет правку из таблицы. Ожидающая правка той же ячейки заменяется новой,
+// исходное значение при этом остается прежним, а счетчик неудачных отправок сбрасывается.
+func (r *TaskPostgresRepository) CreateSheetEdit(ctx context.Context, edit *task.SheetEdit) error {
+	if err := r.DB().QueryRowxContext(ctx, `
+		INSERT INTO task_sheet_edits (spreadsheet_id, task_id, task_name, field, value, base_value, notion_value, status, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (spreadsheet_id, task_id, field) WHERE status = 'pending'
+		DO UPDATE SET value = EXCLUDED.value, task_name = EXCLUDED.task_name, created_at = EXCLUDED.created_at,
+			attempts = 0, last_error = '', next_attempt_at = NOW()
+		RETURNING edit_id
+	`, edit.SpreadsheetID, edit.TaskID, edit.TaskName, string(edit.Field), edit.Value, edit.BaseValue, edit.NotionValue, string(edit.Status), edit.Reason, edit.CreatedAt).Scan(&edit.ID); err != nil {
+		slog.Error("Error creating sheet edit", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *TaskPostgresRepository) ListSheetEdits(ctx context.Context, filter task.SheetEditFilter) ([]task.SheetEdit, error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+	if filter.SpreadsheetID != "" {
+		args = append(args, filter.SpreadsheetID)
+		conditions = append(conditions, fmt.Sprintf("spreadsheet_id = $%d", len(args)))
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make(pq.StringArray, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		args = append(args, statuses)
+		conditions = append(conditions, fmt.Sprintf("status = ANY($%d)", len(args)))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !filter.Due.IsZero() {
+		args = append(args, filter.Due)
+		conditions = append(conditions, fmt.Sprintf("next_attempt_at <= $%d", len(args)))
+	}
+
+	query := `
+		SELECT edit_id, spreadsheet_id, task_id, task_name, field, value, base_value, notion_value, status, reason, created_at,
+			attempts, last_error, next_attempt_at
+		FROM task_sheet_edits
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY edit_id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	edits := []sheetEditDB{}
+	if err := r.DB().SelectContext(ctx, &edits, query, args...); err != nil {
+		slog.Error("Error listing sheet edits", "error", err)
+		return nil, err
+	}
+
+	result := make([]task.SheetEdit, 0, len(edits))
+	for _, e := range edits {
+		result = append(result, e.toEntity())
+	}
+
+	return result, nil
+}
+
+// DeleteSheetEdit удаляет правку, отправленную в Notion
+func (r *TaskPostgresRepository) DeleteSheetEdit(ctx context.Context, editID int64) error {
+	if _, err := r.DB().ExecContext(ctx, `DELETE FROM task_sheet_edits WHERE edit_id = $1`, editID); err != nil {
+		slog.Error("Error deleting sheet edit", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// MarkSheetEditConflict переводит ожидающую правку в конфликт: страница в Notion успела измениться
+func (r *TaskPostgresRepository) MarkSheetEditConflict(ctx context.Context, editID int64, notionValue, reason string) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		UPDATE task_sheet_edits SET status = $2, notion_value = $3, reason = $4 WHERE edit_id = $1
+	`, editID, string(task.SheetEditStatusConflict), notionValue, reason); err != nil {
+		slog.Error("Error marking sheet edit conflict", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// DelaySheetEdit запоминает неудачную отправку правки и откладывает следующую до nextAttemptAt
+func (r *TaskPostgresRepository) DelaySheetEdit(ctx context.Context, editID int64, lastError string, nextAttemptAt time.Time) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		UPDATE task_sheet_edits SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE edit_id = $1
+	`, editID, lastError, nextAttemptAt); err != nil {
+		slog.Error("Error delaying sheet edit", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// MarkSheetEditFailed снимает правку с отправки после последней неудачной попытки,
+// чтобы она не занимала очередь и попала в отчет о конфликтах
+func (r *TaskPostgresRepository) MarkSheetEditFailed(ctx context.Context, editID int64, lastError string) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		UPDATE task_sheet_edits SET status = $2, attempts = attempts + 1, last_error = $3, reason = $3 WHERE edit_id = $1
+	`, editID, string(task.SheetEditStatusFailed), lastError); err != nil {
+		slog.Error("Error marking sheet edit failed", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *TaskPostgresRepository) MarkStaleTasksNotified(ctx context.Context, taskIDs []uuid.UUID, notifiedAt time.Time) error {
+	tx, isNew, err := r.GetTx(ctx)
+	if err != nil {
+		return err
+	}
+	if isNew {
+		defer tx.Rollback()
+	}
+
+	for _, taskID := range taskIDs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO task_stale_alerts (task_id, notified_at) VALUES ($1, $2)
+			ON CONFLICT (task_id) DO UPDATE SET notified_at = EXCLUDED.notified_at
+		`, taskID, notifiedAt); err != nil {
+			slog.Error("Error marking stale task notified", "error", err)
+			return err
+		}
+	}
+
+	if isNew {
+		return tx.Commit()
+	}
+
+	return nil
+}
+
+func (r *TaskPostgresRepository) SnoozeStaleTask(ctx context.Context, taskID uuid.UUID, until time.Time) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		INSERT INTO task_stale_alerts (task_id, snoozed_until) VALUES ($1, $2)
+		ON CONFLICT (task_id) DO UPDATE SET snoozed_until = EXCLUDED.snoozed_until
+	`, taskID, until); err != nil {
+		slog.Error("Error snoozing stale task", "error", err)
+		return err
+	}
+
+	return nil
+}
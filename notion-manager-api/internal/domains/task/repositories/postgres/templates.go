@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type templateDB struct {
+	ID          int64     `db:"template_id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type templateItemDB struct {
+	ID              int64          `db:"item_id"`
+	TemplateID      int64          `db:"template_id"`
+	ParentItemID    int64          `db:"parent_item_id"`
+	Title           string         `db:"title"`
+	Role            string         `db:"role"`
+	StartOffsetDays int            `db:"start_offset_days"`
+	DurationDays    int            `db:"duration_days"`
+	Tags            pq.StringArray `db:"tags"`
+	Estimate        string         `db:"estimate"`
+	Priority        string         `db:"priority"`
+}
+
+func (r *TaskPostgresRepository) CreateTemplate(ctx context.Context, template *task.Template) error {
+	tx, isNew, err := r.GetTx(ctx)
+	if err != nil {
+		return err
+	}
+	if isNew {
+		defer tx.Rollback()
+	}
+
+	if err := tx.QueryRowxContext(ctx, `
+		INSERT INTO task_templates (name, description) VALUES ($1, $2) RETURNING template_id, created_at
+	`, template.Name, template.Description).Scan(&template.ID, &template.CreatedAt); err != nil {
+		slog.Error("Error creating task template", "error", err)
+		return err
+	}
+
+	var insertItems func(items []task.TemplateItem, parentID int64) error
+	insertItems = func(items []task.TemplateItem, parentID int64) error {
+		for i := range items {
+			item := &items[i]
+			if err := tx.QueryRowxContext(ctx, `
+				INSERT INTO task_template_items (template_id, parent_item_id, position, title, role, start_offset_days, duration_days, tags, estimate, priority)
+				VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $9, $10)
+				RETURNING item_id
+			`, template.ID, parentID, i, item.Title, string(item.Role), item.StartOffsetDays, item.DurationDays, tagsToStrings(item.Tags), item.Estimate, item.Priority).Scan(&item.ID); err != nil {
+				slog.Error("Error creating task template item", "error", err)
+				return err
+			}
+			if err := insertItems(item.Subtasks, item.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := insertItems(template.Items, 0); err != nil {
+		return err
+	}
+
+	if isNew {
+		return tx.Commit()
+	}
+
+	return nil
+}
+
+func (r *TaskPostgresRepository) ListTemplates(ctx context.Context) ([]task.Template, error) {
+	templatesDB := []templateDB{}
+	if err := r.DB().SelectContext(ctx, &templatesDB, `
+		SELECT template_id, name, description, created_at FROM task_templates ORDER BY name
+	`); err != nil {
+		slog.Error("Error listing task templates", "error", err)
+		return nil, err
+	}
+
+	itemsDB := []templateItemDB{}
+	if err := r.DB().SelectContext(ctx, &itemsDB, `
+		SELECT item_id, template_id, COALESCE(parent_item_id, 0) AS parent_item_id, title, role,
+			start_offset_days, duration_days, tags, estimate, priority
+		FROM task_template_items
+		ORDER BY position, item_id
+	`); err != nil {
+		slog.Error("Error listing task template items", "error", err)
+		return nil, err
+	}
+
+	itemsByTemplate := map[int64][]templateItemDB{}
+	for _, item := range itemsDB {
+		itemsByTemplate[item.TemplateID] = append(itemsByTemplate[item.TemplateID], item)
+	}
+
+	templates := make([]task.Template, 0, len(templatesDB))
+	for _, t := range templatesDB {
+		templates = append(templates, task.Template{
+			ID:          t.ID,
+			Name:        t.Name,
+			Description: t.Description,
+			CreatedAt:   t.CreatedAt,
+			Items:       buildTemplateItems(itemsByTemplate[t.ID], 0),
+		})
+	}
+
+	return templates, nil
+}
+
+func buildTemplateItems(items []templateItemDB, parentID int64) []task.TemplateItem {
+	result := []task.TemplateItem{}
+	for _, item := range items {
+		if item.ParentItemID != parentID {
+			continue
+		}
+		result = append(result, task.TemplateItem{
+			ID:              item.ID,
+			Title:           item.Title,
+			Role:            task.TemplateRole(item.Role),
+			StartOffsetDays: item.StartOffsetDays,
+			DurationDays:    item.DurationDays,
+			Tags:            tagsFromStrings(item.Tags),
+			Estimate:        item.Estimate,
+			Priority:        item.Priority,
+			Subtasks:        buildTemplateItems(items, item.ID),
+		})
+	}
+	return result
+}
+
+// LinkProjectTask запоминает служебную задачу проекта (менеджмент, тестирование)
+func (r *TaskPostgresRepository) LinkProjectTask(ctx context.Context, projectID uuid.UUID, role task.TemplateRole, taskID uuid.UUID) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		INSERT INTO project_role_tasks (project_id, role, task_id) VALUES ($1, $2, $3)
+		ON CONFLICT (project_id, role) DO UPDATE SET task_id = EXCLUDED.task_id
+	`, projectID.String(), string(role), taskID); err != nil {
+		slog.Error("Error linking project task", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type wipStatDB struct {
+	ExecutorID   uuid.UUID     `db:"executor_id"`
+	Executor     string        `db:"executor"`
+	Direction    string        `db:"direction"`
+	TgID         int64         `db:"tg_id"`
+	ManagerTgIDs pq.Int64Array `db:"manager_tg_ids"`
+	InProgress   int           `db:"in_progress"`
+}
+
+// ListWIPStats возвращает количество задач "В работе" по исполнителям
+// вместе с telegram-id менеджеров проектов этих задач
+func (r *TaskPostgresRepository) ListWIPStats(ctx context.Context) ([]task.WIPStat, error) {
+	query := `
+		SELECT
+			tasks.executor_id,
+			COALESCE(executor.username, '') AS executor,
+			COALESCE(executor.direction, '') AS direction,
+			COALESCE(executor.tg_id, 0) AS tg_id,
+			ARRAY_REMOVE(ARRAY_AGG(DISTINCT manager.tg_id), NULL) AS manager_tg_ids,
+			COUNT(DISTINCT tasks.task_id) AS in_progress
+		FROM tasks
+		LEFT JOIN projects ON projects.project_id = tasks.project_id::text
+		LEFT JOIN employees executor ON executor.employee_id = tasks.executor_id
+		LEFT JOIN employees manager ON manager.profile_id = projects.manager_id AND manager.tg_id <> 0
+		WHERE tasks.status = $1 AND tasks.executor_id <> $2
+		GROUP BY tasks.executor_id, executor.username, executor.direction, executor.tg_id
+		ORDER BY in_progress DESC, executor
+	`
+
+	statsDB := []wipStatDB{}
+	if err := r.DB().SelectContext(ctx, &statsDB, query, string(task.StatusInProgress), uuid.Nil); err != nil {
+		slog.Error("Error listing wip stats", "error", err)
+		return nil, err
+	}
+
+	stats := make([]task.WIPStat, 0, len(statsDB))
+	for _, s := range statsDB {
+		stats = append(stats, task.WIPStat{
+			ExecutorID:   s.ExecutorID,
+			Executor:     s.Executor,
+			Direction:    s.Direction,
+			TgID:         s.TgID,
+			ManagerTgIDs: []int64(s.ManagerTgIDs),
+			InProgress:   s.InProgress,
+		})
+	}
+
+	return stats, nil
+}
+
+// ListWIPViolations возвращает ранее оповещенные нарушения: scope -> количество задач
+func (r *TaskPostgresRepository) ListWIPViolations(ctx context.Context) (map[string]int, error) {
+	rows := []struct {
+		Scope      string `db:"scope"`
+		InProgress int    `db:"in_progress"`
+	}{}
+	if err := r.DB().SelectContext(ctx, &rows, `SELECT scope, in_progress FROM task_wip_violations`); err != nil {
+		slog.Error("Error listing wip violations", "error", err)
+		return nil, err
+	}
+
+	violations := make(map[string]int, len(rows))
+	for _, row := range rows {
+		violations[row.Scope] = row.InProgress
+	}
+
+	return violations, nil
+}
+
+// SetWIPViolations заменяет сохраненные нарушения актуальными
+func (r *TaskPostgresRepository) SetWIPViolations(ctx context.Context, violations []task.WIPViolation, notifiedAt time.Time) error {
+	tx, isNew, err := r.GetTx(ctx)
+	if err != nil {
+		return err
+	}
+	if isNew {
+		defer tx.Rollback()
+	}
+
+	scopes := make([]string, 0, len(violations))
+	for _, v := range violations {
+		scopes = append(scopes, v.Scope)
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO task_wip_violations (scope, in_progress, notified_at) VALUES ($1, $2, $3)
+			ON CONFLICT (scope) DO UPDATE SET in_progress = EXCLUDED.in_progress, notified_at = EXCLUDED.notified_at
+		`, v.Scope, v.InProgress, notifiedAt); err != nil {
+			slog.Error("Error setting wip violation", "error", err)
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_wip_violations WHERE NOT (scope = ANY($1))`, pq.Array(scopes)); err != nil {
+		slog.Error("Error deleting resolved wip violations", "error", err)
+		return err
+	}
+
+	if isNew {
+		return tx.Commit()
+	}
+
+	return nil
+}
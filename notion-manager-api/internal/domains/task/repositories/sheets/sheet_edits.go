@@ -0,0 +1,104 @@
+package sheets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/Corray333/employee_dashboard/internal/export"
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+// ReadSheetsTaskCells читает из листа задач значения редактируемых столбцов по задачам.
+// Править можно только Google-таблицу, поэтому для файловых выгрузок правок нет.
+func (r *TaskSheetsRepository) ReadSheetsTaskCells(ctx context.Context, sheetID string) (map[uuid.UUID]map[task.EditableField]interface{}, error) {
+	if _, ok := r.sink.(*export.SheetsSink); !ok {
+		return nil, nil
+	}
+
+	rows, err := r.client.ReadRows(ctx, sheetID, viper.GetString("sheets.task_sheet"), len(taskHeader))
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make(map[uuid.UUID]map[task.EditableField]interface{}, len(rows))
+	for _, row := range rows {
+		taskID, err := uuid.Parse(gsheets.NotionID(row, 0))
+		if err != nil {
+			continue
+		}
+
+		taskCells := make(map[task.EditableField]interface{}, len(taskEditableColumns))
+		for field, col := range taskEditableColumns {
+			if col < len(row) {
+				taskCells[field] = row[col]
+			} else {
+				taskCells[field] = nil
+			}
+		}
+		cells[taskID] = taskCells
+	}
+
+	return cells, nil
+}
+
+var conflictsHeader = []string{"Задача", "Поле", "Значение в таблице", "Значение в Notion", "Статус", "Причина", "Обнаружено", "Правка"}
+
+var editableFieldNames = map[task.EditableField]string{
+	task.EditableFieldPriority: "Приоритет",
+	task.EditableFieldEstimate: "Оценка задачи",
+}
+
+var sheetEditStatusNames = map[task.SheetEditStatus]string{
+	task.SheetEditStatusConflict: "Конфликт",
+	task.SheetEditStatusInvalid:  "Некорректное значение",
+	task.SheetEditStatusFailed:   "Не отправлено в Notion",
+}
+
+// UpdateSheetsTaskConflicts записывает отчет о конфликтных и некорректных правках в отдельный лист.
+// Лист создается при первом конфликте, пока конфликтов не было, таблица не меняется.
+func (r *TaskSheetsRepository) UpdateSheetsTaskConflicts(ctx context.Context, sheetID string, edits []task.SheetEdit) error {
+	table := export.Table{Name: viper.GetString("sheets.two_way.conflicts_sheet"), Header: conflictsHeader}
+
+	if _, ok := r.sink.(*export.SheetsSink); ok {
+		if _, err := r.client.SheetID(ctx, sheetID, table.Name); err != nil {
+			if !errors.Is(err, gsheets.ErrSheetNotFound) {
+				return err
+			}
+			if len(edits) == 0 {
+				return nil
+			}
+			if err := r.client.AddSheet(ctx, sheetID, table.Name, table.Header); err != nil {
+				return err
+			}
+		}
+	}
+
+	rows := make([][]interface{}, 0, len(edits))
+	for _, e := range edits {
+		rows = append(rows, []interface{}{
+			fmt.Sprintf(`=HYPERLINK("%s"; "%s")`, fmt.Sprintf("https://notion.so/%s", strings.ReplaceAll(e.TaskID.String(), "-", "")), strings.ReplaceAll(e.TaskName, "\"", "\"\"")),
+			editableFieldNames[e.Field],
+			e.Value,
+			e.NotionValue,
+			sheetEditStatusNames[e.Status],
+			e.Reason,
+			e.CreatedAt.Format("02/01/2006 15:04:05"),
+			fmt.Sprintf("#%d", e.ID),
+		})
+	}
+
+	return r.sink.WriteTable(ctx, sheetID, table, rows, conflictRowKey)
+}
+
+// conflictRowKey - строки отчета узнаются по номеру правки в последнем столбце
+func conflictRowKey(row []interface{}) string {
+	if len(row) < len(conflictsHeader) {
+		return ""
+	}
+	return fmt.Sprint(row[len(conflictsHeader)-1])
+}
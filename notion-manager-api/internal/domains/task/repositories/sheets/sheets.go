@@ -3,144 +3,75 @@ package sheets
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/Corray333/employee_dashboard/internal/export"
 	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
 	"github.com/spf13/viper"
-	"google.golang.org/api/sheets/v4"
 )
 
 type TaskSheetsRepository struct {
 	client *gsheets.Client
+	sink   export.Sink
 }
 
-func NewTaskSheetsRepository(client *gsheets.Client) *TaskSheetsRepository {
+func NewTaskSheetsRepository(client *gsheets.Client, sink export.Sink) *TaskSheetsRepository {
 	return &TaskSheetsRepository{
 		client: client,
+		sink:   sink,
 	}
 }
 
-// getSheetIDByName retrieves the sheet ID by sheet name
-func (r *TaskSheetsRepository) getSheetIDByName(ctx context.Context, spreadsheetID, sheetName string) (int64, error) {
-	spreadsheet, err := r.client.Svc().Spreadsheets.Get(spreadsheetID).Do()
-	if err != nil {
-		return 0, err
-	}
-
-	for _, sheet := range spreadsheet.Sheets {
-		if sheet.Properties.Title == sheetName {
-			return sheet.Properties.SheetId, nil
-		}
-	}
+var taskHeader = []string{
+	"Задача", "Приоритет", "Статус", "Начало", "Окончание", "Родительская задача", "Главная задача", "Направление", "Экспертиза", "Затрачено часов", "Оценка", "Оценка задачи",
+}
 
-	return 0, fmt.Errorf("sheet with name '%s' not found", sheetName)
+// taskEditableColumns - столбцы листа задач, правки в которых переносятся в Notion
+var taskEditableColumns = map[task.EditableField]int{
+	task.EditableFieldPriority: 1,
+	task.EditableFieldEstimate: 11,
 }
 
+// UpdateSheetsTasks записывает в лист задачи и вспомогательные строки, меняя только отличающиеся строки
 func (r *TaskSheetsRepository) UpdateSheetsTasks(ctx context.Context, sheetID string, tasks []task.Task) error {
-	sheetName := viper.GetString("sheets.task_sheet")
-
-	// Если задач нет, очищаем редактируемые столбцы и выходим
-	if len(tasks) == 0 {
-		rowLen := len(entityToSheetsTask(&task.Task{}))
-		lastColLetter := string(rune('A' + rowLen - 1))
-		clearRange := sheetName + "!A2:" + lastColLetter
-		if _, err := r.client.Svc().Spreadsheets.Values.Clear(sheetID, clearRange, &sheets.ClearValuesRequest{}).Do(); err != nil {
-			slog.Error("Error clearing old values (empty tasks)", "error", err)
-			return err
-		}
-		return nil
-	}
-
-	writeRange := sheetName + "!A2" // Start writing from the 2nd row
-
-	// Build all rows to write (main tasks + additional generated rows)
-	var vr sheets.ValueRange
-	vr.MajorDimension = "ROWS"
+	rows := make([][]interface{}, 0, len(tasks))
 
 	// Основные строки задач
 	for _, t := range tasks {
-		vr.Values = append(vr.Values, entityToSheetsTask(&t))
+		rows = append(rows, entityToSheetsTask(&t))
 	}
 
 	// Дополнительные строки по статусам и месяцам
-	monthStatusRows := generateMonthStatusRows(tasks)
-	for _, row := range monthStatusRows {
-		vr.Values = append(vr.Values, row)
-	}
+	rows = append(rows, generateMonthStatusRows(tasks)...)
 
 	// Дополнительные строки для родительских задач
-	parentTaskRows := generateParentTaskRows(tasks)
-	for _, row := range parentTaskRows {
-		vr.Values = append(vr.Values, row)
-	}
+	rows = append(rows, generateParentTaskRows(tasks)...)
 
-	// Получаем фактический ID листа по имени
-	actualSheetID, err := r.getSheetIDByName(ctx, sheetID, sheetName)
-	if err != nil {
-		slog.Error("Error getting sheet ID by name", "error", err, "sheetName", sheetName)
-		// Fallback to sheet ID 0 if we can't find the sheet
-		actualSheetID = 0
-	}
+	table := export.Table{Name: viper.GetString("sheets.task_sheet"), Header: taskHeader}
+	return r.sink.WriteTable(ctx, sheetID, table, rows, taskRowKey)
+}
 
-	// Убедимся, что в листе достаточно строк, чтобы записать все данные
-	spreadsheet, err := r.client.Svc().Spreadsheets.Get(sheetID).Do()
-	if err != nil {
-		slog.Error("Error getting spreadsheet properties", "error", err)
-		return err
+// taskRowKey - ключ строки листа задач: строки задач узнаются по ссылке на задачу,
+// строки родительских задач по месяцам - по ссылке на родителя и месяцу, строки статусов - по статусу и месяцу
+func taskRowKey(row []interface{}) string {
+	if id := gsheets.NotionID(row, 0); id != "" {
+		return "task:" + id
 	}
 
-	var currentRowCapacity int64
-	for _, sh := range spreadsheet.Sheets {
-		if sh.Properties.Title == sheetName {
-			if sh.Properties.GridProperties != nil {
-				currentRowCapacity = sh.Properties.GridProperties.RowCount
-			}
-			break
-		}
+	month := ""
+	if len(row) > 3 {
+		month = fmt.Sprint(row[3])
 	}
-
-	// Требуемое количество строк = 1 (шапка) + количество строк с данными
-	requiredRows := int64(1 + len(vr.Values))
-	if currentRowCapacity < requiredRows {
-		missing := requiredRows - currentRowCapacity
-		appendReq := &sheets.BatchUpdateSpreadsheetRequest{
-			Requests: []*sheets.Request{
-				{
-					AppendDimension: &sheets.AppendDimensionRequest{
-						SheetId:   actualSheetID,
-						Dimension: "ROWS",
-						Length:    missing,
-					},
-				},
-			},
-		}
-
-		if _, err := r.client.Svc().Spreadsheets.BatchUpdate(sheetID, appendReq).Do(); err != nil {
-			slog.Error("Error appending rows", "error", err)
-			return err
-		}
+	if id := gsheets.NotionID(row, 5); id != "" {
+		return "parent:" + id + "|" + month
 	}
-
-	// Очистка "хвоста" (и текущих данных) в редактируемых столбцах перед записью, чтобы сохранить только актуальные строки
-	rowLen := len(entityToSheetsTask(&tasks[0]))
-	lastColLetter := string(rune('A' + rowLen - 1))
-	clearRange := sheetName + "!A2:" + lastColLetter
-	if _, err := r.client.Svc().Spreadsheets.Values.Clear(sheetID, clearRange, &sheets.ClearValuesRequest{}).Do(); err != nil {
-		slog.Error("Error clearing old values", "error", err)
-		return err
-	}
-
-	// Обновляем значения (перезаписываем существующие ячейки, без удаления строк)
-	_, err = r.client.Svc().Spreadsheets.Values.Update(sheetID, writeRange, &vr).ValueInputOption("USER_ENTERED").Do()
-	if err != nil {
-		slog.Error("Error updating Google Sheets", "error", err)
-		return err
+	if len(row) > 2 && row[2] != "" && month != "" {
+		return "month:" + fmt.Sprint(row[2]) + "|" + month
 	}
 
-	return nil
+	return ""
 }
 
 // generateMonthStatusRows создает строки для каждой комбинации месяца и статуса
@@ -201,6 +132,7 @@ func generateMonthStatusRows(tasks []task.Task) [][]interface{} {
 			"",              // Экспертиза пустая
 			"",              // TotalHours пустое
 			"",              // TotalEstimate пустое
+			"",              // Estimate пустое
 		}
 
 		rows = append(rows, row)
@@ -242,6 +174,7 @@ func generateParentTaskRows(tasks []task.Task) [][]interface{} {
 					"", // Экспертиза пустая
 					"", // TotalHours пустое
 					"", // TotalEstimate пустое
+					"", // Estimate пустое
 				}
 
 				rows = append(rows, row)
@@ -352,5 +285,6 @@ func entityToSheetsTask(task *task.Task) []interface{} {
 		task.Expertise,
 		task.TotalHours,
 		task.TotalEstimate + task.SH,
+		task.Estimate,
 	}
 }
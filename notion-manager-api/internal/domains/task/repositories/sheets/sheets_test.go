@@ -391,8 +391,8 @@ func TestGenerateMonthStatusRowsStructure(t *testing.T) {
 	}
 
 	row := result[0]
-	if len(row) != 11 {
-		t.Fatalf("Expected row to have 11 columns, got %d", len(row))
+	if len(row) != 12 {
+		t.Fatalf("Expected row to have 12 columns, got %d", len(row))
 	}
 
 	// Проверяем структуру строки
@@ -408,6 +408,7 @@ func TestGenerateMonthStatusRowsStructure(t *testing.T) {
 		"",                              // Экспертиза пустая
 		"",                              // TotalHours пустое
 		"",                              // TotalEstimate пустое
+		"",                              // Estimate пустое
 	}
 
 	if !reflect.DeepEqual(row, expectedStructure) {
@@ -435,8 +436,8 @@ func TestGenerateParentTaskRowsStructure(t *testing.T) {
 	}
 
 	row := result[0]
-	if len(row) != 11 {
-		t.Fatalf("Expected row to have 11 columns, got %d", len(row))
+	if len(row) != 12 {
+		t.Fatalf("Expected row to have 12 columns, got %d", len(row))
 	}
 
 	// Проверяем основную структуру
@@ -574,4 +575,72 @@ func TestGenerateParentTaskRowsEdgeCases(t *testing.T) {
 			}
 		}
 	})
-}
\ No newline at end of file
+}
+
+func TestTaskRowKey(t *testing.T) {
+	parent := task.Task{
+		ID:         uuid.New(),
+		Task:       "Parent",
+		Status:     task.StatusInProgress,
+		ChildCount: 1,
+		Start:      time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		End:        time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+	}
+	child := task.Task{ID: uuid.New(), Task: "Child", ParentID: parent.ID, ParentName: parent.Task}
+	tasks := []task.Task{parent, child}
+
+	rows := [][]interface{}{entityToSheetsTask(&parent), entityToSheetsTask(&child)}
+	rows = append(rows, generateMonthStatusRows(tasks)...)
+	rows = append(rows, generateParentTaskRows(tasks)...)
+
+	keys := map[string]bool{}
+	for _, row := range rows {
+		key := taskRowKey(row)
+		if key == "" {
+			t.Fatalf("row %v has no key", row)
+		}
+		if keys[key] {
+			t.Fatalf("duplicate key %s", key)
+		}
+		keys[key] = true
+	}
+
+	if !keys["task:"+strings.ReplaceAll(child.ID.String(), "-", "")] {
+		t.Errorf("child task is keyed by its own link, got keys %v", keys)
+	}
+	if !keys["parent:"+strings.ReplaceAll(parent.ID.String(), "-", "")+"|01/02/2024"] {
+		t.Errorf("parent month row key is missing, got keys %v", keys)
+	}
+	if !keys["month:"+string(task.StatusInProgress)+"|01/01/2024"] {
+		t.Errorf("status month row key is missing, got keys %v", keys)
+	}
+}
+
+// TestTaskRowsMatchHeader проверяет, что каждая строка листа задач заполняет ровно столбцы заголовка:
+// по числу столбцов заголовка выбирается диапазон записи и заголовок файловых выгрузок
+func TestTaskRowsMatchHeader(t *testing.T) {
+	parent := task.Task{
+		ID:         uuid.New(),
+		Task:       "Parent",
+		Status:     task.StatusInProgress,
+		ChildCount: 1,
+		Start:      time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+	tasks := []task.Task{parent, {ID: uuid.New(), Task: "Child", ParentID: parent.ID}}
+
+	rows := [][]interface{}{entityToSheetsTask(&tasks[0]), entityToSheetsTask(&tasks[1])}
+	rows = append(rows, generateMonthStatusRows(tasks)...)
+	rows = append(rows, generateParentTaskRows(tasks)...)
+
+	for _, row := range rows {
+		if len(row) != len(taskHeader) {
+			t.Fatalf("row has %d columns, header has %d: %v", len(row), len(taskHeader), row)
+		}
+	}
+
+	for field, col := range taskEditableColumns {
+		if col >= len(taskHeader) || taskHeader[col] != editableFieldNames[field] {
+			t.Errorf("editable field %s is in column %d, header there is not %q", field, col, editableFieldNames[field])
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package tg
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func (r *TaskTelegramRepository) SendDeadlineReminders(ctx context.Context, chatID int64, tasks []task.DeadlineTask, overdue bool, now time.Time, loc *time.Location) error {
+	var sb strings.Builder
+	if overdue {
+		sb.WriteString("<b>Просроченные задачи:</b>\n")
+	} else {
+		sb.WriteString("<b>Скоро дедлайн:</b>\n")
+	}
+
+	for i, t := range tasks {
+		fmt.Fprintf(&sb, "%d. <a href=\"https://notion.so/%s\">%s</a>",
+			i+1,
+			strings.ReplaceAll(t.ID.String(), "-", ""),
+			html.EscapeString(t.Task.Task),
+		)
+		if t.ProjectName != "" {
+			fmt.Fprintf(&sb, " (%s)", html.EscapeString(t.ProjectName))
+		}
+
+		daysLeft := t.DaysLeft(now, loc)
+		switch {
+		case overdue:
+			fmt.Fprintf(&sb, " — срок был %s", t.End.In(loc).Format("02.01.2006"))
+			if t.Executor != "" {
+				fmt.Fprintf(&sb, ", исполнитель %s", html.EscapeString(t.Executor))
+			}
+		case daysLeft == 1:
+			sb.WriteString(" — срок завтра")
+		default:
+			fmt.Fprintf(&sb, " — срок %s, осталось %d дн.", t.End.In(loc).Format("02.01.2006"), daysLeft)
+		}
+		sb.WriteString("\n")
+	}
+
+	if _, err := r.GetBot().SendMessage(chatID, sb.String(), &gotgbot.SendMessageOpts{
+		ParseMode: gotgbot.ParseModeHTML,
+		LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
+			IsDisabled: true,
+		},
+	}); err != nil {
+		slog.Error("Error sending deadline reminders", "error", err, "chat_id", chatID)
+		return err
+	}
+
+	return nil
+}
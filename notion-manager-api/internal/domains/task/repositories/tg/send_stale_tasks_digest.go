@@ -0,0 +1,108 @@
+package tg
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/google/uuid"
+)
+
+// Ограничения Telegram на одно сообщение: длина текста и число кнопок.
+// Длина считается вместе с HTML-разметкой, поэтому оценка с запасом
+const (
+	maxMessageLength   = 4096
+	maxKeyboardButtons = 100
+)
+
+const staleDigestHeader = "<b>Задачи, которые давно не двигаются:</b>\n"
+
+type staleDigestPart struct {
+	text     string
+	keyboard [][]gotgbot.InlineKeyboardButton
+}
+
+func (r *TaskTelegramRepository) SendStaleTasksDigest(ctx context.Context, chatID int64, tasks []task.StaleTask, snoozeLinks map[uuid.UUID][]task.SnoozeLink) error {
+	for _, part := range buildStaleDigest(tasks, snoozeLinks) {
+		if _, err := r.GetBot().SendMessage(chatID, part.text, &gotgbot.SendMessageOpts{
+			ParseMode: gotgbot.ParseModeHTML,
+			LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
+				IsDisabled: true,
+			},
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+				InlineKeyboard: part.keyboard,
+			},
+		}); err != nil {
+			slog.Error("Error sending stale tasks digest", "error", err, "chat_id", chatID)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildStaleDigest разбивает дайджест на сообщения, каждое из которых укладывается
+// в ограничения Telegram. Нумерация задач сквозная, чтобы кнопки совпадали со списком
+func buildStaleDigest(tasks []task.StaleTask, snoozeLinks map[uuid.UUID][]task.SnoozeLink) []staleDigestPart {
+	parts := []staleDigestPart{}
+
+	var sb strings.Builder
+	sb.WriteString(staleDigestHeader)
+	keyboard := [][]gotgbot.InlineKeyboardButton{}
+	buttons := 0
+	empty := true
+
+	for i, t := range tasks {
+		var line strings.Builder
+		fmt.Fprintf(&line, "%d. <a href=\"https://notion.so/%s\">%s</a> — %s %s",
+			i+1,
+			strings.ReplaceAll(t.ID.String(), "-", ""),
+			html.EscapeString(t.Task.Task),
+			html.EscapeString(string(t.Status)),
+			formatStaleFor(time.Since(t.StatusSince)),
+		)
+		if t.ProjectName != "" {
+			fmt.Fprintf(&line, " (%s)", html.EscapeString(t.ProjectName))
+		}
+		line.WriteString("\n")
+
+		row := []gotgbot.InlineKeyboardButton{}
+		for _, link := range snoozeLinks[t.ID] {
+			row = append(row, gotgbot.InlineKeyboardButton{
+				Text: fmt.Sprintf("%d: %s", i+1, link.Text),
+				Url:  link.URL,
+			})
+		}
+
+		if !empty && (utf8.RuneCountInString(sb.String())+utf8.RuneCountInString(line.String()) > maxMessageLength ||
+			buttons+len(row) > maxKeyboardButtons) {
+			parts = append(parts, staleDigestPart{text: sb.String(), keyboard: keyboard})
+			sb.Reset()
+			keyboard = [][]gotgbot.InlineKeyboardButton{}
+			buttons = 0
+		}
+
+		sb.WriteString(line.String())
+		if len(row) > 0 {
+			keyboard = append(keyboard, row)
+			buttons += len(row)
+		}
+		empty = false
+	}
+
+	return append(parts, staleDigestPart{text: sb.String(), keyboard: keyboard})
+}
+
+func formatStaleFor(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	if days < 1 {
+		return fmt.Sprintf("%d ч.", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d дн.", days)
+}
@@ -0,0 +1,56 @@
+package tg
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+)
+
+func TestBuildStaleDigestSplitsByTelegramLimits(t *testing.T) {
+	tasks := []task.StaleTask{}
+	links := map[uuid.UUID][]task.SnoozeLink{}
+	for i := 0; i < 80; i++ {
+		st := task.StaleTask{}
+		st.ID = uuid.New()
+		st.Task.Task = strings.Repeat("задача ", 10)
+		st.Status = "В работе"
+		st.StatusSince = time.Now().Add(-72 * time.Hour)
+		tasks = append(tasks, st)
+		links[st.ID] = []task.SnoozeLink{
+			{Text: "+1 дн.", URL: "https://example.com/1"},
+			{Text: "+3 дн.", URL: "https://example.com/3"},
+			{Text: "+7 дн.", URL: "https://example.com/7"},
+		}
+	}
+
+	parts := buildStaleDigest(tasks, links)
+	if len(parts) < 3 {
+		t.Fatalf("len(parts) = %d, want at least 3", len(parts))
+	}
+
+	lines := 0
+	for i, part := range parts {
+		if n := utf8.RuneCountInString(part.text); n > maxMessageLength {
+			t.Fatalf("part %d length = %d, want <= %d", i, n, maxMessageLength)
+		}
+		buttons := 0
+		for _, row := range part.keyboard {
+			buttons += len(row)
+		}
+		if buttons > maxKeyboardButtons {
+			t.Fatalf("part %d buttons = %d, want <= %d", i, buttons, maxKeyboardButtons)
+		}
+		lines += strings.Count(part.text, "notion.so")
+	}
+	if lines != len(tasks) {
+		t.Fatalf("tasks in digest = %d, want %d", lines, len(tasks))
+	}
+	if !strings.Contains(parts[len(parts)-1].text, fmt.Sprintf("%d. ", len(tasks))) {
+		t.Fatalf("last part does not continue numbering: %q", parts[len(parts)-1].text)
+	}
+}
@@ -0,0 +1,24 @@
+package tg
+
+import (
+	"context"
+	"html"
+	"log/slog"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func (r *TaskTelegramRepository) SendWIPViolation(ctx context.Context, chatID int64, violation task.WIPViolation) error {
+	text := "<b>Превышен лимит задач в работе</b>\n" + html.EscapeString(violation.Msg()) +
+		"\nЛучше довести текущие задачи до конца, прежде чем брать новые."
+
+	if _, err := r.GetBot().SendMessage(chatID, text, &gotgbot.SendMessageOpts{
+		ParseMode: gotgbot.ParseModeHTML,
+	}); err != nil {
+		slog.Error("Error sending wip violation", "error", err, "chat_id", chatID)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package tg
+
+import (
+	"github.com/Corray333/employee_dashboard/internal/telegram"
+)
+
+type TaskTelegramRepository struct {
+	*telegram.TelegramClient
+}
+
+func NewTaskTelegramRepository(client *telegram.TelegramClient) *TaskTelegramRepository {
+	return &TaskTelegramRepository{client}
+}
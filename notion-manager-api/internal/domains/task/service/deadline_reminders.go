@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type tasksForReminderLister interface {
+	ListTasksForReminder(ctx context.Context, kind task.ReminderKind, endFrom, endTo, day time.Time) ([]task.DeadlineTask, error)
+}
+
+type reminderSentMarker interface {
+	MarkReminderSent(ctx context.Context, taskID uuid.UUID, kind task.ReminderKind, deadline, sentAt time.Time) error
+}
+
+type deadlineRemindersSender interface {
+	SendDeadlineReminders(ctx context.Context, chatID int64, tasks []task.DeadlineTask, overdue bool, now time.Time, loc *time.Location) error
+}
+
+// deadlineReminder - напоминание, которое нужно отправить получателю
+type deadlineReminder struct {
+	task task.DeadlineTask
+	kind task.ReminderKind
+}
+
+func (s *TaskService) StartDeadlineRemindersWorker(ctx context.Context) {
+	interval := viper.GetDuration("deadline_reminders.check_interval")
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.sendDeadlineReminders(ctx); err != nil {
+			slog.Error("Error sending deadline reminders", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *TaskService) sendDeadlineReminders(ctx context.Context) error {
+	loc, err := time.LoadLocation(viper.GetString("deadline_reminders.timezone"))
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	// В выходные и праздники не напоминаем: дни до дедлайна считаются рабочими,
+	// поэтому в последний рабочий день перед выходными напомним и о дедлайнах на выходных
+	cal := s.calendars.Default()
+	if !cal.IsWorkingDay(today) {
+		return nil
+	}
+
+	// Сначала самые близкие напоминания: если задаче уже пора напомнить "за день",
+	// более ранние напоминания ("за 3 дня") не отправляются, а только отмечаются
+	daysBefore := viper.GetIntSlice("deadline_reminders.days_before")
+	slices.Sort(daysBefore)
+
+	executorReminders := map[int64][]deadlineReminder{}
+	skipped := []deadlineReminder{}
+	reminded := map[uuid.UUID]bool{}
+	for _, days := range daysBefore {
+		if days <= 0 {
+			continue
+		}
+		kind := task.ReminderBefore(days)
+
+		tasks, err := s.tasksForReminderLister.ListTasksForReminder(ctx, kind, today.AddDate(0, 0, 1), cal.AddWorkingDays(today, days).AddDate(0, 0, 1), today)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range tasks {
+			// Отсутствующему исполнителю напомним, когда он вернется
+			if t.ExecutorTgID == 0 || t.ExecutorAbsent {
+				continue
+			}
+			if reminded[t.ID] {
+				skipped = append(skipped, deadlineReminder{task: t, kind: kind})
+				continue
+			}
+			reminded[t.ID] = true
+			executorReminders[t.ExecutorTgID] = append(executorReminders[t.ExecutorTgID], deadlineReminder{task: t, kind: kind})
+		}
+	}
+
+	// Старые просрочки не присылаем, чтобы не завалить менеджеров при первом запуске
+	overdueFrom := time.Time{}.AddDate(0, 0, 1)
+	if lookback := viper.GetDuration("deadline_reminders.overdue_lookback"); lookback > 0 {
+		overdueFrom = today.Add(-lookback)
+	}
+	overdue, err := s.tasksForReminderLister.ListTasksForReminder(ctx, task.ReminderOverdue, overdueFrom, today, today)
+	if err != nil {
+		return err
+	}
+	managerReminders := map[int64][]deadlineReminder{}
+	for _, t := range overdue {
+		if t.ManagerTgID == 0 || t.ManagerAbsent {
+			continue
+		}
+		managerReminders[t.ManagerTgID] = append(managerReminders[t.ManagerTgID], deadlineReminder{task: t, kind: task.ReminderOverdue})
+	}
+
+	s.deliverDeadlineReminders(ctx, executorReminders, false, now, loc)
+	s.deliverDeadlineReminders(ctx, managerReminders, true, now, loc)
+
+	for _, r := range skipped {
+		if err := s.reminderSentMarker.MarkReminderSent(ctx, r.task.ID, r.kind, r.task.End, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *TaskService) deliverDeadlineReminders(ctx context.Context, reminders map[int64][]deadlineReminder, overdue bool, now time.Time, loc *time.Location) {
+	for chatID, chatReminders := range reminders {
+		tasks := make([]task.DeadlineTask, 0, len(chatReminders))
+		for _, r := range chatReminders {
+			tasks = append(tasks, r.task)
+		}
+
+		if err := s.deadlineRemindersSender.SendDeadlineReminders(ctx, chatID, tasks, overdue, now, loc); err != nil {
+			slog.Error("Error sending deadline reminders", "error", err, "chat_id", chatID)
+			continue
+		}
+
+		for _, r := range chatReminders {
+			if err := s.reminderSentMarker.MarkReminderSent(ctx, r.task.ID, r.kind, r.task.End, now); err != nil {
+				slog.Error("Error marking deadline reminder sent", "error", err, "task_id", r.task.ID)
+			}
+		}
+	}
+}
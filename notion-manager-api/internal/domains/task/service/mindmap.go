@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/Corray333/employee_dashboard/pkg/mindmap"
+	"github.com/google/uuid"
+)
+
+// ExportProjectMindmap выгружает иерархию задач проекта в markdown-майндмапу,
+// которую можно отредактировать и загрузить обратно через /api/mindmap
+func (s *TaskService) ExportProjectMindmap(ctx context.Context, projectID uuid.UUID) (string, error) {
+	projects, err := s.projectsLister.ListProjects(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	projectName := ""
+	for _, p := range projects {
+		if p.ID == projectID {
+			projectName = p.Name
+			break
+		}
+	}
+	if projectName == "" {
+		return "", task.ErrProjectNotFound
+	}
+
+	tasks, err := s.taskLister.ListTasks(ctx, task.Filter{ProjectID: projectID}, 20000, 0)
+	if err != nil {
+		return "", err
+	}
+
+	return mindmap.RenderMarkdown(projectName, buildMindmapTree(tasks)), nil
+}
+
+func buildMindmapTree(tasks []task.Task) []mindmap.Task {
+	byID := make(map[uuid.UUID]*task.Task, len(tasks))
+	for i := range tasks {
+		if tasks[i].Status == task.StatusCancelled {
+			continue
+		}
+		byID[tasks[i].ID] = &tasks[i]
+	}
+
+	children := map[uuid.UUID][]*task.Task{}
+	for _, t := range byID {
+		parentID := t.ParentID
+		if _, ok := byID[parentID]; !ok {
+			parentID = uuid.Nil
+		}
+		children[parentID] = append(children[parentID], t)
+	}
+
+	var build func(parentID uuid.UUID) []mindmap.Task
+	build = func(parentID uuid.UUID) []mindmap.Task {
+		nodes := children[parentID]
+		sort.Slice(nodes, func(i, j int) bool {
+			if !nodes[i].CreatedTime.Equal(nodes[j].CreatedTime) {
+				return nodes[i].CreatedTime.Before(nodes[j].CreatedTime)
+			}
+			return nodes[i].Task < nodes[j].Task
+		})
+
+		result := make([]mindmap.Task, 0, len(nodes))
+		for _, t := range nodes {
+			node := mindmap.Task{
+				Title:    t.Task,
+				Link:     mindmap.PageLink(t.ID.String()),
+				Subtasks: build(t.ID),
+			}
+
+			// В майндмапе часы родителя сворачиваются из подзадач,
+			// поэтому у родителя записывается только превышение его оценки над ними
+			subtasksHours := 0.0
+			for _, st := range children[t.ID] {
+				subtasksHours += st.Estimate
+			}
+			if own := t.Estimate - subtasksHours; own > 0 {
+				node.Hours = own
+			}
+
+			result = append(result, node)
+		}
+		return result
+	}
+
+	return build(uuid.Nil)
+}
@@ -5,7 +5,9 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/Corray333/employee_dashboard/internal/calendar"
 	entity_task "github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/Corray333/employee_dashboard/internal/postgres"
 	"github.com/google/uuid"
 )
 
@@ -23,11 +25,50 @@ type TaskService struct {
 	taskLister         taskLister
 	sheetsTasksUpdater sheetsTasksUpdater
 	taskDeleter        taskDeleter
+	taskGetter         taskGetter
 
-	projectsLister projectsLister
+	projectsLister  projectsLister
+	changesRecorder changesRecorder
+	calendars       *calendar.Calendars
+
+	staleTasksLister         staleTasksLister
+	staleTasksNotifiedMarker staleTasksNotifiedMarker
+	staleTaskSnoozer         staleTaskSnoozer
+	staleTasksDigestSender   staleTasksDigestSender
+
+	tasksForReminderLister  tasksForReminderLister
+	reminderSentMarker      reminderSentMarker
+	deadlineRemindersSender deadlineRemindersSender
+
+	wipStatsLister      wipStatsLister
+	wipViolationsLister wipViolationsLister
+	wipViolationsSetter wipViolationsSetter
+	wipViolationSender  wipViolationSender
+
+	transactioner               postgres.Transactioner
+	templateCreator             templateCreator
+	templatesLister             templatesLister
+	projectTaskLinker           projectTaskLinker
+	taskOutboxParentResolver    taskOutboxParentResolver
+	taskOutboxCreatedTaskSetter taskOutboxCreatedTaskSetter
+	taskOutboxMsgFailer         taskOutboxMsgFailer
+
+	taskSheetCellsGetter       taskSheetCellsGetter
+	taskSheetCellsSetter       taskSheetCellsSetter
+	sheetEditCreator           sheetEditCreator
+	sheetEditsLister           sheetEditsLister
+	sheetEditDeleter           sheetEditDeleter
+	sheetEditConflictMarker    sheetEditConflictMarker
+	sheetEditDelayer           sheetEditDelayer
+	sheetEditFailer            sheetEditFailer
+	notionTaskGetter           notionTaskGetter
+	notionTaskFieldUpdater     notionTaskFieldUpdater
+	sheetsTaskCellsReader      sheetsTaskCellsReader
+	sheetsTaskConflictsUpdater sheetsTaskConflictsUpdater
 }
 
 type postgresRepository interface {
+	postgres.Transactioner
 	taskOutboxMsgGetter
 	taskOutboxMsgDeleter
 	taskMsgCreator
@@ -36,21 +77,56 @@ type postgresRepository interface {
 	tasksLastUpdateSetter
 	taskLister
 	taskDeleter
+	taskGetter
+	staleTasksLister
+	staleTasksNotifiedMarker
+	staleTaskSnoozer
+	tasksForReminderLister
+	reminderSentMarker
+	wipStatsLister
+	wipViolationsLister
+	wipViolationsSetter
+	templateCreator
+	templatesLister
+	projectTaskLinker
+	taskOutboxParentResolver
+	taskOutboxCreatedTaskSetter
+	taskOutboxMsgFailer
+	taskSheetCellsGetter
+	taskSheetCellsSetter
+	sheetEditCreator
+	sheetEditsLister
+	sheetEditDeleter
+	sheetEditConflictMarker
+	sheetEditDelayer
+	sheetEditFailer
 }
 
 type notionRepository interface {
 	notionTaskCreator
 	notionTaskLister
+	notionTaskGetter
+	notionTaskFieldUpdater
 }
 
 type sheetsRepository interface {
 	sheetsTasksUpdater
+	sheetsTaskCellsReader
+	sheetsTaskConflictsUpdater
+}
+
+type telegramRepository interface {
+	staleTasksDigestSender
+	deadlineRemindersSender
+	wipViolationSender
 }
 
 type option func(*TaskService)
 
 func NewTaskService(opts ...option) *TaskService {
-	service := &TaskService{}
+	service := &TaskService{
+		calendars: calendar.New(nil, nil, ""),
+	}
 
 	for _, opt := range opts {
 		opt(service)
@@ -61,10 +137,6 @@ func NewTaskService(opts ...option) *TaskService {
 	return service
 }
 
-func (s *TaskService) AcceptUpdate(ctx context.Context) {
-	go s.updateSheets(ctx)
-}
-
 func WithPostgresRepository(repository postgresRepository) option {
 	return func(s *TaskService) {
 		s.taskOutboxMsgGetter = repository
@@ -75,6 +147,30 @@ func WithPostgresRepository(repository postgresRepository) option {
 		s.tasksLastUpdateSetter = repository
 		s.taskLister = repository
 		s.taskDeleter = repository
+		s.taskGetter = repository
+		s.staleTasksLister = repository
+		s.staleTasksNotifiedMarker = repository
+		s.staleTaskSnoozer = repository
+		s.tasksForReminderLister = repository
+		s.reminderSentMarker = repository
+		s.wipStatsLister = repository
+		s.wipViolationsLister = repository
+		s.wipViolationsSetter = repository
+		s.transactioner = repository
+		s.templateCreator = repository
+		s.templatesLister = repository
+		s.projectTaskLinker = repository
+		s.taskOutboxParentResolver = repository
+		s.taskOutboxCreatedTaskSetter = repository
+		s.taskOutboxMsgFailer = repository
+		s.taskSheetCellsGetter = repository
+		s.taskSheetCellsSetter = repository
+		s.sheetEditCreator = repository
+		s.sheetEditsLister = repository
+		s.sheetEditDeleter = repository
+		s.sheetEditConflictMarker = repository
+		s.sheetEditDelayer = repository
+		s.sheetEditFailer = repository
 	}
 }
 
@@ -82,22 +178,37 @@ func WithNotionRepository(repository notionRepository) option {
 	return func(s *TaskService) {
 		s.notionTaskCreator = repository
 		s.notionTaskLister = repository
+		s.notionTaskGetter = repository
+		s.notionTaskFieldUpdater = repository
 	}
 }
 
 func WithSheetsRepository(repository sheetsRepository) option {
 	return func(s *TaskService) {
 		s.sheetsTasksUpdater = repository
+		s.sheetsTaskCellsReader = repository
+		s.sheetsTaskConflictsUpdater = repository
+	}
+}
+
+func WithTelegramRepository(repository telegramRepository) option {
+	return func(s *TaskService) {
+		s.staleTasksDigestSender = repository
+		s.deadlineRemindersSender = repository
+		s.wipViolationSender = repository
 	}
 }
 
 func (s *TaskService) Run() {
 	go s.TaskSync(context.Background())
 	go s.StartTaskOutboxWorker(context.Background())
+	go s.StartStaleTasksWorker(context.Background())
+	go s.StartDeadlineRemindersWorker(context.Background())
+	go s.StartSheetEditsWorker(context.Background())
 }
 
 type taskOutboxMsgGetter interface {
-	GetTaskOutboxMsgs(ctx context.Context) ([]entity_task.TaskOutboxMsg, error)
+	GetTaskOutboxMsgs(ctx context.Context, maxAttempts int) ([]entity_task.TaskOutboxMsg, error)
 }
 
 type notionTaskCreator interface {
@@ -112,22 +223,52 @@ type taskDeleter interface {
 	DeleteTask(ctx context.Context, taskID uuid.UUID) error
 }
 
+// taskOutboxMaxAttempts - сколько раз сообщение outbox пробуют обработать, прежде чем оставить с ошибкой
+const taskOutboxMaxAttempts = 10
+
+// processTaskMsgs обрабатывает сообщения outbox. Ошибка одного сообщения не останавливает
+// остальные: попытка засчитывается, и сообщение повторяется на следующем проходе
 func (s *TaskService) processTaskMsgs(ctx context.Context) error {
-	taskMsgs, err := s.taskOutboxMsgGetter.GetTaskOutboxMsgs(ctx)
+	taskMsgs, err := s.taskOutboxMsgGetter.GetTaskOutboxMsgs(ctx, taskOutboxMaxAttempts)
 	if err != nil {
 		return err
 	}
 
 	for _, task := range taskMsgs {
-		if err := s.notionTaskCreator.CreateTask(ctx, task.ToEntity()); err != nil {
+		if err := s.processTaskMsg(ctx, &task); err != nil {
+			slog.Error("Error processing task message", "error", err, "msg_id", task.ID)
+			if err := s.taskOutboxMsgFailer.FailTaskOutboxMsg(ctx, task.ID, err.Error()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *TaskService) processTaskMsg(ctx context.Context, task *entity_task.TaskOutboxMsg) error {
+	created := task.ToEntity()
+	if task.CreatedTaskID != uuid.Nil {
+		// Задача уже создана при прошлой попытке, повторяются только следующие шаги
+		created.ID = task.CreatedTaskID
+	} else {
+		if err := s.notionTaskCreator.CreateTask(ctx, created); err != nil {
 			return err
 		}
-		if err := s.taskOutboxMsgDeleter.DeleteTaskOutboxMsg(ctx, &task); err != nil {
+		if err := s.taskOutboxCreatedTaskSetter.SetTaskOutboxCreatedTask(ctx, task.ID, created.ID); err != nil {
 			return err
 		}
 	}
+	if task.Role != entity_task.TemplateRoleNone {
+		if err := s.projectTaskLinker.LinkProjectTask(ctx, task.ProjectID, task.Role, created.ID); err != nil {
+			return err
+		}
+	}
+	if err := s.taskOutboxParentResolver.ResolveTaskOutboxParent(ctx, task.ID, created.ID); err != nil {
+		return err
+	}
 
-	return nil
+	return s.taskOutboxMsgDeleter.DeleteTaskOutboxMsg(ctx, task)
 }
 
 func WithProjectRepository(repository projectsLister) option {
@@ -136,6 +277,18 @@ func WithProjectRepository(repository projectsLister) option {
 	}
 }
 
+func WithChangesRecorder(recorder changesRecorder) option {
+	return func(s *TaskService) {
+		s.changesRecorder = recorder
+	}
+}
+
+func WithCalendars(calendars *calendar.Calendars) option {
+	return func(s *TaskService) {
+		s.calendars = calendars
+	}
+}
+
 func (s *TaskService) StartTaskOutboxWorker(ctx context.Context) {
 	ticker := time.NewTicker(20 * time.Second)
 	defer ticker.Stop()
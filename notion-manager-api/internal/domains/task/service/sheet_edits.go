@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type taskSheetCellsGetter interface {
+	GetTaskSheetCells(ctx context.Context, spreadsheetID string) (map[uuid.UUID]map[task.EditableField]string, error)
+}
+
+type taskSheetCellsSetter interface {
+	SetTaskSheetCells(ctx context.Context, spreadsheetID string, cells map[uuid.UUID]map[task.EditableField]string) error
+}
+
+type sheetEditCreator interface {
+	CreateSheetEdit(ctx context.Context, edit *task.SheetEdit) error
+}
+
+type sheetEditsLister interface {
+	ListSheetEdits(ctx context.Context, filter task.SheetEditFilter) ([]task.SheetEdit, error)
+}
+
+type sheetEditDeleter interface {
+	DeleteSheetEdit(ctx context.Context, editID int64) error
+}
+
+type sheetEditConflictMarker interface {
+	MarkSheetEditConflict(ctx context.Context, editID int64, notionValue, reason string) error
+}
+
+type sheetEditDelayer interface {
+	DelaySheetEdit(ctx context.Context, editID int64, lastError string, nextAttemptAt time.Time) error
+}
+
+type sheetEditFailer interface {
+	MarkSheetEditFailed(ctx context.Context, editID int64, lastError string) error
+}
+
+type notionTaskGetter interface {
+	GetNotionTask(ctx context.Context, taskID uuid.UUID) (*task.Task, error)
+}
+
+type notionTaskFieldUpdater interface {
+	UpdateTaskField(ctx context.Context, taskID uuid.UUID, field task.EditableField, value string) error
+}
+
+type sheetsTaskCellsReader interface {
+	ReadSheetsTaskCells(ctx context.Context, sheetID string) (map[uuid.UUID]map[task.EditableField]interface{}, error)
+}
+
+type sheetsTaskConflictsUpdater interface {
+	UpdateSheetsTaskConflicts(ctx context.Context, sheetID string, edits []task.SheetEdit) error
+}
+
+// editableFields возвращает поля, правки которых переносятся из таблиц в Notion, или nil, если обратная синхронизация выключена
+func editableFields() []task.EditableField {
+	if !viper.GetBool("sheets.two_way.enabled") {
+		return nil
+	}
+
+	fields := []task.EditableField{}
+	for _, f := range viper.GetStringSlice("sheets.two_way.fields") {
+		fields = append(fields, task.EditableField(f))
+	}
+	return fields
+}
+
+// exportTasks выгружает задачи в таблицу sheetID. Перед выгрузкой из таблицы забираются правки,
+// иначе выгрузка их перезапишет; после - запоминаются выгруженные значения и обновляется отчет о конфликтах.
+func (s *TaskService) exportTasks(ctx context.Context, sheetID string, tasks []task.Task) error {
+	fields := editableFields()
+	if len(fields) == 0 {
+		return s.sheetsTasksUpdater.UpdateSheetsTasks(ctx, sheetID, tasks)
+	}
+
+	// Ожидающие отправки правки показываются в таблице сразу, не дожидаясь синхронизации с Notion
+	pending, err := s.sheetEditsLister.ListSheetEdits(ctx, task.SheetEditFilter{
+		SpreadsheetID: sheetID,
+		Statuses:      []task.SheetEditStatus{task.SheetEditStatusPending},
+	})
+	if err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]*task.Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+	for _, e := range pending {
+		if t, ok := byID[e.TaskID]; ok {
+			e.Field.Set(t, e.Value)
+		}
+	}
+
+	if err := s.collectSheetEdits(ctx, sheetID, byID, fields); err != nil {
+		return err
+	}
+
+	if err := s.sheetsTasksUpdater.UpdateSheetsTasks(ctx, sheetID, tasks); err != nil {
+		return err
+	}
+
+	cells := make(map[uuid.UUID]map[task.EditableField]string, len(tasks))
+	for i := range tasks {
+		taskCells := make(map[task.EditableField]string, len(fields))
+		for _, f := range fields {
+			taskCells[f] = f.Value(&tasks[i])
+		}
+		cells[tasks[i].ID] = taskCells
+	}
+	if err := s.taskSheetCellsSetter.SetTaskSheetCells(ctx, sheetID, cells); err != nil {
+		return err
+	}
+
+	if err := s.updateConflictsReport(ctx, sheetID); err != nil {
+		slog.Error("Error updating sheet conflicts report", "error", err, "sheet_id", sheetID)
+	}
+
+	return nil
+}
+
+// collectSheetEdits находит в таблице изменившиеся ячейки и ставит правки в очередь на отправку в Notion.
+// Принятая правка сразу применяется к задаче, чтобы выгрузка не откатила ее в таблице.
+func (s *TaskService) collectSheetEdits(ctx context.Context, sheetID string, tasks map[uuid.UUID]*task.Task, fields []task.EditableField) error {
+	sheetCells, err := s.sheetsTaskCellsReader.ReadSheetsTaskCells(ctx, sheetID)
+	if err != nil || sheetCells == nil {
+		return err
+	}
+
+	exported, err := s.taskSheetCellsGetter.GetTaskSheetCells(ctx, sheetID)
+	if err != nil {
+		return err
+	}
+
+	priorities := viper.GetStringSlice("sheets.two_way.priorities")
+	now := time.Now()
+	for taskID, taskCells := range sheetCells {
+		t, ok := tasks[taskID]
+		if !ok {
+			continue
+		}
+
+		for _, f := range fields {
+			raw, ok := taskCells[f]
+			if !ok {
+				continue
+			}
+			// Ячейки, которые еще не выгружались с запоминанием, сравнить не с чем
+			exportedValue, ok := exported[taskID][f]
+			if !ok {
+				continue
+			}
+
+			current := f.Value(t)
+			edit := &task.SheetEdit{
+				SpreadsheetID: sheetID,
+				TaskID:        taskID,
+				TaskName:      t.Task,
+				Field:         f,
+				BaseValue:     current,
+				CreatedAt:     now,
+			}
+
+			value, err := f.Normalize(raw, priorities)
+			if err != nil {
+				edit.Value = cellString(raw)
+				if edit.Value == exportedValue {
+					continue
+				}
+				edit.NotionValue = current
+				edit.Status = task.SheetEditStatusInvalid
+				edit.Reason = err.Error()
+				if err := s.sheetEditCreator.CreateSheetEdit(ctx, edit); err != nil {
+					return err
+				}
+				continue
+			}
+			edit.Value = value
+
+			switch task.ClassifySheetEdit(value, exportedValue, current) {
+			case task.SheetEditNone:
+				continue
+			case task.SheetEditConflict:
+				edit.NotionValue = current
+				edit.Status = task.SheetEditStatusConflict
+				edit.Reason = "Значение изменилось и в таблице, и в Notion"
+			case task.SheetEditChanged:
+				edit.Status = task.SheetEditStatusPending
+				f.Set(t, value)
+			}
+
+			if err := s.sheetEditCreator.CreateSheetEdit(ctx, edit); err != nil {
+				return err
+			}
+			slog.Info("Sheet edit detected", "sheet_id", sheetID, "task_id", taskID, "field", f, "status", edit.Status)
+		}
+	}
+
+	return nil
+}
+
+func cellString(raw interface{}) string {
+	switch v := raw.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (s *TaskService) updateConflictsReport(ctx context.Context, sheetID string) error {
+	edits, err := s.sheetEditsLister.ListSheetEdits(ctx, task.SheetEditFilter{
+		SpreadsheetID: sheetID,
+		Statuses:      []task.SheetEditStatus{task.SheetEditStatusConflict, task.SheetEditStatusInvalid, task.SheetEditStatusFailed},
+		Since:         time.Now().Add(-viper.GetDuration("sheets.two_way.conflicts_retention")),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.sheetsTaskConflictsUpdater.UpdateSheetsTaskConflicts(ctx, sheetID, edits)
+}
+
+func sheetEditsPushInterval() time.Duration {
+	interval := viper.GetDuration("sheets.two_way.push_interval")
+	if interval <= 0 {
+		interval = 20 * time.Second
+	}
+	return interval
+}
+
+func (s *TaskService) StartSheetEditsWorker(ctx context.Context) {
+	if len(editableFields()) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(sheetEditsPushInterval())
+	defer ticker.Stop()
+
+	for {
+		if err := s.pushSheetEdits(ctx); err != nil {
+			slog.Error("Error pushing sheet edits to notion", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pushSheetEdits отправляет ожидающие правки в Notion. Если страница задачи успела измениться
+// и значение в Notion разошлось и с исходным, и с новым, правка не отправляется, а становится конфликтом.
+// Неудачная отправка откладывается с растущей паузой, а после sheets.two_way.max_attempts попыток
+// правка получает статус failed, чтобы постоянно падающие правки не останавливали очередь.
+func (s *TaskService) pushSheetEdits(ctx context.Context) error {
+	edits, err := s.sheetEditsLister.ListSheetEdits(ctx, task.SheetEditFilter{
+		Statuses: []task.SheetEditStatus{task.SheetEditStatusPending},
+		Due:      time.Now(),
+		Limit:    50,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range edits {
+		current, err := s.notionTaskGetter.GetNotionTask(ctx, e.TaskID)
+		if err != nil {
+			slog.Error("Error getting task for sheet edit", "error", err, "edit_id", e.ID)
+			if err := s.failSheetEdit(ctx, e, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		notionValue := e.Field.Value(current)
+		if notionValue != e.BaseValue && notionValue != e.Value {
+			if err := s.sheetEditConflictMarker.MarkSheetEditConflict(ctx, e.ID, notionValue, "Задача изменена в Notion, пока правка ждала отправки"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if notionValue != e.Value {
+			if err := s.notionTaskFieldUpdater.UpdateTaskField(ctx, e.TaskID, e.Field, e.Value); err != nil {
+				slog.Error("Error pushing sheet edit", "error", err, "edit_id", e.ID)
+				if err := s.failSheetEdit(ctx, e, err); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if err := s.sheetEditDeleter.DeleteSheetEdit(ctx, e.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// failSheetEdit откладывает правку после неудачной отправки или, если попытки кончились, снимает ее с отправки
+func (s *TaskService) failSheetEdit(ctx context.Context, e task.SheetEdit, pushErr error) error {
+	maxAttempts := viper.GetInt("sheets.two_way.max_attempts")
+	if maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+	maxDelay := viper.GetDuration("sheets.two_way.max_retry_delay")
+	if maxDelay <= 0 {
+		maxDelay = time.Hour
+	}
+
+	attempts := e.Attempts + 1
+	if attempts >= maxAttempts {
+		slog.Warn("Sheet edit failed permanently", "edit_id", e.ID, "attempts", attempts, "error", pushErr)
+		return s.sheetEditFailer.MarkSheetEditFailed(ctx, e.ID, pushErr.Error())
+	}
+
+	delay := task.SheetEditRetryDelay(attempts, sheetEditsPushInterval(), maxDelay)
+	return s.sheetEditDelayer.DelaySheetEdit(ctx, e.ID, pushErr.Error(), time.Now().Add(delay))
+}
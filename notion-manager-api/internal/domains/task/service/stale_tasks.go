@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type staleTasksLister interface {
+	ListStaleTasks(ctx context.Context, status task.Status, statusBefore, notifiedBefore time.Time) ([]task.StaleTask, error)
+}
+
+type staleTasksNotifiedMarker interface {
+	MarkStaleTasksNotified(ctx context.Context, taskIDs []uuid.UUID, notifiedAt time.Time) error
+}
+
+type staleTaskSnoozer interface {
+	SnoozeStaleTask(ctx context.Context, taskID uuid.UUID, until time.Time) error
+}
+
+type staleTasksDigestSender interface {
+	SendStaleTasksDigest(ctx context.Context, chatID int64, tasks []task.StaleTask, snoozeLinks map[uuid.UUID][]task.SnoozeLink) error
+}
+
+func (s *TaskService) StartStaleTasksWorker(ctx context.Context) {
+	// Без отдельного секрета ссылки отложения подписать нечем, поэтому дайджесты не рассылаются
+	if os.Getenv("SNOOZE_SECRET") == "" {
+		slog.Error("Stale tasks worker is disabled", "error", task.ErrSnoozeSecretNotSet)
+		return
+	}
+
+	interval := viper.GetDuration("stale_tasks.check_interval")
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.notifyStaleTasks(ctx); err != nil {
+			slog.Error("Error notifying about stale tasks", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *TaskService) notifyStaleTasks(ctx context.Context) error {
+	thresholds := []task.StaleThreshold{}
+	if err := viper.UnmarshalKey("stale_tasks.thresholds", &thresholds); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	notifiedBefore := now.Add(-viper.GetDuration("stale_tasks.repeat_after"))
+
+	// Дайджест собирается по получателю: ответственный за задачу и менеджер проекта
+	digests := map[int64][]task.StaleTask{}
+	for _, threshold := range thresholds {
+		tasks, err := s.staleTasksLister.ListStaleTasks(ctx, threshold.Status, now.Add(-threshold.After), notifiedBefore)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range tasks {
+			if t.ResponsibleTgID != 0 {
+				digests[t.ResponsibleTgID] = append(digests[t.ResponsibleTgID], t)
+			}
+			if t.ManagerTgID != 0 && t.ManagerTgID != t.ResponsibleTgID {
+				digests[t.ManagerTgID] = append(digests[t.ManagerTgID], t)
+			}
+		}
+	}
+
+	// Задача считается напомненной, только если хотя бы один дайджест с ней дошел,
+	// иначе она попадет в следующую проверку
+	notified := map[uuid.UUID]struct{}{}
+	for chatID, tasks := range digests {
+		snoozeLinks := make(map[uuid.UUID][]task.SnoozeLink, len(tasks))
+		for _, t := range tasks {
+			links, err := s.snoozeLinks(t.ID, now)
+			if err != nil {
+				return err
+			}
+			snoozeLinks[t.ID] = links
+		}
+
+		if err := s.staleTasksDigestSender.SendStaleTasksDigest(ctx, chatID, tasks, snoozeLinks); err != nil {
+			slog.Error("Error sending stale tasks digest", "error", err, "chat_id", chatID)
+			continue
+		}
+		for _, t := range tasks {
+			notified[t.ID] = struct{}{}
+		}
+	}
+
+	if len(notified) == 0 {
+		return nil
+	}
+
+	taskIDs := make([]uuid.UUID, 0, len(notified))
+	for taskID := range notified {
+		taskIDs = append(taskIDs, taskID)
+	}
+
+	return s.staleTasksNotifiedMarker.MarkStaleTasksNotified(ctx, taskIDs, now)
+}
+
+func (s *TaskService) snoozeLinks(taskID uuid.UUID, now time.Time) ([]task.SnoozeLink, error) {
+	ttl := viper.GetDuration("stale_tasks.snooze_link_ttl")
+	if ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+	expires := now.Add(ttl).Unix()
+
+	links := []task.SnoozeLink{}
+	for _, option := range viper.GetStringSlice("stale_tasks.snooze_options") {
+		d, err := time.ParseDuration(option)
+		if err != nil {
+			slog.Error("Invalid snooze option", "option", option, "error", err)
+			continue
+		}
+
+		until := now.Add(d).Unix()
+		sign, err := snoozeSignature(taskID, until, expires)
+		if err != nil {
+			return nil, err
+		}
+
+		query := url.Values{}
+		query.Set("until", strconv.FormatInt(until, 10))
+		query.Set("expires", strconv.FormatInt(expires, 10))
+		query.Set("sign", sign)
+
+		links = append(links, task.SnoozeLink{
+			Text: fmt.Sprintf("+%d дн.", int(d.Hours()/24)),
+			URL:  fmt.Sprintf("%s/api/tasks/%s/snooze?%s", viper.GetString("server.public_url"), taskID, query.Encode()),
+		})
+	}
+
+	return links, nil
+}
+
+// VerifySnoozeLink проверяет подпись и срок действия ссылки из дайджеста
+func (s *TaskService) VerifySnoozeLink(taskID uuid.UUID, until, expires int64, sign string) error {
+	expected, err := snoozeSignature(taskID, until, expires)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(sign), []byte(expected)) {
+		return task.ErrInvalidSnoozeSignature
+	}
+	if time.Now().Unix() > expires {
+		return task.ErrSnoozeLinkExpired
+	}
+
+	return nil
+}
+
+// SnoozeStaleTask откладывает напоминание о задаче по ссылке из дайджеста
+func (s *TaskService) SnoozeStaleTask(ctx context.Context, taskID uuid.UUID, until, expires int64, sign string) (time.Time, error) {
+	if err := s.VerifySnoozeLink(taskID, until, expires, sign); err != nil {
+		return time.Time{}, err
+	}
+
+	untilTime := time.Unix(until, 0)
+	if err := s.staleTaskSnoozer.SnoozeStaleTask(ctx, taskID, untilTime); err != nil {
+		return time.Time{}, err
+	}
+
+	return untilTime, nil
+}
+
+func snoozeSignature(taskID uuid.UUID, until, expires int64) (string, error) {
+	secret := os.Getenv("SNOOZE_SECRET")
+	if secret == "" {
+		return "", task.ErrSnoozeSecretNotSet
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s:%d:%d", taskID, until, expires)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
 	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
 	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
 	"github.com/Corray333/employee_dashboard/internal/utils"
@@ -16,6 +17,14 @@ type taskSetter interface {
 	SetTask(ctx context.Context, task *task.Task) error
 }
 
+type taskGetter interface {
+	GetTask(ctx context.Context, taskID uuid.UUID) (*task.Task, error)
+}
+
+type changesRecorder interface {
+	RecordChanges(ctx context.Context, entity audit.Entity, entityID uuid.UUID, before, after map[string]string, editedAt time.Time, editedBy string) error
+}
+
 type notionTaskLister interface {
 	ListTasks(ctx context.Context, lastUpdate time.Time) ([]task.Task, error)
 }
@@ -68,7 +77,7 @@ func (s *TaskService) updateTasks(ctx context.Context) error {
 
 	lastTime := time.Time{}
 	for _, task := range times {
-		if err := s.taskSetter.SetTask(ctx, &task); err != nil {
+		if err := s.setTask(ctx, &task); err != nil {
 			return err
 		}
 		if task.LastEditedTime.After(lastTime) {
@@ -79,6 +88,36 @@ func (s *TaskService) updateTasks(ctx context.Context) error {
 		return err
 	}
 
+	if err := s.checkWIPLimits(ctx); err != nil {
+		slog.Error("Error checking wip limits", "error", err)
+	}
+
+	return nil
+}
+
+// setTask сохраняет задачу и записывает изменения ее полей в журнал
+func (s *TaskService) setTask(ctx context.Context, t *task.Task) error {
+	var before map[string]string
+	if s.changesRecorder != nil {
+		old, err := s.taskGetter.GetTask(ctx, t.ID)
+		if err != nil {
+			return err
+		}
+		if old != nil {
+			before = old.AuditFields()
+		}
+	}
+
+	if err := s.taskSetter.SetTask(ctx, t); err != nil {
+		return err
+	}
+
+	if s.changesRecorder != nil {
+		if err := s.changesRecorder.RecordChanges(ctx, audit.EntityTask, t.ID, before, t.AuditFields(), t.LastEditedTime, t.LastEditedBy); err != nil {
+			slog.Error("Error recording task changes", "error", err, "task_id", t.ID)
+		}
+	}
+
 	return nil
 }
 
@@ -120,7 +159,7 @@ func (s *TaskService) UpdateProjectSheets(ctx context.Context, projectID uuid.UU
 		return nil
 	}
 
-	if err := s.sheetsTasksUpdater.UpdateSheetsTasks(ctx, sheetID, tasks); err != nil {
+	if err := s.exportTasks(ctx, sheetID, tasks); err != nil {
 		return err
 	}
 
@@ -128,46 +167,19 @@ func (s *TaskService) UpdateProjectSheets(ctx context.Context, projectID uuid.UU
 
 }
 
-func (s *TaskService) updateSheets(ctx context.Context) {
+func (s *TaskService) UpdateSheets(ctx context.Context) error {
 
 	tasks, err := s.taskLister.ListTasks(ctx, task.Filter{}, 20000, 0)
 	if err != nil {
 		slog.Error("Error getting tasks", "error", err)
-		return
+		return err
 	}
 
-	if err := s.sheetsTasksUpdater.UpdateSheetsTasks(ctx, viper.GetString("sheets.id"), tasks); err != nil {
+	if err := s.exportTasks(ctx, viper.GetString("sheets.id"), tasks); err != nil {
 		slog.Error("Error updating sheets", "error", err)
-		return
-	}
-
-	// projects, err := s.projectsLister.ListProjects(ctx)
-	// if err != nil {
-	// 	slog.Error("Error getting projects", "error", err)
-	// 	return
-	// }
-
-	// for _, project := range projects {
-	// 	if project.SheetsLink == "" {
-	// 		continue
-	// 	}
-
-	// 	sheetID, err := utils.ExtractSpreadsheetID(project.SheetsLink)
-	// 	if err != nil {
-	// 		slog.Error("Error extracting spreadsheet ID", "error", err)
-	// 		continue
-	// 	}
-
-	// 	tasks, err := s.taskLister.ListTasks(ctx, task.Filter{ProjectID: project.ID}, 20000, 0)
-	// 	if err != nil {
-	// 		slog.Error("Error getting tasks", "error", err)
-	// 		return
-	// 	}
-
-	// 	if err := s.sheetsTasksUpdater.UpdateSheetsTasks(ctx, sheetID, tasks); err != nil {
-	// 		slog.Error("Error updating sheets", "error", err)
-	// 		return
-	// 	}
-
-	// }
+		return err
+	}
+	// Таблицы отдельных проектов обновляет ProjectService.UpdateAllProjectSheets
+
+	return nil
 }
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/google/uuid"
+)
+
+type templateCreator interface {
+	CreateTemplate(ctx context.Context, template *task.Template) error
+}
+
+type templatesLister interface {
+	ListTemplates(ctx context.Context) ([]task.Template, error)
+}
+
+type projectTaskLinker interface {
+	LinkProjectTask(ctx context.Context, projectID uuid.UUID, role task.TemplateRole, taskID uuid.UUID) error
+}
+
+type taskOutboxParentResolver interface {
+	ResolveTaskOutboxParent(ctx context.Context, msgID int64, taskID uuid.UUID) error
+}
+
+type taskOutboxCreatedTaskSetter interface {
+	SetTaskOutboxCreatedTask(ctx context.Context, msgID int64, taskID uuid.UUID) error
+}
+
+type taskOutboxMsgFailer interface {
+	FailTaskOutboxMsg(ctx context.Context, msgID int64, reason string) error
+}
+
+func (s *TaskService) CreateTemplate(ctx context.Context, template *task.Template) error {
+	if template.Name == "" {
+		return fmt.Errorf("%w: empty name", task.ErrInvalidTemplate)
+	}
+	if err := validateTemplateItems(template.Items, map[task.TemplateRole]bool{}); err != nil {
+		return err
+	}
+
+	return s.templateCreator.CreateTemplate(ctx, template)
+}
+
+func validateTemplateItems(items []task.TemplateItem, roles map[task.TemplateRole]bool) error {
+	for _, item := range items {
+		if item.Title == "" {
+			return fmt.Errorf("%w: empty item title", task.ErrInvalidTemplate)
+		}
+		if item.Role != task.TemplateRoleNone {
+			if roles[item.Role] {
+				return fmt.Errorf("%w: role %s is used more than once", task.ErrInvalidTemplate, item.Role)
+			}
+			roles[item.Role] = true
+		}
+		if item.Priority == "" {
+			return fmt.Errorf("%w: empty priority of %q", task.ErrInvalidTemplate, item.Title)
+		}
+		if item.DurationDays < 0 {
+			return fmt.Errorf("%w: negative duration of %q", task.ErrInvalidTemplate, item.Title)
+		}
+		if err := task.ValidateEstimate(item.Estimate); err != nil {
+			return fmt.Errorf("%w: %s: %s", task.ErrInvalidTemplate, item.Title, err.Error())
+		}
+		if err := validateTemplateItems(item.Subtasks, roles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TaskService) ListTemplates(ctx context.Context) ([]task.Template, error) {
+	return s.templatesLister.ListTemplates(ctx)
+}
+
+// InstantiateTemplate ставит задачи шаблона в outbox. Задачи создаются в Notion воркером
+// outbox: сначала родители, затем подзадачи, служебные задачи привязываются к проекту.
+func (s *TaskService) InstantiateTemplate(ctx context.Context, templateID int64, req *task.InstantiateTemplateRequest) ([]task.TaskOutboxMsg, error) {
+	if req.ExecutorID == uuid.Nil {
+		return nil, fmt.Errorf("%w: executor is required", task.ErrInvalidTemplate)
+	}
+
+	templates, err := s.templatesLister.ListTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var template *task.Template
+	for i := range templates {
+		if templates[i].ID == templateID {
+			template = &templates[i]
+			break
+		}
+	}
+	if template == nil {
+		return nil, task.ErrTemplateNotFound
+	}
+
+	projects, err := s.projectsLister.ListProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projectName := ""
+	for _, p := range projects {
+		if p.ID == req.ProjectID {
+			projectName = p.Name
+			break
+		}
+	}
+	if projectName == "" {
+		return nil, task.ErrProjectNotFound
+	}
+
+	tasks, err := s.taskLister.ListTasks(ctx, task.Filter{ProjectID: req.ProjectID}, 20000, 0)
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]float64{"hours": 0}
+	for _, t := range tasks {
+		vars["hours"] += t.Estimate
+	}
+
+	if req.Start.IsZero() {
+		now := time.Now()
+		req.Start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	}
+
+	ctx, err = s.transactioner.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.transactioner.Rollback(ctx)
+
+	msgs := []task.TaskOutboxMsg{}
+	var enqueue func(items []task.TemplateItem, parentMsgID int64) error
+	enqueue = func(items []task.TemplateItem, parentMsgID int64) error {
+		for _, item := range items {
+			estimate, err := task.EvalEstimate(item.Estimate, vars)
+			if err != nil {
+				return err
+			}
+			start, end := item.Deadline(req.Start)
+
+			msg := &task.TaskOutboxMsg{
+				ProjectID:   req.ProjectID,
+				ExecutorID:  req.Executor(item.Role),
+				Task:        item.RenderTitle(projectName),
+				Estimate:    estimate,
+				Priority:    item.Priority,
+				Start:       start,
+				End:         end,
+				ParentMsgID: parentMsgID,
+				Tags:        item.Tags,
+				Role:        item.Role,
+			}
+			if err := s.taskMsgCreator.CreateTaskOutboxMsg(ctx, msg); err != nil {
+				return err
+			}
+			msgs = append(msgs, *msg)
+
+			if err := enqueue(item.Subtasks, msg.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := enqueue(template.Items, 0); err != nil {
+		return nil, err
+	}
+
+	if err := s.transactioner.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return msgs, nil
+}
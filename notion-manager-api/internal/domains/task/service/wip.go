@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/spf13/viper"
+)
+
+type wipStatsLister interface {
+	ListWIPStats(ctx context.Context) ([]task.WIPStat, error)
+}
+
+type wipViolationsLister interface {
+	ListWIPViolations(ctx context.Context) (map[string]int, error)
+}
+
+type wipViolationsSetter interface {
+	SetWIPViolations(ctx context.Context, violations []task.WIPViolation, notifiedAt time.Time) error
+}
+
+type wipViolationSender interface {
+	SendWIPViolation(ctx context.Context, chatID int64, violation task.WIPViolation) error
+}
+
+func wipLimits() (task.WIPLimits, error) {
+	limits := task.WIPLimits{}
+	if err := viper.UnmarshalKey("wip_limits", &limits); err != nil {
+		return limits, err
+	}
+	return limits, nil
+}
+
+// GetWIPOverview возвращает задачи в работе по исполнителям с лимитами и нарушениями
+func (s *TaskService) GetWIPOverview(ctx context.Context) (*task.WIPOverview, error) {
+	limits, err := wipLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.wipStatsLister.ListWIPStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task.WIPOverview{
+		Employees:  stats,
+		Violations: task.ApplyWIPLimits(stats, limits),
+	}, nil
+}
+
+// checkWIPLimits оповещает о новых нарушениях и о росте числа задач у уже оповещенных
+func (s *TaskService) checkWIPLimits(ctx context.Context) error {
+	overview, err := s.GetWIPOverview(ctx)
+	if err != nil {
+		return err
+	}
+
+	notified, err := s.wipViolationsLister.ListWIPViolations(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Нарушение сохраняется с новым числом задач, только если оповещение хотя бы
+	// одному получателю дошло, иначе оно повторится на следующей проверке
+	persisted := make([]task.WIPViolation, 0, len(overview.Violations))
+	for _, v := range overview.Violations {
+		count, ok := notified[v.Scope]
+		if ok && count >= v.InProgress {
+			persisted = append(persisted, v)
+			continue
+		}
+
+		delivered := false
+		sent := map[int64]bool{}
+		for _, chatID := range append(append([]int64{}, v.TgIDs...), v.ManagerTgIDs...) {
+			if chatID == 0 || sent[chatID] {
+				continue
+			}
+			sent[chatID] = true
+			if err := s.wipViolationSender.SendWIPViolation(ctx, chatID, v); err != nil {
+				slog.Error("Error sending wip violation", "error", err, "scope", v.Scope)
+				continue
+			}
+			delivered = true
+		}
+
+		switch {
+		case delivered:
+			persisted = append(persisted, v)
+		case ok:
+			v.InProgress = count
+			persisted = append(persisted, v)
+		}
+	}
+
+	return s.wipViolationsSetter.SetWIPViolations(ctx, persisted, time.Now())
+}
@@ -1,14 +1,19 @@
 package task
 
 import (
+	"github.com/Corray333/employee_dashboard/internal/calendar"
+	audit_service "github.com/Corray333/employee_dashboard/internal/domains/audit/service"
 	project_repo "github.com/Corray333/employee_dashboard/internal/domains/project/service"
 	notion_repo "github.com/Corray333/employee_dashboard/internal/domains/task/repositories/notion"
 	postgres_repo "github.com/Corray333/employee_dashboard/internal/domains/task/repositories/postgres"
 	sheets_repo "github.com/Corray333/employee_dashboard/internal/domains/task/repositories/sheets"
+	tg_repo "github.com/Corray333/employee_dashboard/internal/domains/task/repositories/tg"
 	"github.com/Corray333/employee_dashboard/internal/domains/task/service"
 	"github.com/Corray333/employee_dashboard/internal/domains/task/transport"
+	"github.com/Corray333/employee_dashboard/internal/export"
 	"github.com/Corray333/employee_dashboard/internal/postgres"
 	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+	"github.com/Corray333/employee_dashboard/internal/telegram"
 	notion "github.com/Corray333/employee_dashboard/pkg/notion/v2"
 	"github.com/go-chi/chi/v5"
 )
@@ -20,13 +25,14 @@ type TaskController struct {
 	transport    *transport.TaskTransport
 }
 
-func NewTaskController(router *chi.Mux, store *postgres.PostgresClient, notionClient *notion.Client, sheetsClient *gsheets.Client, projectRepository *project_repo.ProjectService) *TaskController {
+func NewTaskController(router *chi.Mux, store *postgres.PostgresClient, calendars *calendar.Calendars, notionClient *notion.Client, sheetsClient *gsheets.Client, tgClient *telegram.TelegramClient, projectRepository *project_repo.ProjectService, auditService *audit_service.AuditService) *TaskController {
 
 	postgresRepo := postgres_repo.NewTaskPostgresRepository(store)
 	notionRepo := notion_repo.NewTaskNotionRepository(notionClient)
-	sheetsRepo := sheets_repo.NewTaskSheetsRepository(sheetsClient)
+	sheetsRepo := sheets_repo.NewTaskSheetsRepository(sheetsClient, export.NewSink("tasks", sheetsClient, tgClient))
+	tgRepo := tg_repo.NewTaskTelegramRepository(tgClient)
 
-	service := service.NewTaskService(service.WithPostgresRepository(postgresRepo), service.WithNotionRepository(notionRepo), service.WithSheetsRepository(sheetsRepo), service.WithProjectRepository(projectRepository))
+	service := service.NewTaskService(service.WithPostgresRepository(postgresRepo), service.WithNotionRepository(notionRepo), service.WithSheetsRepository(sheetsRepo), service.WithTelegramRepository(tgRepo), service.WithProjectRepository(projectRepository), service.WithChangesRecorder(auditService), service.WithCalendars(calendars))
 
 	transport := transport.NewTaskTransport(router, service)
 
@@ -3,16 +3,28 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	entity_task "github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
 	"github.com/Corray333/employee_dashboard/internal/transport"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type service interface {
 	CreateTask(ctx context.Context, task *entity_task.TaskOutboxMsg) error
+	VerifySnoozeLink(taskID uuid.UUID, until, expires int64, sign string) error
+	SnoozeStaleTask(ctx context.Context, taskID uuid.UUID, until, expires int64, sign string) (time.Time, error)
+	GetWIPOverview(ctx context.Context) (*entity_task.WIPOverview, error)
+	ExportProjectMindmap(ctx context.Context, projectID uuid.UUID) (string, error)
+	CreateTemplate(ctx context.Context, template *entity_task.Template) error
+	ListTemplates(ctx context.Context) ([]entity_task.Template, error)
+	InstantiateTemplate(ctx context.Context, templateID int64, req *entity_task.InstantiateTemplateRequest) ([]entity_task.TaskOutboxMsg, error)
 }
 type TaskTransport struct {
 	service service
@@ -33,7 +45,17 @@ func (t *TaskTransport) RegisterRoutes() {
 		r.Use(transport.NewTaskTrackerAuthMiddleware())
 
 		r.Post("/api/task", t.createTask)
+		r.Get("/api/tasks/wip", t.getWIPOverview)
+		r.Get("/api/tasks/mindmap", t.exportProjectMindmap)
+		r.Get("/api/tasks/templates", t.listTemplates)
+		r.Post("/api/tasks/templates", t.createTemplate)
+		r.Post("/api/tasks/templates/{templateID}/instantiate", t.instantiateTemplate)
 	})
+
+	// Ссылки из telegram-дайджеста подписаны, поэтому авторизация не нужна.
+	// GET только показывает подтверждение, чтобы превью ссылок в мессенджере не откладывали задачу
+	t.router.Get("/api/tasks/{taskID}/snooze", t.confirmSnoozeStaleTask)
+	t.router.Post("/api/tasks/{taskID}/snooze", t.snoozeStaleTask)
 }
 
 func (t *TaskTransport) createTask(w http.ResponseWriter, r *http.Request) {
@@ -53,3 +75,202 @@ func (t *TaskTransport) createTask(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 
 }
+
+type snoozeLink struct {
+	taskID  uuid.UUID
+	until   int64
+	expires int64
+	sign    string
+}
+
+func parseSnoozeLink(r *http.Request) (*snoozeLink, error) {
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		return nil, err
+	}
+
+	until, err := strconv.ParseInt(r.FormValue("until"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	expires, err := strconv.ParseInt(r.FormValue("expires"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snoozeLink{
+		taskID:  taskID,
+		until:   until,
+		expires: expires,
+		sign:    r.FormValue("sign"),
+	}, nil
+}
+
+func snoozeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity_task.ErrInvalidSnoozeSignature):
+		return http.StatusForbidden
+	case errors.Is(err, entity_task.ErrSnoozeLinkExpired):
+		return http.StatusGone
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (t *TaskTransport) confirmSnoozeStaleTask(w http.ResponseWriter, r *http.Request) {
+	link, err := parseSnoozeLink(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.VerifySnoozeLink(link.taskID, link.until, link.expires, link.sign); err != nil {
+		http.Error(w, err.Error(), snoozeErrorStatus(err))
+		return
+	}
+
+	// Форма отправляется на тот же адрес, параметры ссылки остаются в query
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Отложить напоминание</title></head>
+<body>
+<form method="POST">
+<p>Отложить напоминание о задаче до %s?</p>
+<button type="submit">Отложить</button>
+</form>
+</body>
+</html>
+`, time.Unix(link.until, 0).Format("02.01.2006 15:04"))
+}
+
+func (t *TaskTransport) snoozeStaleTask(w http.ResponseWriter, r *http.Request) {
+	link, err := parseSnoozeLink(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	untilTime, err := t.service.SnoozeStaleTask(r.Context(), link.taskID, link.until, link.expires, link.sign)
+	if err != nil {
+		status := snoozeErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			slog.Error("Error snoozing stale task", "error", err)
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "Напоминание о задаче отложено до %s", untilTime.Format("02.01.2006 15:04"))
+}
+
+func (t *TaskTransport) getWIPOverview(w http.ResponseWriter, r *http.Request) {
+	overview, err := t.service.GetWIPOverview(r.Context())
+	if err != nil {
+		slog.Error("Error getting wip overview", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(overview); err != nil {
+		slog.Error("Error encoding wip overview", "error", err)
+	}
+}
+
+func (t *TaskTransport) exportProjectMindmap(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(r.URL.Query().Get("project_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := t.service.ExportProjectMindmap(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, entity_task.ErrProjectNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		slog.Error("Error exporting project mindmap", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.md\"", projectID))
+	w.Write([]byte(data))
+}
+
+func (t *TaskTransport) listTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := t.service.ListTemplates(r.Context())
+	if err != nil {
+		slog.Error("Error listing task templates", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(templates); err != nil {
+		slog.Error("Error encoding task templates", "error", err)
+	}
+}
+
+func (t *TaskTransport) createTemplate(w http.ResponseWriter, r *http.Request) {
+	template := &entity_task.Template{}
+	if err := json.NewDecoder(r.Body).Decode(template); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.CreateTemplate(r.Context(), template); err != nil {
+		if errors.Is(err, entity_task.ErrInvalidTemplate) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error creating task template", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(template); err != nil {
+		slog.Error("Error encoding task template", "error", err)
+	}
+}
+
+func (t *TaskTransport) instantiateTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, err := strconv.ParseInt(chi.URLParam(r, "templateID"), 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := &entity_task.InstantiateTemplateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	msgs, err := t.service.InstantiateTemplate(r.Context(), templateID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity_task.ErrTemplateNotFound), errors.Is(err, entity_task.ErrProjectNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, entity_task.ErrInvalidTemplate):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			slog.Error("Error instantiating task template", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(msgs); err != nil {
+		slog.Error("Error encoding task outbox messages", "error", err)
+	}
+}
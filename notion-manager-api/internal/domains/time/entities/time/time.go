@@ -1,8 +1,10 @@
 package time
 
 import (
+	"strconv"
 	"time"
 
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
 	"github.com/google/uuid"
 )
 
@@ -40,6 +42,7 @@ type Time struct {
 	TargetTask    string    `json:"target_task"`
 	CR            bool      `json:"cr"`
 	LastUpdate    time.Time `json:"last_update"`
+	LastEditedBy  string    `json:"last_edited_by"`
 	CreatedAt     time.Time `json:"created"`
 
 	WhoDid       string  `json:"who_did"`
@@ -48,6 +51,21 @@ type Time struct {
 	TaskEstimate float64 `json:"task_estimate"`
 }
 
+// AuditFields возвращает поля записи времени, изменения которых пишутся в журнал
+func (t *Time) AuditFields() map[string]string {
+	return map[string]string{
+		"total_hours":  strconv.FormatFloat(t.TotalHours, 'f', -1, 64),
+		"task_id":      audit.FormatUUID(t.TaskID),
+		"employee_id":  audit.FormatUUID(t.EmployeeID),
+		"project_id":   audit.FormatUUID(t.ProjectID),
+		"work_date":    audit.FormatTime(t.WorkDate),
+		"what_did":     t.WhatDid,
+		"payment":      strconv.FormatBool(t.Payment),
+		"status_hours": t.StatusHours,
+		"overtime":     strconv.FormatBool(t.Overtime),
+	}
+}
+
 type TimeOutboxMsg struct {
 	ID          int64     `json:"id"`
 	TaskID      uuid.UUID `json:"taskID"`
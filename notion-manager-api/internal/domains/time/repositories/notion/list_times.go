@@ -56,7 +56,10 @@ type time struct {
 	ID             string `json:"id"`
 	CreatedTime    string `json:"created_time"`
 	LastEditedTime string `json:"last_edited_time"`
-	Properties     struct {
+	LastEditedBy   struct {
+		ID string `json:"id"`
+	} `json:"last_edited_by"`
+	Properties struct {
 		TotalHours struct {
 			Number float64 `json:"number"`
 		} `json:"Затрачено ч."`
@@ -343,9 +346,10 @@ func (t *time) ToEntity() *entity_time.Time {
 			}
 			return ""
 		}(),
-		TargetTask: t.Properties.TargetTask.Formula.String,
-		CR:         t.Properties.CR.Formula.Boolean,
-		LastUpdate: lastUpdate,
-		CreatedAt:  created,
+		TargetTask:   t.Properties.TargetTask.Formula.String,
+		CR:           t.Properties.CR.Formula.Boolean,
+		LastUpdate:   lastUpdate,
+		LastEditedBy: t.LastEditedBy.ID,
+		CreatedAt:    created,
 	}
 }
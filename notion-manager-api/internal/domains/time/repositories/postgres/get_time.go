@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	entity_time "github.com/Corray333/employee_dashboard/internal/domains/time/entities/time"
+	"github.com/google/uuid"
+)
+
+// GetTime возвращает запись времени в том виде, в котором она сохранена, или nil, если ее еще нет
+func (r *TimePostgresRepository) GetTime(ctx context.Context, timeID uuid.UUID) (*entity_time.Time, error) {
+	t := timeDB{}
+	err := r.DB().GetContext(ctx, &t, `SELECT * FROM times WHERE time_id = $1`, timeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		slog.Error("Error getting time", "error", err)
+		return nil, err
+	}
+
+	return t.ToEntity(), nil
+}
@@ -20,6 +20,8 @@ type TimeService struct {
 	timeWriteOfNotion        timeWriteOfNotion
 	projectsLister           projectsLister
 	timeDeleter              timeDeleter
+	timeGetter               timeGetter
+	changesRecorder          changesRecorder
 }
 
 type postgresRepository interface {
@@ -31,6 +33,7 @@ type postgresRepository interface {
 	timeOutboxMsgGetter
 	timeWriteOfSentMarker
 	timeDeleter
+	timeGetter
 }
 type notionRepository interface {
 	timeRawLister
@@ -53,10 +56,6 @@ func NewTimeService(opts ...option) *TimeService {
 	return service
 }
 
-func (s *TimeService) AcceptUpdate(ctx context.Context) {
-	go s.updateSheets(ctx)
-}
-
 func WithPostgresRepository(repository postgresRepository) option {
 	return func(s *TimeService) {
 		s.timeLastUpdateTimeGetter = repository
@@ -67,6 +66,7 @@ func WithPostgresRepository(repository postgresRepository) option {
 		s.timeOutboxMsgGetter = repository
 		s.timeWriteOfSentMarker = repository
 		s.timeDeleter = repository
+		s.timeGetter = repository
 	}
 }
 
@@ -89,6 +89,12 @@ func WithProjectRepository(repository projectsLister) option {
 	}
 }
 
+func WithChangesRecorder(recorder changesRecorder) option {
+	return func(s *TimeService) {
+		s.changesRecorder = recorder
+	}
+}
+
 func (s *TimeService) Run() {
 	go s.TimeSync(context.Background())
 	go s.StartWriteOfOutboxWorker(context.Background())
@@ -6,6 +6,7 @@ import (
 	"time"
 	pkg_time "time"
 
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
 	"github.com/Corray333/employee_dashboard/internal/domains/project/entities/project"
 	entity_time "github.com/Corray333/employee_dashboard/internal/domains/time/entities/time"
 	"github.com/Corray333/employee_dashboard/internal/utils"
@@ -29,6 +30,14 @@ type timeSetter interface {
 	SetTime(ctx context.Context, time *entity_time.Time) error
 }
 
+type timeGetter interface {
+	GetTime(ctx context.Context, timeID uuid.UUID) (*entity_time.Time, error)
+}
+
+type changesRecorder interface {
+	RecordChanges(ctx context.Context, entity audit.Entity, entityID uuid.UUID, before, after map[string]string, editedAt time.Time, editedBy string) error
+}
+
 type timesLister interface {
 	ListTimes(ctx context.Context, filter entity_time.TimeFilter, offset, limit int) ([]entity_time.Time, error)
 }
@@ -50,7 +59,7 @@ func (s *TimeService) updateTimes(ctx context.Context) error {
 
 	lastTime := time.Time{}
 	for _, time := range times {
-		if err := s.timeSetter.SetTime(ctx, &time); err != nil {
+		if err := s.setTime(ctx, &time); err != nil {
 			return err
 		}
 		if time.LastUpdate.After(lastTime) {
@@ -64,6 +73,32 @@ func (s *TimeService) updateTimes(ctx context.Context) error {
 	return nil
 }
 
+// setTime сохраняет запись времени и записывает изменения ее полей в журнал
+func (s *TimeService) setTime(ctx context.Context, t *entity_time.Time) error {
+	var before map[string]string
+	if s.changesRecorder != nil {
+		old, err := s.timeGetter.GetTime(ctx, t.ID)
+		if err != nil {
+			return err
+		}
+		if old != nil {
+			before = old.AuditFields()
+		}
+	}
+
+	if err := s.timeSetter.SetTime(ctx, t); err != nil {
+		return err
+	}
+
+	if s.changesRecorder != nil {
+		if err := s.changesRecorder.RecordChanges(ctx, audit.EntityTime, t.ID, before, t.AuditFields(), t.LastUpdate, t.LastEditedBy); err != nil {
+			slog.Error("Error recording time changes", "error", err, "time_id", t.ID)
+		}
+	}
+
+	return nil
+}
+
 func (s *TimeService) TimeSync(ctx context.Context) {
 	ticker := time.NewTicker(20 * time.Second)
 	defer ticker.Stop()
@@ -86,46 +121,21 @@ type projectsLister interface {
 	ListProjects(ctx context.Context) ([]project.Project, error)
 }
 
-func (s *TimeService) updateSheets(ctx context.Context) {
+func (s *TimeService) UpdateSheets(ctx context.Context) error {
 
 	times, err := s.timesLister.ListTimes(ctx, entity_time.TimeFilter{}, 0, 20000)
 	if err != nil {
 		slog.Error("Error getting times", "error", err)
-		return
+		return err
 	}
 
 	if err := s.sheetsRepository.UpdateSheetsTimes(ctx, viper.GetString("sheets.id"), times); err != nil {
 		slog.Error("Error updating sheets", "error", err)
-		return
-	}
-
-	// projects, err := s.projectsLister.ListProjects(ctx)
-	// if err != nil {
-	// 	slog.Error("Error getting projects", "error", err)
-	// 	return
-	// }
-
-	// for _, project := range projects {
-	// 	if project.SheetsLink == "" {
-	// 		continue
-	// 	}
-
-	// 	sheetsID, err := utils.ExtractSpreadsheetID(project.SheetsLink)
-	// 	if err != nil {
-	// 		slog.Error("Error extracting spreadsheet ID", "error", err)
-	// 		continue
-	// 	}
-	// 	times, err := s.timesLister.ListTimes(ctx, entity_time.TimeFilter{ProjectID: project.ID}, 0, 20000)
-	// 	if err != nil {
-	// 		slog.Error("Error getting times", "error", err)
-	// 		return
-	// 	}
-	// 	if err := s.sheetsRepository.UpdateSheetsTimes(ctx, sheetsID, times); err != nil {
-	// 		slog.Error("Error updating sheets", "error", err)
-	// 		return
-	// 	}
-	// }
+		return err
+	}
+	// Таблицы отдельных проектов обновляет ProjectService.UpdateAllProjectSheets
 
+	return nil
 }
 
 func (s *TimeService) UpdateProjectSheets(ctx context.Context, projectID uuid.UUID) error {
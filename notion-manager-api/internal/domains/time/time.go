@@ -1,14 +1,17 @@
 package feedback
 
 import (
+	audit_service "github.com/Corray333/employee_dashboard/internal/domains/audit/service"
 	project_repo "github.com/Corray333/employee_dashboard/internal/domains/project/service"
 	notion_repo "github.com/Corray333/employee_dashboard/internal/domains/time/repositories/notion"
 	postgres_repo "github.com/Corray333/employee_dashboard/internal/domains/time/repositories/postgres"
 	"github.com/Corray333/employee_dashboard/internal/domains/time/repositories/sheets"
 	"github.com/Corray333/employee_dashboard/internal/domains/time/service"
 	"github.com/Corray333/employee_dashboard/internal/domains/time/transport"
+	"github.com/Corray333/employee_dashboard/internal/export"
 	"github.com/Corray333/employee_dashboard/internal/postgres"
 	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+	"github.com/Corray333/employee_dashboard/internal/telegram"
 	notion "github.com/Corray333/employee_dashboard/pkg/notion/v2"
 	"github.com/go-chi/chi/v5"
 )
@@ -20,13 +23,13 @@ type TimeController struct {
 	transport    *transport.TimeTransport
 }
 
-func NewTimeController(router *chi.Mux, store *postgres.PostgresClient, notionClient *notion.Client, sheetsClient *gsheets.Client, projectRepository *project_repo.ProjectService) *TimeController {
+func NewTimeController(router *chi.Mux, store *postgres.PostgresClient, notionClient *notion.Client, sheetsClient *gsheets.Client, tgClient *telegram.TelegramClient, projectRepository *project_repo.ProjectService, auditService *audit_service.AuditService) *TimeController {
 
 	postgresRepo := postgres_repo.NewTimePostgresRepository(store)
 	notionRepo := notion_repo.NewTimeNotionRepository(notionClient)
-	sheetsRepo := sheets.NewTimeSheetsRepository(sheetsClient)
+	sheetsRepo := sheets.NewTimeSheetsRepository(export.NewSink("times", sheetsClient, tgClient))
 
-	service := service.NewTimeService(service.WithPostgresRepository(postgresRepo), service.WithNotionRepository(notionRepo), service.WithSheetsRepository(sheetsRepo), service.WithProjectRepository(projectRepository))
+	service := service.NewTimeService(service.WithPostgresRepository(postgresRepo), service.WithNotionRepository(notionRepo), service.WithSheetsRepository(sheetsRepo), service.WithProjectRepository(projectRepository), service.WithChangesRecorder(auditService))
 
 	transport := transport.NewTimeTransport(router, service)
 
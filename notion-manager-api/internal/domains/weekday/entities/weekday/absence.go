@@ -0,0 +1,237 @@
+package weekday
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// AbsenceProject - проект, на котором у отсутствующего сотрудника есть незавершенные задачи
+type AbsenceProject struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	ManagerTgID int64     `json:"-"`
+}
+
+// Absence - отсутствие сотрудника вместе с его направлением и текущими проектами
+type Absence struct {
+	WeekdayID  uuid.UUID        `json:"weekday_id"`
+	EmployeeID uuid.UUID        `json:"employee_id"`
+	Username   string           `json:"username"`
+	Direction  string           `json:"direction"`
+	Category   Category         `json:"category"`
+	Start      time.Time        `json:"start"`
+	End        time.Time        `json:"end"`
+	Reason     string           `json:"reason"`
+	Projects   []AbsenceProject `json:"projects"`
+}
+
+// Days возвращает первый и последний день отсутствия. Однодневное отсутствие хранится без даты окончания.
+func (a *Absence) Days() (time.Time, time.Time) {
+	first := day(a.Start)
+	last := first
+	if !a.End.IsZero() && day(a.End).After(first) {
+		last = day(a.End)
+	}
+	return first, last
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// CalendarFilter - отбор отсутствий за период, пересекающийся с [From, To]
+type CalendarFilter struct {
+	From       time.Time  `json:"from"`
+	To         time.Time  `json:"to"`
+	EmployeeID uuid.UUID  `json:"employee_id"`
+	Direction  string     `json:"direction"`
+	ProjectID  uuid.UUID  `json:"project_id"`
+	Categories []Category `json:"categories"`
+}
+
+func (f *CalendarFilter) Validate() error {
+	if f.From.IsZero() || f.To.IsZero() {
+		return fmt.Errorf("%w: from and to are required", ErrInvalidPeriod)
+	}
+	if f.To.Before(f.From) {
+		return fmt.Errorf("%w: to is before from", ErrInvalidPeriod)
+	}
+	return nil
+}
+
+// Match проверяет условия фильтра, которые зависят от направления и проектов сотрудника
+func (f *CalendarFilter) Match(a *Absence) bool {
+	if f.EmployeeID != uuid.Nil && a.EmployeeID != f.EmployeeID {
+		return false
+	}
+	if f.Direction != "" && a.Direction != f.Direction {
+		return false
+	}
+	if len(f.Categories) > 0 && !slices.Contains(f.Categories, a.Category) {
+		return false
+	}
+	if f.ProjectID != uuid.Nil && !slices.ContainsFunc(a.Projects, func(p AbsenceProject) bool { return p.ID == f.ProjectID }) {
+		return false
+	}
+	return true
+}
+
+type ConflictScope string
+
+const (
+	ConflictScopeDirection ConflictScope = "direction"
+	ConflictScopeProject   ConflictScope = "project"
+)
+
+// AbsenceLimits - сколько человек из одного направления или проекта могут отсутствовать одновременно, 0 - без ограничения
+type AbsenceLimits struct {
+	PerDirection int `mapstructure:"per_direction"`
+	PerProject   int `mapstructure:"per_project"`
+}
+
+// AbsenceConflict - дни, когда отсутствует больше людей из направления или проекта, чем допускает лимит
+type AbsenceConflict struct {
+	Scope       ConflictScope `json:"scope"`
+	Key         string        `json:"key"`
+	Name        string        `json:"name"`
+	Start       time.Time     `json:"start"`
+	End         time.Time     `json:"end"`
+	Employees   []string      `json:"employees"`
+	Limit       int           `json:"limit"`
+	ManagerTgID int64         `json:"-"`
+	// WeekdayIDs - отсутствия, из которых сложился конфликт, по возрастанию
+	WeekdayIDs []uuid.UUID `json:"weekday_ids"`
+}
+
+// Signature однозначно описывает конфликт, чтобы не предупреждать о нем повторно. Даты в подпись
+// не входят: проверяемый период сдвигается каждый день, и тот же конфликт получал бы новую подпись.
+func (c *AbsenceConflict) Signature() string {
+	ids := make([]string, 0, len(c.WeekdayIDs))
+	for _, id := range c.WeekdayIDs {
+		ids = append(ids, id.String())
+	}
+	return fmt.Sprintf("%s|%s|%s", c.Scope, c.Key, strings.Join(ids, ","))
+}
+
+type absenceGroup struct {
+	scope       ConflictScope
+	key         string
+	name        string
+	limit       int
+	managerTgID int64
+	absences    []*Absence
+}
+
+// DetectAbsenceConflicts ищет в периоде [from, to] дни, когда из одного направления или проекта
+// отсутствует больше людей, чем разрешено. Идущие подряд дни с одним и тем же составом
+// отсутствующих объединяются в один конфликт. Нерабочие дни по isWorkingDay не проверяются и не разрывают конфликт,
+// nil - проверяются все дни.
+func DetectAbsenceConflicts(absences []Absence, limits AbsenceLimits, from, to time.Time, isWorkingDay WorkingDayFunc) []AbsenceConflict {
+	groups := map[string]*absenceGroup{}
+	add := func(scope ConflictScope, key, name string, limit int, managerTgID int64, a *Absence) {
+		if limit <= 0 || key == "" {
+			return
+		}
+		id := string(scope) + "|" + key
+		g, ok := groups[id]
+		if !ok {
+			g = &absenceGroup{scope: scope, key: key, name: name, limit: limit, managerTgID: managerTgID}
+			groups[id] = g
+		}
+		g.absences = append(g.absences, a)
+	}
+
+	for i := range absences {
+		a := &absences[i]
+		add(ConflictScopeDirection, a.Direction, a.Direction, limits.PerDirection, 0, a)
+		for _, p := range a.Projects {
+			add(ConflictScopeProject, p.ID.String(), p.Name, limits.PerProject, p.ManagerTgID, a)
+		}
+	}
+
+	conflicts := []AbsenceConflict{}
+	for _, g := range groups {
+		conflicts = append(conflicts, g.conflicts(day(from), day(to), isWorkingDay)...)
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if !conflicts[i].Start.Equal(conflicts[j].Start) {
+			return conflicts[i].Start.Before(conflicts[j].Start)
+		}
+		if conflicts[i].Scope != conflicts[j].Scope {
+			return conflicts[i].Scope < conflicts[j].Scope
+		}
+		return conflicts[i].Name < conflicts[j].Name
+	})
+
+	return conflicts
+}
+
+func (g *absenceGroup) conflicts(from, to time.Time, isWorkingDay WorkingDayFunc) []AbsenceConflict {
+	conflicts := []AbsenceConflict{}
+	var current *AbsenceConflict
+
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if isWorkingDay != nil && !isWorkingDay(d) {
+			continue
+		}
+
+		absent := map[string]bool{}
+		weekdayIDs := []uuid.UUID{}
+		for _, a := range g.absences {
+			first, last := a.Days()
+			if !d.Before(first) && !d.After(last) {
+				absent[a.Username] = true
+				weekdayIDs = append(weekdayIDs, a.WeekdayID)
+			}
+		}
+
+		if len(absent) <= g.limit {
+			current = nil
+			continue
+		}
+
+		employees := make([]string, 0, len(absent))
+		for username := range absent {
+			employees = append(employees, username)
+		}
+		sort.Strings(employees)
+
+		if current != nil && slices.Equal(current.Employees, employees) {
+			current.End = d
+			current.WeekdayIDs = sortedWeekdayIDs(append(current.WeekdayIDs, weekdayIDs...))
+			continue
+		}
+
+		conflicts = append(conflicts, AbsenceConflict{
+			Scope:       g.scope,
+			Key:         g.key,
+			Name:        g.name,
+			Start:       d,
+			End:         d,
+			Employees:   employees,
+			Limit:       g.limit,
+			ManagerTgID: g.managerTgID,
+			WeekdayIDs:  sortedWeekdayIDs(weekdayIDs),
+		})
+		current = &conflicts[len(conflicts)-1]
+	}
+
+	return conflicts
+}
+
+// sortedWeekdayIDs сортирует ID отсутствий и убирает повторы
+func sortedWeekdayIDs(ids []uuid.UUID) []uuid.UUID {
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return slices.Compact(ids)
+}
@@ -0,0 +1,143 @@
+package weekday
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDetectAbsenceConflicts(t *testing.T) {
+	date := func(d int) time.Time { return time.Date(2025, time.March, d, 0, 0, 0, 0, time.UTC) }
+	project := AbsenceProject{ID: uuid.MustParse("6a0b7c2e-1f4d-4f2a-9a51-3b8e2d1c0f11"), Name: "CRM"}
+
+	tests := []struct {
+		name     string
+		absences []Absence
+		limits   AbsenceLimits
+		weekdays bool
+		expected []AbsenceConflict
+	}{
+		{
+			name: "within limit",
+			absences: []Absence{
+				{Username: "anna", Direction: "QA", Start: date(3), End: date(5)},
+				{Username: "ivan", Direction: "QA", Start: date(6)},
+			},
+			limits:   AbsenceLimits{PerDirection: 1},
+			expected: []AbsenceConflict{},
+		},
+		{
+			name: "overlapping in direction",
+			absences: []Absence{
+				{Username: "anna", Direction: "QA", Start: date(3), End: date(5)},
+				{Username: "ivan", Direction: "QA", Start: date(4), End: date(7)},
+				{Username: "olga", Direction: "Frontend", Start: date(4)},
+			},
+			limits: AbsenceLimits{PerDirection: 1},
+			expected: []AbsenceConflict{
+				{Scope: ConflictScopeDirection, Key: "QA", Name: "QA", Start: date(4), End: date(5), Employees: []string{"anna", "ivan"}, Limit: 1},
+			},
+		},
+		{
+			name: "changed set of absent splits conflict",
+			absences: []Absence{
+				{Username: "anna", Direction: "QA", Start: date(3), End: date(6)},
+				{Username: "ivan", Direction: "QA", Start: date(3), End: date(4)},
+				{Username: "olga", Direction: "QA", Start: date(5), End: date(6)},
+			},
+			limits: AbsenceLimits{PerDirection: 1},
+			expected: []AbsenceConflict{
+				{Scope: ConflictScopeDirection, Key: "QA", Name: "QA", Start: date(3), End: date(4), Employees: []string{"anna", "ivan"}, Limit: 1},
+				{Scope: ConflictScopeDirection, Key: "QA", Name: "QA", Start: date(5), End: date(6), Employees: []string{"anna", "olga"}, Limit: 1},
+			},
+		},
+		{
+			name: "project conflict across directions",
+			absences: []Absence{
+				{Username: "anna", Direction: "QA", Start: date(10), Projects: []AbsenceProject{project}},
+				{Username: "ivan", Direction: "Backend", Start: date(10), Projects: []AbsenceProject{project}},
+			},
+			limits: AbsenceLimits{PerDirection: 1, PerProject: 1},
+			expected: []AbsenceConflict{
+				{Scope: ConflictScopeProject, Key: project.ID.String(), Name: "CRM", Start: date(10), End: date(10), Employees: []string{"anna", "ivan"}, Limit: 1},
+			},
+		},
+		{
+			name: "weekend does not split conflict",
+			absences: []Absence{
+				{Username: "anna", Direction: "QA", Start: date(6), End: date(11)},
+				{Username: "ivan", Direction: "QA", Start: date(7), End: date(10)},
+			},
+			limits:   AbsenceLimits{PerDirection: 1},
+			weekdays: true,
+			expected: []AbsenceConflict{
+				{Scope: ConflictScopeDirection, Key: "QA", Name: "QA", Start: date(7), End: date(10), Employees: []string{"anna", "ivan"}, Limit: 1},
+			},
+		},
+		{
+			name: "conflict only on weekend",
+			absences: []Absence{
+				{Username: "anna", Direction: "QA", Start: date(8), End: date(9)},
+				{Username: "ivan", Direction: "QA", Start: date(8), End: date(9)},
+			},
+			limits:   AbsenceLimits{PerDirection: 1},
+			weekdays: true,
+			expected: []AbsenceConflict{},
+		},
+		{
+			name: "disabled limits",
+			absences: []Absence{
+				{Username: "anna", Direction: "QA", Start: date(10), Projects: []AbsenceProject{project}},
+				{Username: "ivan", Direction: "QA", Start: date(10), Projects: []AbsenceProject{project}},
+			},
+			expected: []AbsenceConflict{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var isWorkingDay WorkingDayFunc
+			if tt.weekdays {
+				isWorkingDay = IsWeekday
+			}
+			got := DetectAbsenceConflicts(tt.absences, tt.limits, date(1), date(31), isWorkingDay)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("DetectAbsenceConflicts() returned %d conflicts, expected %d: %+v", len(got), len(tt.expected), got)
+			}
+			for i := range got {
+				g, e := got[i], tt.expected[i]
+				if g.Scope != e.Scope || g.Key != e.Key || g.Name != e.Name || !g.Start.Equal(e.Start) || !g.End.Equal(e.End) ||
+					!slices.Equal(g.Employees, e.Employees) || g.Limit != e.Limit {
+					t.Errorf("conflict %d = %+v, expected %+v", i, g, e)
+				}
+			}
+		})
+	}
+}
+
+func TestAbsenceConflictSignature(t *testing.T) {
+	date := func(d int) time.Time { return time.Date(2025, time.March, d, 0, 0, 0, 0, time.UTC) }
+	anna := uuid.MustParse("1c9a4a52-3f0e-4b8e-9d1a-0a6f4f2b7c01")
+	ivan := uuid.MustParse("0b2e7d41-8c3a-4e6f-a1d2-5f9e3c7b8a02")
+	absences := []Absence{
+		{WeekdayID: anna, Username: "anna", Direction: "QA", Start: date(3), End: date(10)},
+		{WeekdayID: ivan, Username: "ivan", Direction: "QA", Start: date(4), End: date(12)},
+	}
+	limits := AbsenceLimits{PerDirection: 1}
+
+	// Проверка идет каждый день с сегодняшнего, поэтому начало периода сдвигается
+	first := DetectAbsenceConflicts(absences, limits, date(1), date(31), nil)
+	later := DetectAbsenceConflicts(absences, limits, date(6), date(8), nil)
+	if len(first) != 1 || len(later) != 1 {
+		t.Fatalf("expected one conflict in each period, got %+v and %+v", first, later)
+	}
+
+	if !slices.Equal(first[0].WeekdayIDs, []uuid.UUID{ivan, anna}) {
+		t.Errorf("WeekdayIDs = %v, expected sorted %v", first[0].WeekdayIDs, []uuid.UUID{ivan, anna})
+	}
+	if first[0].Signature() != later[0].Signature() {
+		t.Errorf("signature changed with the period: %q != %q", first[0].Signature(), later[0].Signature())
+	}
+}
@@ -0,0 +1,157 @@
+package weekday
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrWeekdayNotFound = errors.New("weekday not found")
+
+// ImpactTask - незавершенная задача отсутствующего сотрудника
+type ImpactTask struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Status      string    `json:"status"`
+	ProjectID   uuid.UUID `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+	ManagerTgID int64     `json:"-"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// Intersects проверяет, пересекаются ли сроки задачи с днями [first, last].
+// Задача без начала занимает только день окончания, без окончания - только день начала, без сроков - не пересекается.
+func (t *ImpactTask) Intersects(first, last time.Time) bool {
+	start, end := t.Start, t.End
+	if start.IsZero() {
+		start = end
+	}
+	if end.IsZero() || end.Before(start) {
+		end = start
+	}
+	if start.IsZero() {
+		return false
+	}
+	return !day(start).After(last) && !day(end).Before(first)
+}
+
+// Substitute - сотрудник с той же экспертизой, у которого нет отсутствий в период.
+// Load - сколько его незавершенных задач приходится на этот период.
+type Substitute struct {
+	EmployeeID uuid.UUID `json:"employee_id"`
+	Username   string    `json:"username"`
+	Load       int       `json:"load"`
+}
+
+// AbsenceImpact - задачи одного проекта, сроки которых пересекаются с отсутствием
+type AbsenceImpact struct {
+	WeekdayID   uuid.UUID    `json:"weekday_id"`
+	Username    string       `json:"username"`
+	Category    Category     `json:"category"`
+	Start       time.Time    `json:"start"`
+	End         time.Time    `json:"end"`
+	ProjectID   uuid.UUID    `json:"project_id"`
+	ProjectName string       `json:"project_name"`
+	ManagerTgID int64        `json:"-"`
+	Tasks       []ImpactTask `json:"tasks"`
+	Substitutes []Substitute `json:"substitutes"`
+}
+
+// ComputeAbsenceImpact отбирает задачи, пересекающиеся с отсутствием, и группирует их по проектам.
+// Замены одни на все проекты: у них та же экспертиза, что и у отсутствующего.
+func ComputeAbsenceImpact(w *Weekday, tasks []ImpactTask, substitutes []Substitute) []AbsenceImpact {
+	absence := Absence{Start: w.PeriodStart, End: w.PeriodEnd}
+	first, last := absence.Days()
+
+	byProject := map[uuid.UUID]*AbsenceImpact{}
+	for _, t := range tasks {
+		if !t.Intersects(first, last) {
+			continue
+		}
+
+		impact, ok := byProject[t.ProjectID]
+		if !ok {
+			impact = &AbsenceImpact{
+				WeekdayID:   w.ID,
+				Username:    w.Employee.Username,
+				Category:    w.Category,
+				Start:       first,
+				End:         last,
+				ProjectID:   t.ProjectID,
+				ProjectName: t.ProjectName,
+				ManagerTgID: t.ManagerTgID,
+				Substitutes: substitutes,
+			}
+			byProject[t.ProjectID] = impact
+		}
+		impact.Tasks = append(impact.Tasks, t)
+	}
+
+	impacts := make([]AbsenceImpact, 0, len(byProject))
+	for _, impact := range byProject {
+		sort.SliceStable(impact.Tasks, func(i, j int) bool {
+			return taskDeadline(&impact.Tasks[i]).Before(taskDeadline(&impact.Tasks[j]))
+		})
+		impacts = append(impacts, *impact)
+	}
+	sort.Slice(impacts, func(i, j int) bool {
+		return impacts[i].ProjectName < impacts[j].ProjectName
+	})
+
+	return impacts
+}
+
+func taskDeadline(t *ImpactTask) time.Time {
+	if t.End.IsZero() {
+		return t.Start
+	}
+	return t.End
+}
+
+// Message - текст отчета для менеджера проекта
+func (i *AbsenceImpact) Message() string {
+	period := i.Start.Format("02.01.2006")
+	if i.End.After(i.Start) {
+		period += " - " + i.End.Format("02.01.2006")
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s отсутствует %s (%s). На проекте %s в этот период попадают задачи:\n", i.Username, period, i.Category, i.ProjectName)
+	for _, t := range i.Tasks {
+		fmt.Fprintf(b, "• %s (%s, %s)\n", t.Title, t.Status, taskPeriod(&t))
+	}
+
+	if len(i.Substitutes) == 0 {
+		b.WriteString("\nСвободных сотрудников с той же экспертизой в этот период нет")
+		return b.String()
+	}
+
+	candidates := make([]string, 0, len(i.Substitutes))
+	for _, s := range i.Substitutes {
+		candidates = append(candidates, fmt.Sprintf("%s (задач в период: %d)", s.Username, s.Load))
+	}
+	fmt.Fprintf(b, "\nМогут заменить: %s", strings.Join(candidates, ", "))
+	return b.String()
+}
+
+func taskPeriod(t *ImpactTask) string {
+	switch {
+	case t.Start.IsZero():
+		return "до " + t.End.Format("02.01")
+	case t.End.IsZero() || !day(t.End).After(day(t.Start)):
+		return t.Start.Format("02.01")
+	default:
+		return t.Start.Format("02.01") + " - " + t.End.Format("02.01")
+	}
+}
+
+// AbsenceImpactReport - отправленный менеджеру отчет о влиянии отсутствия на проект
+type AbsenceImpactReport struct {
+	ProjectID uuid.UUID
+	NotificationMessage
+}
@@ -0,0 +1,77 @@
+package weekday
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/employee/entities/employee"
+	"github.com/google/uuid"
+)
+
+func TestImpactTaskIntersects(t *testing.T) {
+	date := func(d int) time.Time { return time.Date(2025, time.May, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		task     ImpactTask
+		expected bool
+	}{
+		{name: "inside", task: ImpactTask{Start: date(5), End: date(6)}, expected: true},
+		{name: "covers absence", task: ImpactTask{Start: date(1), End: date(20)}, expected: true},
+		{name: "ends on first day", task: ImpactTask{Start: date(1), End: date(4)}, expected: true},
+		{name: "before", task: ImpactTask{Start: date(1), End: date(3)}},
+		{name: "after", task: ImpactTask{Start: date(11), End: date(12)}},
+		{name: "deadline only", task: ImpactTask{End: date(10)}, expected: true},
+		{name: "start only", task: ImpactTask{Start: date(11)}},
+		{name: "without dates", task: ImpactTask{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.Intersects(date(4), date(10)); got != tt.expected {
+				t.Errorf("Intersects() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestComputeAbsenceImpact(t *testing.T) {
+	date := func(d int) time.Time { return time.Date(2025, time.May, d, 0, 0, 0, 0, time.UTC) }
+	crm := uuid.MustParse("6a0b7c2e-1f4d-4f2a-9a51-3b8e2d1c0f11")
+	app := uuid.MustParse("0c6f7c7e-5b0d-4b44-9f67-0f5f4a8f5d21")
+
+	w := &Weekday{
+		ID:          uuid.New(),
+		Category:    CategoryVacation,
+		PeriodStart: date(5),
+		PeriodEnd:   date(9),
+		Employee:    employee.Employee{Username: "anna"},
+	}
+	tasks := []ImpactTask{
+		{Title: "Отчеты", Status: "В работе", ProjectID: crm, ProjectName: "CRM", ManagerTgID: 1, Start: date(1), End: date(8)},
+		{Title: "Релиз", Status: "Можно делать", ProjectID: crm, ProjectName: "CRM", ManagerTgID: 1, End: date(6)},
+		{Title: "Дизайн", ProjectID: app, ProjectName: "App", ManagerTgID: 2, Start: date(12), End: date(14)},
+		{Title: "Ревью", ProjectID: app, ProjectName: "App", ManagerTgID: 2, Start: date(9)},
+	}
+	substitutes := []Substitute{{Username: "ivan", Load: 1}}
+
+	got := ComputeAbsenceImpact(w, tasks, substitutes)
+	if len(got) != 2 {
+		t.Fatalf("ComputeAbsenceImpact() returned %d impacts, expected 2: %+v", len(got), got)
+	}
+
+	if got[0].ProjectName != "App" || len(got[0].Tasks) != 1 || got[0].Tasks[0].Title != "Ревью" {
+		t.Errorf("impact 0 = %+v, expected App with Ревью", got[0])
+	}
+	if got[1].ProjectName != "CRM" || len(got[1].Tasks) != 2 || got[1].Tasks[0].Title != "Релиз" || got[1].ManagerTgID != 1 {
+		t.Errorf("impact 1 = %+v, expected CRM with Релиз and Отчеты", got[1])
+	}
+
+	expected := "anna отсутствует 05.05.2025 - 09.05.2025 (Отпуск). На проекте CRM в этот период попадают задачи:\n" +
+		"• Релиз (Можно делать, до 06.05)\n" +
+		"• Отчеты (В работе, 01.05 - 08.05)\n" +
+		"\nМогут заменить: ivan (задач в период: 1)"
+	if msg := got[1].Message(); msg != expected {
+		t.Errorf("Message() = %q, expected %q", msg, expected)
+	}
+}
@@ -0,0 +1,132 @@
+package weekday
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidSubscription      = errors.New("invalid notification subscription")
+	ErrInvalidDirectionLead     = errors.New("invalid direction lead")
+	ErrNotificationNotDelivered = errors.New("notification is not delivered to all recipients")
+)
+
+type RecipientKind string
+
+const (
+	RecipientDirectionLead  RecipientKind = "direction_lead"
+	RecipientProjectManager RecipientKind = "project_manager"
+	RecipientSubscription   RecipientKind = "subscription"
+)
+
+// Recipient - чат или тема форума, куда отправляется уведомление об отсутствии
+type Recipient struct {
+	ChatID   int64         `json:"chat_id" db:"chat_id"`
+	ThreadID int64         `json:"thread_id" db:"thread_id"`
+	Kind     RecipientKind `json:"kind" db:"kind"`
+}
+
+// UniqueRecipients убирает повторы одного и того же чата, оставляя первый
+func UniqueRecipients(recipients []Recipient) []Recipient {
+	seen := map[[2]int64]bool{}
+	unique := make([]Recipient, 0, len(recipients))
+	for _, r := range recipients {
+		key := [2]int64{r.ChatID, r.ThreadID}
+		if r.ChatID == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, r)
+	}
+	return unique
+}
+
+// NotificationSubscription - подписка чата или темы форума на уведомления об отсутствиях в направлении.
+// Пустое направление - все направления.
+type NotificationSubscription struct {
+	ID        int64     `json:"id"`
+	ChatID    int64     `json:"chat_id"`
+	ThreadID  int64     `json:"thread_id"`
+	Direction string    `json:"direction"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (s *NotificationSubscription) Validate() error {
+	if s.ChatID == 0 {
+		return errors.Join(ErrInvalidSubscription, errors.New("chat_id is required"))
+	}
+	if s.ThreadID < 0 {
+		return errors.Join(ErrInvalidSubscription, errors.New("thread_id must not be negative"))
+	}
+	return nil
+}
+
+// DirectionLead - руководитель направления, EmployeeID - employees.employee_id
+type DirectionLead struct {
+	Direction  string    `json:"direction"`
+	EmployeeID string    `json:"employee_id"`
+	Username   string    `json:"username"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// NotificationMessage - уведомление об отсутствии у одного получателя.
+// MessageID 0 - сообщение еще не доставлено, Attempts - неудачные попытки доставить Text.
+type NotificationMessage struct {
+	WeekdayID uuid.UUID
+	Recipient
+	MessageID int64
+	Text      string
+	Attempts  int
+	LastError string
+}
+
+// Delivered сообщает, что получатель видит актуальный текст text
+func (m *NotificationMessage) Delivered(text string) bool {
+	return m.MessageID != 0 && m.Text == text && m.LastError == ""
+}
+
+// PlanNotification возвращает сообщения, которые нужно отправить или отредактировать, чтобы все получатели
+// увидели text. Уже отправленные сообщения редактируются, даже если сотрудник больше не относится к получателю.
+// Получатели, которым не удалось доставить text за maxAttempts попыток, пропускаются.
+func PlanNotification(weekdayID uuid.UUID, recipients []Recipient, sent []NotificationMessage, text string, maxAttempts int) []NotificationMessage {
+	byRecipient := map[[2]int64]NotificationMessage{}
+	for _, m := range sent {
+		byRecipient[[2]int64{m.ChatID, m.ThreadID}] = m
+	}
+
+	plan := []NotificationMessage{}
+	planned := map[[2]int64]bool{}
+	add := func(m NotificationMessage) {
+		key := [2]int64{m.ChatID, m.ThreadID}
+		if planned[key] || m.Delivered(text) {
+			return
+		}
+		planned[key] = true
+
+		if m.Text != text {
+			m.Attempts = 0
+		}
+		if maxAttempts > 0 && m.Attempts >= maxAttempts {
+			return
+		}
+		plan = append(plan, m)
+	}
+
+	for _, r := range UniqueRecipients(recipients) {
+		m, ok := byRecipient[[2]int64{r.ChatID, r.ThreadID}]
+		if !ok {
+			m = NotificationMessage{WeekdayID: weekdayID, Recipient: r}
+		}
+		add(m)
+	}
+	for _, m := range sent {
+		if m.MessageID != 0 {
+			add(m)
+		}
+	}
+
+	return plan
+}
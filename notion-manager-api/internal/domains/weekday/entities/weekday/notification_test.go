@@ -0,0 +1,81 @@
+package weekday
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPlanNotification(t *testing.T) {
+	weekdayID := uuid.MustParse("6a0b7c2e-1f4d-4f2a-9a51-3b8e2d1c0f11")
+	lead := Recipient{ChatID: 1, Kind: RecipientDirectionLead}
+	manager := Recipient{ChatID: 2, Kind: RecipientProjectManager}
+	topic := Recipient{ChatID: -100, ThreadID: 7, Kind: RecipientSubscription}
+
+	tests := []struct {
+		name       string
+		recipients []Recipient
+		sent       []NotificationMessage
+		text       string
+		expected   []NotificationMessage
+	}{
+		{
+			name:       "new weekday",
+			recipients: []Recipient{lead, manager, topic, {ChatID: 1, Kind: RecipientProjectManager}},
+			text:       "v1",
+			expected: []NotificationMessage{
+				{WeekdayID: weekdayID, Recipient: lead},
+				{WeekdayID: weekdayID, Recipient: manager},
+				{WeekdayID: weekdayID, Recipient: topic},
+			},
+		},
+		{
+			name:       "retry only failed",
+			recipients: []Recipient{lead, manager},
+			sent: []NotificationMessage{
+				{WeekdayID: weekdayID, Recipient: lead, MessageID: 10, Text: "v1"},
+				{WeekdayID: weekdayID, Recipient: manager, Text: "v1", Attempts: 1, LastError: "blocked"},
+			},
+			text: "v1",
+			expected: []NotificationMessage{
+				{WeekdayID: weekdayID, Recipient: manager, Text: "v1", Attempts: 1, LastError: "blocked"},
+			},
+		},
+		{
+			name:       "give up after max attempts",
+			recipients: []Recipient{manager},
+			sent: []NotificationMessage{
+				{WeekdayID: weekdayID, Recipient: manager, Text: "v1", Attempts: 3, LastError: "blocked"},
+			},
+			text:     "v1",
+			expected: []NotificationMessage{},
+		},
+		{
+			name:       "update edits sent and retries given up",
+			recipients: []Recipient{manager},
+			sent: []NotificationMessage{
+				{WeekdayID: weekdayID, Recipient: lead, MessageID: 10, Text: "v1"},
+				{WeekdayID: weekdayID, Recipient: manager, Text: "v1", Attempts: 3, LastError: "blocked"},
+			},
+			text: "v2",
+			expected: []NotificationMessage{
+				{WeekdayID: weekdayID, Recipient: manager, Text: "v1", LastError: "blocked"},
+				{WeekdayID: weekdayID, Recipient: lead, MessageID: 10, Text: "v1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PlanNotification(weekdayID, tt.recipients, tt.sent, tt.text, 3)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("PlanNotification() returned %d messages, expected %d: %+v", len(got), len(tt.expected), got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("message %d = %+v, expected %+v", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package weekday
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidVacationYear = errors.New("invalid vacation year")
+
+// WorkingDayFunc сообщает, является ли день рабочим
+type WorkingDayFunc func(day time.Time) bool
+
+// IsWeekday считает рабочими все дни, кроме субботы и воскресенья
+func IsWeekday(day time.Time) bool {
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+}
+
+// VacationRule - правило начисления отпуска для статуса сотрудника
+type VacationRule struct {
+	Status      string  `mapstructure:"status" json:"status"`
+	DaysPerYear float64 `mapstructure:"days_per_year" json:"days_per_year"`
+	// Monthly - в текущем году дни начисляются помесячно, а не сразу за весь год
+	Monthly bool `mapstructure:"monthly" json:"monthly"`
+	// MaxCarryOver - сколько неиспользованных дней переходит на следующий год, -1 - без ограничения
+	MaxCarryOver float64 `mapstructure:"max_carry_over" json:"max_carry_over"`
+}
+
+// VacationRules - правила учета отпусков. Учет ведется с SinceYear, остаток на его начало задается вручную.
+type VacationRules struct {
+	SinceYear int            `mapstructure:"since_year"`
+	Default   VacationRule   `mapstructure:"default"`
+	Statuses  []VacationRule `mapstructure:"statuses"`
+}
+
+func (r *VacationRules) Rule(status string) VacationRule {
+	for _, rule := range r.Statuses {
+		if rule.Status == status {
+			return rule
+		}
+	}
+	return r.Default
+}
+
+// VacationEmployee - сотрудник, для которого считается остаток отпуска
+type VacationEmployee struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Status   string    `json:"status"`
+	Geo      string    `json:"geo"`
+}
+
+// VacationUsage - рабочие дни отпуска за год: уже прошедшие и запланированные
+type VacationUsage struct {
+	Used    float64
+	Planned float64
+}
+
+// VacationYear - движение дней отпуска за год
+type VacationYear struct {
+	Year        int     `json:"year"`
+	CarriedOver float64 `json:"carried_over"`
+	Accrued     float64 `json:"accrued"`
+	Used        float64 `json:"used"`
+	Planned     float64 `json:"planned"`
+	Remaining   float64 `json:"remaining"`
+}
+
+// VacationBalance - остаток отпуска сотрудника на год вместе с историей предыдущих лет учета
+type VacationBalance struct {
+	Employee VacationEmployee `json:"employee"`
+	Rule     VacationRule     `json:"rule"`
+	VacationYear
+	History []VacationYear `json:"history"`
+}
+
+// VacationDays раскладывает отпуска по годам, считая только рабочие дни.
+// Дни до asOf включительно считаются использованными, остальные - запланированными.
+func VacationDays(vacations []Absence, asOf time.Time, isWorkingDay WorkingDayFunc) map[int]VacationUsage {
+	asOf = day(asOf)
+	usage := map[int]VacationUsage{}
+	for i := range vacations {
+		first, last := vacations[i].Days()
+		for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+			if !isWorkingDay(d) {
+				continue
+			}
+			u := usage[d.Year()]
+			if d.After(asOf) {
+				u.Planned++
+			} else {
+				u.Used++
+			}
+			usage[d.Year()] = u
+		}
+	}
+	return usage
+}
+
+// ComputeVacationBalance считает движение отпуска по годам с sinceYear по year.
+// opening - остаток на начало sinceYear. Перерасход переносится на следующий год целиком,
+// положительный остаток - не больше MaxCarryOver. При помесячном начислении запланированные дни
+// текущего года сначала покрываются днями, которые начислятся до конца года.
+func ComputeVacationBalance(rule VacationRule, sinceYear int, opening float64, usage map[int]VacationUsage, year int, asOf time.Time) []VacationYear {
+	years := []VacationYear{}
+	carried := opening
+	for y := sinceYear; y <= year; y++ {
+		accrued, upcoming := rule.DaysPerYear, 0.0
+		if rule.Monthly && y == asOf.Year() {
+			accrued = rule.DaysPerYear * float64(asOf.Month()) / 12
+			upcoming = rule.DaysPerYear - accrued
+		}
+
+		u := usage[y]
+		remaining := carried + accrued - u.Used - math.Max(0, u.Planned-upcoming)
+		years = append(years, VacationYear{
+			Year:        y,
+			CarriedOver: round(carried),
+			Accrued:     round(accrued),
+			Used:        u.Used,
+			Planned:     u.Planned,
+			Remaining:   round(remaining),
+		})
+
+		carried = remaining
+		if remaining > 0 && rule.MaxCarryOver >= 0 {
+			carried = math.Min(remaining, rule.MaxCarryOver)
+		}
+	}
+	return years
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
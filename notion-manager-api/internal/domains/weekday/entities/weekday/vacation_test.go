@@ -0,0 +1,107 @@
+package weekday
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVacationDays(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	vacations := []Absence{
+		// пн-вс: 5 рабочих дней
+		{Start: date(2025, time.March, 10), End: date(2025, time.March, 16)},
+		// суббота
+		{Start: date(2025, time.March, 22)},
+		// через новый год: 2 рабочих дня в 2025 и 2 в 2026
+		{Start: date(2025, time.December, 30), End: date(2026, time.January, 2)},
+	}
+
+	usage := VacationDays(vacations, date(2025, time.March, 12), IsWeekday)
+
+	expected := map[int]VacationUsage{
+		2025: {Used: 3, Planned: 4},
+		2026: {Planned: 2},
+	}
+	if len(usage) != len(expected) {
+		t.Fatalf("VacationDays() = %+v, expected %+v", usage, expected)
+	}
+	for year, u := range expected {
+		if usage[year] != u {
+			t.Errorf("VacationDays()[%d] = %+v, expected %+v", year, usage[year], u)
+		}
+	}
+}
+
+func TestComputeVacationBalance(t *testing.T) {
+	asOf := time.Date(2025, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rule     VacationRule
+		opening  float64
+		usage    map[int]VacationUsage
+		expected []VacationYear
+	}{
+		{
+			name:  "carry over is capped",
+			rule:  VacationRule{DaysPerYear: 28, MaxCarryOver: 5},
+			usage: map[int]VacationUsage{2024: {Used: 20}, 2025: {Used: 10, Planned: 5}},
+			expected: []VacationYear{
+				{Year: 2024, Accrued: 28, Used: 20, Remaining: 8},
+				{Year: 2025, CarriedOver: 5, Accrued: 28, Used: 10, Planned: 5, Remaining: 18},
+			},
+		},
+		{
+			name:    "overspend moves to next year",
+			rule:    VacationRule{DaysPerYear: 20, MaxCarryOver: -1},
+			opening: 3,
+			usage:   map[int]VacationUsage{2024: {Used: 25}},
+			expected: []VacationYear{
+				{Year: 2024, CarriedOver: 3, Accrued: 20, Used: 25, Remaining: -2},
+				{Year: 2025, CarriedOver: -2, Accrued: 20, Remaining: 18},
+			},
+		},
+		{
+			name:  "monthly accrual in current year",
+			rule:  VacationRule{DaysPerYear: 28, Monthly: true, MaxCarryOver: 0},
+			usage: map[int]VacationUsage{2025: {Used: 4}},
+			expected: []VacationYear{
+				{Year: 2024, Accrued: 28, Remaining: 28},
+				{Year: 2025, Accrued: 14, Used: 4, Remaining: 10},
+			},
+		},
+		{
+			name:  "planned days are covered by upcoming monthly accrual",
+			rule:  VacationRule{DaysPerYear: 28, Monthly: true, MaxCarryOver: 0},
+			usage: map[int]VacationUsage{2025: {Used: 4, Planned: 15}},
+			expected: []VacationYear{
+				{Year: 2024, Accrued: 28, Remaining: 28},
+				{Year: 2025, Accrued: 14, Used: 4, Planned: 15, Remaining: 9},
+			},
+		},
+		{
+			name:  "planned days over the whole year accrual",
+			rule:  VacationRule{DaysPerYear: 28, Monthly: true, MaxCarryOver: 0},
+			usage: map[int]VacationUsage{2025: {Used: 4, Planned: 26}},
+			expected: []VacationYear{
+				{Year: 2024, Accrued: 28, Remaining: 28},
+				{Year: 2025, Accrued: 14, Used: 4, Planned: 26, Remaining: -2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeVacationBalance(tt.rule, 2024, tt.opening, tt.usage, 2025, asOf)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("ComputeVacationBalance() = %+v, expected %+v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("year %d = %+v, expected %+v", got[i].Year, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
 	"github.com/Corray333/employee_dashboard/internal/domains/employee/entities/employee"
 	"github.com/google/uuid"
 )
@@ -16,11 +17,23 @@ type Weekday struct {
 	Reason      string    `json:"reason"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	UpdatedBy   string    `json:"updated_by"`
 	Notified    bool      `json:"notified"`
 
 	Employee employee.Employee `json:"employee"`
 }
 
+// AuditFields возвращает поля отсутствия, изменения которых пишутся в журнал
+func (w *Weekday) AuditFields() map[string]string {
+	return map[string]string{
+		"employee_id":  audit.FormatUUID(w.Employee.ID),
+		"category":     string(w.Category),
+		"period_start": audit.FormatTime(w.PeriodStart),
+		"period_end":   audit.FormatTime(w.PeriodEnd),
+		"reason":       w.Reason,
+	}
+}
+
 var months = map[time.Month]string{
 	time.January:   "января",
 	time.February:  "февраля",
@@ -41,12 +54,18 @@ func formatDate(d time.Time) string {
 }
 
 func (w Weekday) GetNotifyMsg() string {
+	return w.GetNotifyMsgByCalendar(nil)
+}
+
+// GetNotifyMsgByCalendar - сообщение об отсутствии, где к длительности добавлено число рабочих дней по календарю.
+// Без календаря длительность указывается только в календарных днях.
+func (w Weekday) GetNotifyMsgByCalendar(isWorkingDay WorkingDayFunc) string {
 	username := w.Employee.Username
 	periodPhrase := w.getPeriodPhrase()
 	categoryPhrase := w.getCategoryPhrase()
 
 	msg := fmt.Sprintf("%s %s %s", username, categoryPhrase, periodPhrase)
-	return w.appendDaysCountIfNeeded(msg)
+	return w.appendDaysCountIfNeeded(msg, isWorkingDay)
 }
 
 func (w Weekday) getPeriodPhrase() string {
@@ -73,19 +92,26 @@ func (w Weekday) getCategoryPhrase() string {
 	return fmt.Sprintf("берёт %s", w.Category)
 }
 
-func (w Weekday) appendDaysCountIfNeeded(msg string) string {
-	var days int
-	if w.PeriodEnd.IsZero() {
-		days = 1
-	} else {
-		days = int(w.PeriodEnd.Sub(w.PeriodStart).Hours()/24) + 1
-	}
+func (w Weekday) appendDaysCountIfNeeded(msg string, isWorkingDay WorkingDayFunc) string {
+	a := Absence{Start: w.PeriodStart, End: w.PeriodEnd}
+	first, last := a.Days()
+	days := int(last.Sub(first).Hours()/24) + 1
 
 	if days >= 5 {
+		count := fmt.Sprintf("%d дней", days)
+		if isWorkingDay != nil {
+			working := 0
+			for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+				if isWorkingDay(d) {
+					working++
+				}
+			}
+			count = fmt.Sprintf("%s, из них рабочих %d", count, working)
+		}
 		if w.Reason != "" {
-			return fmt.Sprintf("%s (%d дней, %s)", msg, days, w.Reason)
+			return fmt.Sprintf("%s (%s, %s)", msg, count, w.Reason)
 		}
-		return fmt.Sprintf("%s (%d дней)", msg, days)
+		return fmt.Sprintf("%s (%s)", msg, count)
 	}
 
 	if w.Reason != "" {
@@ -139,3 +139,19 @@ func TestGetNotifyMsg(t *testing.T) {
 		})
 	}
 }
+
+func TestGetNotifyMsgByCalendar(t *testing.T) {
+	w := Weekday{
+		Category:    Category("отпуск"),
+		PeriodStart: time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2025, time.May, 7, 0, 0, 0, 0, time.UTC),
+		Employee:    employee.Employee{Username: "Mark"},
+	}
+	holidays := map[int]bool{1: true, 2: true}
+	isWorkingDay := func(d time.Time) bool { return IsWeekday(d) && !holidays[d.Day()] }
+
+	expects := "Mark берёт отпуск с 1 мая по 7 мая (7 дней, из них рабочих 3)"
+	if got := w.GetNotifyMsgByCalendar(isWorkingDay); got != expects {
+		t.Errorf("GetNotifyMsgByCalendar() = %q, expected %q", got, expects)
+	}
+}
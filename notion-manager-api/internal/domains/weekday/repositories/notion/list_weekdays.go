@@ -56,7 +56,10 @@ type Weekday struct {
 	ID             uuid.UUID `json:"id"`
 	CreatedTime    string    `json:"created_time"`
 	LastEditedTime string    `json:"last_edited_time"`
-	Properties     struct {
+	LastEditedBy   struct {
+		ID string `json:"id"`
+	} `json:"last_edited_by"`
+	Properties struct {
 		Employee struct {
 			Relation []struct {
 				ID uuid.UUID `json:"id"`
@@ -132,6 +135,7 @@ func (w *Weekday) ToEntity() *weekday.Weekday {
 		Reason:      reason,
 		CreatedAt:   createdTime,
 		UpdatedAt:   lastEditedTime,
+		UpdatedBy:   w.LastEditedBy.ID,
 		Employee: employee.Employee{
 			ID: employeeID,
 		},
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type absenceDB struct {
+	WeekdayID  uuid.UUID `db:"weekday_id"`
+	EmployeeID uuid.UUID `db:"employee_id"`
+	Username   string    `db:"username"`
+	Direction  string    `db:"direction"`
+	Category   string    `db:"category"`
+	Start      time.Time `db:"start_time"`
+	End        time.Time `db:"end_time"`
+	Reason     string    `db:"reason"`
+}
+
+// absenceProjectDB - проект отсутствующего: в tasks исполнитель хранится Notion ID пользователя,
+// а отсутствия ведутся по профилю, поэтому executor_id здесь - профиль исполнителя
+type absenceProjectDB struct {
+	EmployeeID  uuid.UUID `db:"executor_id"`
+	ProjectID   uuid.UUID `db:"project_id"`
+	Name        string    `db:"name"`
+	ManagerTgID int64     `db:"manager_tg_id"`
+}
+
+// ListAbsences возвращает отсутствия, пересекающиеся с периодом фильтра, вместе с направлением сотрудника
+// и проектами, где у него есть незавершенные задачи. Остальные условия фильтра применяются в сервисе.
+func (r *WeekdayPostgresRepository) ListAbsences(ctx context.Context, filter *weekday.CalendarFilter) ([]weekday.Absence, error) {
+	// Однодневное отсутствие хранится без даты окончания
+	query := `
+		SELECT
+			weekdays.weekday_id,
+			weekdays.employee_id,
+			COALESCE(employees.username, '') AS username,
+			COALESCE(employees.direction, '') AS direction,
+			weekdays.category,
+			weekdays.start_time,
+			weekdays.end_time,
+			weekdays.reason
+		FROM weekdays
+		LEFT JOIN employees ON employees.profile_id = weekdays.employee_id::text
+		WHERE weekdays.start_time < $2
+			AND GREATEST(weekdays.start_time, weekdays.end_time) >= $1
+		ORDER BY weekdays.start_time, username
+	`
+
+	absencesDB := []absenceDB{}
+	if err := r.DB().SelectContext(ctx, &absencesDB, query, filter.From, filter.To.AddDate(0, 0, 1)); err != nil {
+		slog.Error("Error listing absences", "error", err)
+		return nil, err
+	}
+
+	employeeIDs := make([]string, 0, len(absencesDB))
+	for _, a := range absencesDB {
+		employeeIDs = append(employeeIDs, a.EmployeeID.String())
+	}
+
+	projectsDB := []absenceProjectDB{}
+	if len(employeeIDs) > 0 {
+		if err := r.DB().SelectContext(ctx, &projectsDB, `
+			SELECT DISTINCT
+				executor.profile_id AS executor_id,
+				tasks.project_id,
+				COALESCE(projects.name, '') AS name,
+				COALESCE(manager.tg_id, 0) AS manager_tg_id
+			FROM tasks
+			JOIN employees executor ON executor.employee_id = tasks.executor_id
+			JOIN projects ON projects.project_id = tasks.project_id::text
+			LEFT JOIN employees manager ON manager.profile_id = projects.manager_id
+			WHERE executor.profile_id = ANY($1) AND NOT (tasks.status = ANY($2))
+		`, pq.Array(employeeIDs), pq.Array([]string{string(task.StatusDone), string(task.StatusCancelled)})); err != nil {
+			slog.Error("Error listing absent employees projects", "error", err)
+			return nil, err
+		}
+	}
+
+	projects := map[uuid.UUID][]weekday.AbsenceProject{}
+	for _, p := range projectsDB {
+		projects[p.EmployeeID] = append(projects[p.EmployeeID], weekday.AbsenceProject{
+			ID:          p.ProjectID,
+			Name:        p.Name,
+			ManagerTgID: p.ManagerTgID,
+		})
+	}
+
+	absences := make([]weekday.Absence, 0, len(absencesDB))
+	for _, a := range absencesDB {
+		absence := weekday.Absence{
+			WeekdayID:  a.WeekdayID,
+			EmployeeID: a.EmployeeID,
+			Username:   a.Username,
+			Direction:  a.Direction,
+			Category:   weekday.Category(a.Category),
+			Start:      a.Start,
+			Reason:     a.Reason,
+			Projects:   projects[a.EmployeeID],
+		}
+		if a.End.After(a.Start) {
+			absence.End = a.End
+		}
+		if absence.Projects == nil {
+			absence.Projects = []weekday.AbsenceProject{}
+		}
+		absences = append(absences, absence)
+	}
+
+	return absences, nil
+}
+
+// ListNotifiedAbsenceConflicts возвращает из переданных подписей конфликтов те, о которых уже предупреждали
+func (r *WeekdayPostgresRepository) ListNotifiedAbsenceConflicts(ctx context.Context, signatures []string) (map[string]bool, error) {
+	rows := []string{}
+	if err := r.DB().SelectContext(ctx, &rows, `SELECT signature FROM absence_conflict_alerts WHERE signature = ANY($1)`, pq.Array(signatures)); err != nil {
+		slog.Error("Error listing notified absence conflicts", "error", err)
+		return nil, err
+	}
+
+	notified := make(map[string]bool, len(rows))
+	for _, s := range rows {
+		notified[s] = true
+	}
+
+	return notified, nil
+}
+
+func (r *WeekdayPostgresRepository) SetAbsenceConflictNotified(ctx context.Context, conflict *weekday.AbsenceConflict, notifiedAt time.Time) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		INSERT INTO absence_conflict_alerts (signature, scope, key, start_date, end_date, notified_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (signature) DO NOTHING
+	`, conflict.Signature(), string(conflict.Scope), conflict.Key, conflict.Start, conflict.End, notifiedAt); err != nil {
+		slog.Error("Error saving absence conflict alert", "error", err)
+		return err
+	}
+
+	return nil
+}
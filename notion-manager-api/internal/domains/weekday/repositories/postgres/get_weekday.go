@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/google/uuid"
+)
+
+// GetWeekday возвращает отсутствие в том виде, в котором оно сохранено, или nil, если его еще нет.
+// В отличие от ListWeekdays не подгружает сотрудника.
+func (r *WeekdayPostgresRepository) GetWeekday(ctx context.Context, weekdayID uuid.UUID) (*weekday.Weekday, error) {
+	w := weekdayDB{}
+	err := r.DB().GetContext(ctx, &w, `SELECT * FROM weekdays WHERE weekday_id = $1`, weekdayID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		slog.Error("Error getting weekday", "error", err)
+		return nil, err
+	}
+
+	return w.ToEntity(), nil
+}
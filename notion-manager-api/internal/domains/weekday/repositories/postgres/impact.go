@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type impactTaskDB struct {
+	ID          uuid.UUID `db:"task_id"`
+	Title       string    `db:"title"`
+	Status      string    `db:"status"`
+	ProjectID   uuid.UUID `db:"project_id"`
+	ProjectName string    `db:"project_name"`
+	ManagerTgID int64     `db:"manager_tg_id"`
+	Start       time.Time `db:"start"`
+	End         time.Time `db:"end"`
+}
+
+// ListImpactTasks возвращает незавершенные задачи отсутствующего сотрудника, которые могут пересекаться с днями [first, last].
+// Отсутствие ведется по профилю, а исполнитель задачи - по Notion ID пользователя, поэтому они связываются через employees.
+// Точная проверка пересечения - в weekday.ComputeAbsenceImpact.
+func (r *WeekdayPostgresRepository) ListImpactTasks(ctx context.Context, weekdayID uuid.UUID, first, last time.Time) ([]weekday.ImpactTask, error) {
+	query := `
+		SELECT
+			tasks.task_id, tasks.title, tasks.status, tasks.project_id, tasks.start, tasks."end",
+			COALESCE(projects.name, '') AS project_name,
+			COALESCE(manager.tg_id, 0) AS manager_tg_id
+		FROM weekdays
+		JOIN employees executor ON executor.profile_id = weekdays.employee_id::text
+		JOIN tasks ON tasks.executor_id = executor.employee_id
+		LEFT JOIN projects ON projects.project_id = tasks.project_id::text
+		LEFT JOIN employees manager ON manager.profile_id = projects.manager_id
+		WHERE weekdays.weekday_id = $1
+			AND NOT (tasks.status = ANY($4))
+			AND tasks.start::date <= $3::date
+			AND GREATEST(tasks.start, tasks."end")::date >= $2::date
+	`
+
+	tasksDB := []impactTaskDB{}
+	if err := r.DB().SelectContext(ctx, &tasksDB, query, weekdayID, first, last,
+		pq.Array([]string{string(task.StatusDone), string(task.StatusCancelled)})); err != nil {
+		slog.Error("Error listing absence impact tasks", "error", err)
+		return nil, err
+	}
+
+	tasks := make([]weekday.ImpactTask, 0, len(tasksDB))
+	for _, t := range tasksDB {
+		tasks = append(tasks, weekday.ImpactTask(t))
+	}
+
+	return tasks, nil
+}
+
+type substituteDB struct {
+	EmployeeID uuid.UUID `db:"profile_id"`
+	Username   string    `db:"username"`
+	Load       int       `db:"load"`
+}
+
+// ListSubstitutes подбирает до limit сотрудников с той же экспертизой, что и у отсутствующего,
+// у которых нет отсутствий в дни [first, last]. Первыми идут наименее загруженные в этот период.
+func (r *WeekdayPostgresRepository) ListSubstitutes(ctx context.Context, weekdayID uuid.UUID, first, last time.Time, excludedStatuses []string, limit int) ([]weekday.Substitute, error) {
+	query := `
+		WITH absent AS (
+			SELECT employees.profile_id, employees.expertise_id
+			FROM weekdays
+			JOIN employees ON employees.profile_id = weekdays.employee_id::text
+			WHERE weekdays.weekday_id = $1
+		)
+		SELECT
+			employees.profile_id,
+			employees.username,
+			(
+				SELECT COUNT(*) FROM tasks
+				WHERE tasks.executor_id = employees.employee_id
+					AND NOT (tasks.status = ANY($5))
+					AND tasks.start::date <= $3::date
+					AND GREATEST(tasks.start, tasks."end")::date >= $2::date
+			) AS load
+		FROM employees
+		JOIN absent ON absent.expertise_id = employees.expertise_id
+		WHERE absent.expertise_id <> ''
+			AND employees.profile_id <> ''
+			AND employees.profile_id <> absent.profile_id
+			AND NOT (employees.status = ANY($4))
+			AND NOT EXISTS (
+				SELECT 1 FROM weekdays w
+				WHERE w.employee_id::text = employees.profile_id
+					AND w.start_time::date <= $3::date
+					AND GREATEST(w.end_time, w.start_time)::date >= $2::date
+			)
+		ORDER BY load, employees.username
+		LIMIT $6
+	`
+
+	substitutesDB := []substituteDB{}
+	if err := r.DB().SelectContext(ctx, &substitutesDB, query, weekdayID, first, last, pq.Array(excludedStatuses),
+		pq.Array([]string{string(task.StatusDone), string(task.StatusCancelled)}), limit); err != nil {
+		slog.Error("Error listing absence substitutes", "error", err)
+		return nil, err
+	}
+
+	substitutes := make([]weekday.Substitute, 0, len(substitutesDB))
+	for _, s := range substitutesDB {
+		substitutes = append(substitutes, weekday.Substitute(s))
+	}
+
+	return substitutes, nil
+}
+
+type impactReportDB struct {
+	WeekdayID uuid.UUID `db:"weekday_id"`
+	ProjectID uuid.UUID `db:"project_id"`
+	ChatID    int64     `db:"chat_id"`
+	MessageID int64     `db:"message_id"`
+	Text      string    `db:"text"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (r *WeekdayPostgresRepository) ListAbsenceImpactReports(ctx context.Context, weekdayID uuid.UUID) ([]weekday.AbsenceImpactReport, error) {
+	reportsDB := []impactReportDB{}
+	if err := r.DB().SelectContext(ctx, &reportsDB, `SELECT * FROM absence_impact_reports WHERE weekday_id = $1`, weekdayID); err != nil {
+		slog.Error("Error listing absence impact reports", "error", err)
+		return nil, err
+	}
+
+	reports := make([]weekday.AbsenceImpactReport, 0, len(reportsDB))
+	for _, rep := range reportsDB {
+		reports = append(reports, weekday.AbsenceImpactReport{
+			ProjectID: rep.ProjectID,
+			NotificationMessage: weekday.NotificationMessage{
+				WeekdayID: rep.WeekdayID,
+				Recipient: weekday.Recipient{ChatID: rep.ChatID, Kind: weekday.RecipientProjectManager},
+				MessageID: rep.MessageID,
+				Text:      rep.Text,
+			},
+		})
+	}
+
+	return reports, nil
+}
+
+func (r *WeekdayPostgresRepository) SetAbsenceImpactReport(ctx context.Context, report *weekday.AbsenceImpactReport) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		INSERT INTO absence_impact_reports (weekday_id, project_id, chat_id, message_id, text, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (weekday_id, project_id) DO UPDATE
+		SET
+			chat_id    = EXCLUDED.chat_id,
+			message_id = EXCLUDED.message_id,
+			text       = EXCLUDED.text,
+			updated_at = EXCLUDED.updated_at
+	`, report.WeekdayID, report.ProjectID, report.ChatID, report.MessageID, report.Text); err != nil {
+		slog.Error("Error saving absence impact report", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *WeekdayPostgresRepository) DeleteAbsenceImpactReport(ctx context.Context, report *weekday.AbsenceImpactReport) error {
+	if _, err := r.DB().ExecContext(ctx, `DELETE FROM absence_impact_reports WHERE weekday_id = $1 AND project_id = $2`, report.WeekdayID, report.ProjectID); err != nil {
+		slog.Error("Error deleting absence impact report", "error", err)
+		return err
+	}
+
+	return nil
+}
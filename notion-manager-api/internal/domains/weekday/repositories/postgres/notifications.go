@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/task/entities/task"
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/google/uuid"
+)
+
+// ListWeekdayRecipients возвращает получателей уведомления об отсутствии: руководителя направления сотрудника,
+// менеджеров проектов, где у него есть незавершенные задачи, и подписанные на направление чаты.
+// Сам отсутствующий сотрудник уведомление не получает.
+func (r *WeekdayPostgresRepository) ListWeekdayRecipients(ctx context.Context, weekdayID uuid.UUID) ([]weekday.Recipient, error) {
+	query := `
+		WITH absent AS (
+			SELECT employees.employee_id, COALESCE(employees.direction, '') AS direction, employees.tg_id
+			FROM weekdays
+			JOIN employees ON employees.profile_id = weekdays.employee_id::text
+			WHERE weekdays.weekday_id = $1
+		)
+		SELECT lead.tg_id AS chat_id, 0 AS thread_id, $4::text AS kind
+		FROM absent
+		JOIN direction_leads ON direction_leads.direction = absent.direction
+		JOIN employees lead ON lead.employee_id = direction_leads.employee_id
+		WHERE lead.tg_id <> 0 AND lead.tg_id <> absent.tg_id
+		UNION ALL
+		SELECT DISTINCT manager.tg_id, 0, $5::text
+		FROM absent
+		JOIN tasks ON tasks.executor_id = absent.employee_id
+		JOIN projects ON projects.project_id = tasks.project_id::text
+		JOIN employees manager ON manager.profile_id = projects.manager_id
+		WHERE tasks.status NOT IN ($2, $3) AND manager.tg_id <> 0 AND manager.tg_id <> absent.tg_id
+		UNION ALL
+		SELECT chat_id, thread_id, $6::text
+		FROM weekday_notification_subscriptions
+		WHERE direction = '' OR direction = (SELECT direction FROM absent)
+	`
+
+	recipients := []weekday.Recipient{}
+	if err := r.DB().SelectContext(ctx, &recipients, query, weekdayID, string(task.StatusDone), string(task.StatusCancelled),
+		string(weekday.RecipientDirectionLead), string(weekday.RecipientProjectManager), string(weekday.RecipientSubscription)); err != nil {
+		slog.Error("Error listing weekday recipients", "error", err)
+		return nil, err
+	}
+
+	return weekday.UniqueRecipients(recipients), nil
+}
+
+// ListDirectionRecipients возвращает руководителя направления и подписанные на него чаты.
+// Для пустого направления - только чаты, подписанные на все направления.
+func (r *WeekdayPostgresRepository) ListDirectionRecipients(ctx context.Context, direction string) ([]weekday.Recipient, error) {
+	query := `
+		SELECT lead.tg_id AS chat_id, 0 AS thread_id, $2::text AS kind
+		FROM direction_leads
+		JOIN employees lead ON lead.employee_id = direction_leads.employee_id
+		WHERE direction_leads.direction = $1 AND $1 <> '' AND lead.tg_id <> 0
+		UNION ALL
+		SELECT chat_id, thread_id, $3::text
+		FROM weekday_notification_subscriptions
+		WHERE direction = '' OR direction = $1
+	`
+
+	recipients := []weekday.Recipient{}
+	if err := r.DB().SelectContext(ctx, &recipients, query, direction,
+		string(weekday.RecipientDirectionLead), string(weekday.RecipientSubscription)); err != nil {
+		slog.Error("Error listing direction recipients", "error", err)
+		return nil, err
+	}
+
+	return weekday.UniqueRecipients(recipients), nil
+}
+
+type notificationMessageDB struct {
+	WeekdayID uuid.UUID `db:"weekday_id"`
+	ChatID    int64     `db:"chat_id"`
+	ThreadID  int64     `db:"thread_id"`
+	MessageID int64     `db:"message_id"`
+	Text      string    `db:"text"`
+	Attempts  int       `db:"attempts"`
+	LastError string    `db:"last_error"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (r *WeekdayPostgresRepository) ListNotificationMessages(ctx context.Context, weekdayID uuid.UUID) ([]weekday.NotificationMessage, error) {
+	messagesDB := []notificationMessageDB{}
+	if err := r.DB().SelectContext(ctx, &messagesDB, `SELECT * FROM weekday_notification_messages WHERE weekday_id = $1 ORDER BY chat_id, thread_id`, weekdayID); err != nil {
+		slog.Error("Error listing weekday notification messages", "error", err)
+		return nil, err
+	}
+
+	messages := make([]weekday.NotificationMessage, 0, len(messagesDB))
+	for _, m := range messagesDB {
+		messages = append(messages, weekday.NotificationMessage{
+			WeekdayID: m.WeekdayID,
+			Recipient: weekday.Recipient{ChatID: m.ChatID, ThreadID: m.ThreadID},
+			MessageID: m.MessageID,
+			Text:      m.Text,
+			Attempts:  m.Attempts,
+			LastError: m.LastError,
+		})
+	}
+
+	return messages, nil
+}
+
+func (r *WeekdayPostgresRepository) SetNotificationMessage(ctx context.Context, m *weekday.NotificationMessage) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		INSERT INTO weekday_notification_messages (weekday_id, chat_id, thread_id, message_id, text, attempts, last_error, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (weekday_id, chat_id, thread_id) DO UPDATE
+		SET
+			message_id = EXCLUDED.message_id,
+			text       = EXCLUDED.text,
+			attempts   = EXCLUDED.attempts,
+			last_error = EXCLUDED.last_error,
+			updated_at = EXCLUDED.updated_at
+	`, m.WeekdayID, m.ChatID, m.ThreadID, m.MessageID, m.Text, m.Attempts, m.LastError); err != nil {
+		slog.Error("Error saving weekday notification message", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *WeekdayPostgresRepository) DeleteNotificationMessage(ctx context.Context, m *weekday.NotificationMessage) error {
+	if _, err := r.DB().ExecContext(ctx, `DELETE FROM weekday_notification_messages WHERE weekday_id = $1 AND chat_id = $2 AND thread_id = $3`, m.WeekdayID, m.ChatID, m.ThreadID); err != nil {
+		slog.Error("Error deleting weekday notification message", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *WeekdayPostgresRepository) DeleteWeekday(ctx context.Context, weekdayID uuid.UUID) error {
+	if _, err := r.DB().ExecContext(ctx, `DELETE FROM weekdays WHERE weekday_id = $1`, weekdayID); err != nil {
+		slog.Error("Error deleting weekday", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+type subscriptionDB struct {
+	ID        int64     `db:"subscription_id"`
+	ChatID    int64     `db:"chat_id"`
+	ThreadID  int64     `db:"thread_id"`
+	Direction string    `db:"direction"`
+	Title     string    `db:"title"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (r *WeekdayPostgresRepository) ListNotificationSubscriptions(ctx context.Context) ([]weekday.NotificationSubscription, error) {
+	subscriptionsDB := []subscriptionDB{}
+	if err := r.DB().SelectContext(ctx, &subscriptionsDB, `SELECT * FROM weekday_notification_subscriptions ORDER BY direction, subscription_id`); err != nil {
+		slog.Error("Error listing weekday notification subscriptions", "error", err)
+		return nil, err
+	}
+
+	subscriptions := make([]weekday.NotificationSubscription, 0, len(subscriptionsDB))
+	for _, s := range subscriptionsDB {
+		subscriptions = append(subscriptions, weekday.NotificationSubscription(s))
+	}
+
+	return subscriptions, nil
+}
+
+// CreateNotificationSubscription сохраняет подписку и заполняет ее ID. Повторная подписка того же чата только меняет название.
+func (r *WeekdayPostgresRepository) CreateNotificationSubscription(ctx context.Context, s *weekday.NotificationSubscription) error {
+	row := subscriptionDB{}
+	if err := r.DB().GetContext(ctx, &row, `
+		INSERT INTO weekday_notification_subscriptions (chat_id, thread_id, direction, title)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (chat_id, thread_id, direction) DO UPDATE SET title = EXCLUDED.title
+		RETURNING *
+	`, s.ChatID, s.ThreadID, s.Direction, s.Title); err != nil {
+		slog.Error("Error creating weekday notification subscription", "error", err)
+		return err
+	}
+
+	*s = weekday.NotificationSubscription(row)
+	return nil
+}
+
+func (r *WeekdayPostgresRepository) DeleteNotificationSubscription(ctx context.Context, subscriptionID int64) error {
+	if _, err := r.DB().ExecContext(ctx, `DELETE FROM weekday_notification_subscriptions WHERE subscription_id = $1`, subscriptionID); err != nil {
+		slog.Error("Error deleting weekday notification subscription", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+type directionLeadDB struct {
+	Direction  string    `db:"direction"`
+	EmployeeID string    `db:"employee_id"`
+	Username   string    `db:"username"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func (r *WeekdayPostgresRepository) ListDirectionLeads(ctx context.Context) ([]weekday.DirectionLead, error) {
+	leadsDB := []directionLeadDB{}
+	if err := r.DB().SelectContext(ctx, &leadsDB, `
+		SELECT direction_leads.direction, direction_leads.employee_id, COALESCE(employees.username, '') AS username, direction_leads.updated_at
+		FROM direction_leads
+		LEFT JOIN employees ON employees.employee_id = direction_leads.employee_id
+		ORDER BY direction_leads.direction
+	`); err != nil {
+		slog.Error("Error listing direction leads", "error", err)
+		return nil, err
+	}
+
+	leads := make([]weekday.DirectionLead, 0, len(leadsDB))
+	for _, l := range leadsDB {
+		leads = append(leads, weekday.DirectionLead(l))
+	}
+
+	return leads, nil
+}
+
+// SetDirectionLead назначает руководителя направления. Сотрудник ищется по employee_id.
+func (r *WeekdayPostgresRepository) SetDirectionLead(ctx context.Context, lead *weekday.DirectionLead) error {
+	row := directionLeadDB{}
+	err := r.DB().GetContext(ctx, &row, `
+		WITH lead AS (
+			INSERT INTO direction_leads (direction, employee_id, updated_at)
+			SELECT $1, employee_id, NOW() FROM employees WHERE employee_id = $2
+			ON CONFLICT (direction) DO UPDATE SET employee_id = EXCLUDED.employee_id, updated_at = EXCLUDED.updated_at
+			RETURNING *
+		)
+		SELECT lead.direction, lead.employee_id, employees.username, lead.updated_at
+		FROM lead
+		JOIN employees ON employees.employee_id = lead.employee_id
+	`, lead.Direction, lead.EmployeeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.Join(weekday.ErrInvalidDirectionLead, errors.New("employee not found"))
+	}
+	if err != nil {
+		slog.Error("Error setting direction lead", "error", err)
+		return err
+	}
+
+	*lead = weekday.DirectionLead(row)
+	return nil
+}
+
+func (r *WeekdayPostgresRepository) DeleteDirectionLead(ctx context.Context, direction string) error {
+	if _, err := r.DB().ExecContext(ctx, `DELETE FROM direction_leads WHERE direction = $1`, direction); err != nil {
+		slog.Error("Error deleting direction lead", "error", err)
+		return err
+	}
+
+	return nil
+}
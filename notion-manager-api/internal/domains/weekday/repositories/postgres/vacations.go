@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/google/uuid"
+)
+
+// ListVacationEmployees возвращает сотрудников с профилем в Notion, от которого ведутся отсутствия
+func (r *WeekdayPostgresRepository) ListVacationEmployees(ctx context.Context) ([]weekday.VacationEmployee, error) {
+	rows := []struct {
+		ProfileID string `db:"profile_id"`
+		Username  string `db:"username"`
+		Status    string `db:"status"`
+		Geo       string `db:"geo"`
+	}{}
+	if err := r.DB().SelectContext(ctx, &rows, `
+		SELECT profile_id, username, status, geo FROM employees WHERE profile_id <> '' ORDER BY username
+	`); err != nil {
+		slog.Error("Error listing vacation employees", "error", err)
+		return nil, err
+	}
+
+	employees := make([]weekday.VacationEmployee, 0, len(rows))
+	for _, row := range rows {
+		id, err := uuid.Parse(row.ProfileID)
+		if err != nil {
+			continue
+		}
+		employees = append(employees, weekday.VacationEmployee{ID: id, Username: row.Username, Status: row.Status, Geo: row.Geo})
+	}
+
+	return employees, nil
+}
+
+// ListVacations возвращает отпуска, начавшиеся в периоде [from, to)
+func (r *WeekdayPostgresRepository) ListVacations(ctx context.Context, from, to time.Time) ([]weekday.Absence, error) {
+	absencesDB := []absenceDB{}
+	if err := r.DB().SelectContext(ctx, &absencesDB, `
+		SELECT weekday_id, employee_id, '' AS username, '' AS direction, category, start_time, end_time, reason
+		FROM weekdays
+		WHERE category = $1 AND start_time >= $2 AND start_time < $3
+		ORDER BY start_time
+	`, string(weekday.CategoryVacation), from, to); err != nil {
+		slog.Error("Error listing vacations", "error", err)
+		return nil, err
+	}
+
+	vacations := make([]weekday.Absence, 0, len(absencesDB))
+	for _, a := range absencesDB {
+		vacation := weekday.Absence{
+			WeekdayID:  a.WeekdayID,
+			EmployeeID: a.EmployeeID,
+			Category:   weekday.Category(a.Category),
+			Start:      a.Start,
+			Reason:     a.Reason,
+		}
+		if a.End.After(a.Start) {
+			vacation.End = a.End
+		}
+		vacations = append(vacations, vacation)
+	}
+
+	return vacations, nil
+}
+
+// GetVacationOpeningBalances возвращает остатки отпуска на начало учета по сотрудникам
+func (r *WeekdayPostgresRepository) GetVacationOpeningBalances(ctx context.Context) (map[uuid.UUID]float64, error) {
+	rows := []struct {
+		EmployeeID uuid.UUID `db:"employee_id"`
+		Days       float64   `db:"days"`
+	}{}
+	if err := r.DB().SelectContext(ctx, &rows, `SELECT employee_id, days FROM vacation_opening_balances`); err != nil {
+		slog.Error("Error listing vacation opening balances", "error", err)
+		return nil, err
+	}
+
+	balances := make(map[uuid.UUID]float64, len(rows))
+	for _, row := range rows {
+		balances[row.EmployeeID] = row.Days
+	}
+
+	return balances, nil
+}
+
+func (r *WeekdayPostgresRepository) SetVacationOpeningBalance(ctx context.Context, employeeID uuid.UUID, days float64) error {
+	if _, err := r.DB().ExecContext(ctx, `
+		INSERT INTO vacation_opening_balances (employee_id, days, updated_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (employee_id) DO UPDATE SET days = EXCLUDED.days, updated_at = EXCLUDED.updated_at
+	`, employeeID, days); err != nil {
+		slog.Error("Error setting vacation opening balance", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetEmployeeGeo возвращает geo сотрудника по employee_id или profile_id, пустую строку - если сотрудник не найден
+func (r *WeekdayPostgresRepository) GetEmployeeGeo(ctx context.Context, employeeID uuid.UUID) (string, error) {
+	geo := ""
+	if err := r.DB().GetContext(ctx, &geo, `
+		SELECT geo FROM employees WHERE employee_id::text = $1 OR profile_id = $1 LIMIT 1
+	`, employeeID.String()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		slog.Error("Error getting employee geo", "error", err)
+		return "", err
+	}
+
+	return geo, nil
+}
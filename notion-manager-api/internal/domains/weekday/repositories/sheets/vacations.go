@@ -0,0 +1,54 @@
+package sheets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/Corray333/employee_dashboard/internal/export"
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+	"github.com/spf13/viper"
+)
+
+var vacationHeader = []string{"Сотрудник", "Статус", "Перенесено", "Начислено", "Использовано", "Запланировано", "Остаток", "ID сотрудника"}
+
+// UpdateSheetsVacationBalances выгружает остатки отпусков за год в отдельный лист года, создавая его при первой выгрузке
+func (r *WeekdaySheetsRepository) UpdateSheetsVacationBalances(ctx context.Context, sheetID string, year int, balances []weekday.VacationBalance) error {
+	table := export.Table{Name: fmt.Sprintf("%s %d", viper.GetString("sheets.vacations_sheet"), year), Header: vacationHeader}
+
+	if _, ok := r.vacationsSink.(*export.SheetsSink); ok {
+		if _, err := r.client.SheetID(ctx, sheetID, table.Name); err != nil {
+			if !errors.Is(err, gsheets.ErrSheetNotFound) {
+				return err
+			}
+			if err := r.client.AddSheet(ctx, sheetID, table.Name, table.Header); err != nil {
+				return err
+			}
+		}
+	}
+
+	rows := make([][]interface{}, 0, len(balances))
+	for _, b := range balances {
+		rows = append(rows, []interface{}{
+			b.Employee.Username,
+			b.Employee.Status,
+			b.CarriedOver,
+			b.Accrued,
+			b.Used,
+			b.Planned,
+			b.Remaining,
+			b.Employee.ID.String(),
+		})
+	}
+
+	return r.vacationsSink.WriteTable(ctx, sheetID, table, rows, vacationRowKey)
+}
+
+// vacationRowKey - строки узнаются по ID сотрудника в последнем столбце, чтобы смена имени не разрывала историю
+func vacationRowKey(row []interface{}) string {
+	if len(row) < len(vacationHeader) {
+		return ""
+	}
+	return fmt.Sprint(row[len(vacationHeader)-1])
+}
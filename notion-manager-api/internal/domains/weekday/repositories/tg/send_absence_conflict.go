@@ -0,0 +1,42 @@
+package tg
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// SendAbsenceConflict предупреждает получателей о конфликте отсутствий
+func (r *WeekdayPostgresRepository) SendAbsenceConflict(ctx context.Context, conflict *weekday.AbsenceConflict, recipients []weekday.Recipient) error {
+	scope := "направлении"
+	if conflict.Scope == weekday.ConflictScopeProject {
+		scope = "проекте"
+	}
+
+	period := conflict.Start.Format("02.01.2006")
+	if conflict.End.After(conflict.Start) {
+		period += " - " + conflict.End.Format("02.01.2006")
+	}
+
+	text := fmt.Sprintf("<b>Одновременно отсутствуют %d из допустимых %d в %s %s</b>\n%s: %s",
+		len(conflict.Employees), conflict.Limit, scope, html.EscapeString(conflict.Name),
+		period, html.EscapeString(strings.Join(conflict.Employees, ", ")))
+
+	var lastErr error
+	for _, recipient := range recipients {
+		if _, err := r.GetBot().SendMessage(recipient.ChatID, text, &gotgbot.SendMessageOpts{
+			ParseMode:       gotgbot.ParseModeHTML,
+			MessageThreadId: recipient.ThreadID,
+		}); err != nil {
+			slog.Error("Error sending absence conflict", "error", err, "chat_id", recipient.ChatID)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
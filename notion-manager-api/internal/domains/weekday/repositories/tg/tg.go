@@ -2,10 +2,12 @@ package tg
 
 import (
 	"context"
+	"html"
 	"log/slog"
 
 	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
 	"github.com/Corray333/employee_dashboard/internal/telegram"
+	"github.com/PaulSonOfLars/gotgbot/v2"
 )
 
 type WeekdayPostgresRepository struct {
@@ -16,28 +18,51 @@ func NewWeekdayTelegramRepository(client *telegram.TelegramClient) *WeekdayPostg
 	return &WeekdayPostgresRepository{client}
 }
 
-var managerIDs = []int64{
-	377742748,
-	373352303,
-	56218566,
-	795836353,
+// SendWeekdayNotification отправляет уведомление об отсутствии получателю и возвращает ID сообщения
+func (r *WeekdayPostgresRepository) SendWeekdayNotification(ctx context.Context, recipient weekday.Recipient, text string) (int64, error) {
+	msg, err := r.GetBot().SendMessage(recipient.ChatID, html.EscapeString(text), &gotgbot.SendMessageOpts{
+		ParseMode:       gotgbot.ParseModeHTML,
+		MessageThreadId: recipient.ThreadID,
+	})
+	if err != nil {
+		slog.Error("Error sending weekday notification", "error", err, "chat_id", recipient.ChatID, "thread_id", recipient.ThreadID)
+		return 0, err
+	}
+
+	return msg.MessageId, nil
+}
+
+// EditWeekdayNotification заменяет текст ранее отправленного уведомления
+func (r *WeekdayPostgresRepository) EditWeekdayNotification(ctx context.Context, message *weekday.NotificationMessage, text string) error {
+	if _, _, err := r.GetBot().EditMessageText(html.EscapeString(text), &gotgbot.EditMessageTextOpts{
+		ChatId:    message.ChatID,
+		MessageId: message.MessageID,
+		ParseMode: gotgbot.ParseModeHTML,
+	}); err != nil {
+		slog.Error("Error editing weekday notification", "error", err, "chat_id", message.ChatID, "message_id", message.MessageID)
+		return err
+	}
+
+	return nil
 }
 
-func (r *WeekdayPostgresRepository) SendWeekendNotification(ctx context.Context, weekday *weekday.Weekday) error {
-	msg := weekday.GetNotifyMsg()
-	sentMessages := map[int64]int64{}
-	for _, id := range managerIDs {
-		msg, err := r.GetBot().SendMessage(id, msg, nil)
-		if err != nil {
-			slog.Error("Error sending message", "error", err)
-			// for _, msgID := range sentMessages {
-			// 	if _, err := r.GetBot().DeleteMessage(id, msgID, nil); err != nil {
-			// 		slog.Error("Error deleting message", "error", err)
-			// 	}
-			// }
-			// return err
-		}
-		sentMessages[id] = msg.MessageId
+// CancelWeekdayNotification удаляет уведомление об отмененном отсутствии.
+// Старые сообщения бот удалить не может, поэтому их текст зачеркивается.
+func (r *WeekdayPostgresRepository) CancelWeekdayNotification(ctx context.Context, message *weekday.NotificationMessage) error {
+	_, err := r.GetBot().DeleteMessage(message.ChatID, message.MessageID, nil)
+	if err == nil {
+		return nil
 	}
+	slog.Warn("Error deleting weekday notification, striking it through", "error", err, "chat_id", message.ChatID, "message_id", message.MessageID)
+
+	if _, _, err := r.GetBot().EditMessageText("<s>"+html.EscapeString(message.Text)+"</s>\n\nОтменено", &gotgbot.EditMessageTextOpts{
+		ChatId:    message.ChatID,
+		MessageId: message.MessageID,
+		ParseMode: gotgbot.ParseModeHTML,
+	}); err != nil {
+		slog.Error("Error striking weekday notification through", "error", err, "chat_id", message.ChatID, "message_id", message.MessageID)
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type absencesLister interface {
+	ListAbsences(ctx context.Context, filter *weekday.CalendarFilter) ([]weekday.Absence, error)
+}
+
+type notifiedAbsenceConflictsLister interface {
+	ListNotifiedAbsenceConflicts(ctx context.Context, signatures []string) (map[string]bool, error)
+}
+
+type absenceConflictNotifiedSetter interface {
+	SetAbsenceConflictNotified(ctx context.Context, conflict *weekday.AbsenceConflict, notifiedAt time.Time) error
+}
+
+type absenceConflictSender interface {
+	SendAbsenceConflict(ctx context.Context, conflict *weekday.AbsenceConflict, recipients []weekday.Recipient) error
+}
+
+func absenceLimits() (weekday.AbsenceLimits, error) {
+	limits := weekday.AbsenceLimits{}
+	if err := viper.UnmarshalKey("absences.limits", &limits); err != nil {
+		return limits, err
+	}
+	return limits, nil
+}
+
+// ListAbsences возвращает календарь отсутствий за период
+func (s *WeekdayService) ListAbsences(ctx context.Context, filter *weekday.CalendarFilter) ([]weekday.Absence, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	absences, err := s.absencesLister.ListAbsences(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]weekday.Absence, 0, len(absences))
+	for i := range absences {
+		if filter.Match(&absences[i]) {
+			filtered = append(filtered, absences[i])
+		}
+	}
+
+	return filtered, nil
+}
+
+// ListAbsenceConflicts ищет дни, когда из направления или проекта отсутствует больше людей, чем разрешено.
+// Лимит считается по всем отсутствующим, поэтому фильтр по сотруднику, направлению или проекту
+// только отбирает конфликты, в которых они участвуют.
+func (s *WeekdayService) ListAbsenceConflicts(ctx context.Context, filter *weekday.CalendarFilter) ([]weekday.AbsenceConflict, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	limits, err := absenceLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	absences, err := s.absencesLister.ListAbsences(ctx, &weekday.CalendarFilter{From: filter.From, To: filter.To})
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := map[string]bool{}
+	for i := range absences {
+		if filter.EmployeeID != uuid.Nil && filter.Match(&absences[i]) {
+			usernames[absences[i].Username] = true
+		}
+	}
+
+	conflicts := []weekday.AbsenceConflict{}
+	for _, c := range weekday.DetectAbsenceConflicts(absences, limits, filter.From, filter.To, s.calendars.Default().IsWorkingDay) {
+		if filter.Direction != "" && (c.Scope != weekday.ConflictScopeDirection || c.Key != filter.Direction) {
+			continue
+		}
+		if filter.ProjectID != uuid.Nil && (c.Scope != weekday.ConflictScopeProject || c.Key != filter.ProjectID.String()) {
+			continue
+		}
+		if filter.EmployeeID != uuid.Nil && !involves(c, usernames) {
+			continue
+		}
+		conflicts = append(conflicts, c)
+	}
+
+	return conflicts, nil
+}
+
+func involves(c weekday.AbsenceConflict, usernames map[string]bool) bool {
+	for _, username := range c.Employees {
+		if usernames[username] {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyAbsenceConflicts предупреждает менеджеров о конфликтах за период, о которых еще не предупреждали.
+// Новым считается только конфликт с изменившимся составом отсутствий: сроки в подпись не входят,
+// поэтому продление уже известного конфликта повторно не рассылается.
+func (s *WeekdayService) NotifyAbsenceConflicts(ctx context.Context, from, to time.Time) ([]weekday.AbsenceConflict, error) {
+	conflicts, err := s.ListAbsenceConflicts(ctx, &weekday.CalendarFilter{From: from, To: to})
+	if err != nil || len(conflicts) == 0 {
+		return nil, err
+	}
+
+	signatures := make([]string, 0, len(conflicts))
+	for i := range conflicts {
+		signatures = append(signatures, conflicts[i].Signature())
+	}
+	notified, err := s.notifiedAbsenceConflictsLister.ListNotifiedAbsenceConflicts(ctx, signatures)
+	if err != nil {
+		return nil, err
+	}
+
+	sent := []weekday.AbsenceConflict{}
+	for i := range conflicts {
+		c := &conflicts[i]
+		if notified[c.Signature()] {
+			continue
+		}
+
+		recipients, err := s.absenceConflictRecipients(ctx, c)
+		if err != nil {
+			return sent, err
+		}
+		if len(recipients) == 0 {
+			slog.Warn("No recipients for absence conflict", "scope", c.Scope, "key", c.Key)
+			continue
+		}
+
+		if err := s.absenceConflictSender.SendAbsenceConflict(ctx, c, recipients); err != nil {
+			slog.Error("Error sending absence conflict", "error", err, "scope", c.Scope, "key", c.Key)
+			continue
+		}
+		if err := s.absenceConflictNotifiedSetter.SetAbsenceConflictNotified(ctx, c, time.Now()); err != nil {
+			return sent, err
+		}
+		sent = append(sent, *c)
+	}
+
+	return sent, nil
+}
+
+// checkAbsenceConflicts проверяет конфликты в периодах синхронизированных отсутствий
+func (s *WeekdayService) checkAbsenceConflicts(ctx context.Context, weekdays []weekday.Weekday) error {
+	var from, to time.Time
+	for i := range weekdays {
+		a := weekday.Absence{Start: weekdays[i].PeriodStart, End: weekdays[i].PeriodEnd}
+		first, last := a.Days()
+		if from.IsZero() || first.Before(from) {
+			from = first
+		}
+		if last.After(to) {
+			to = last
+		}
+	}
+	if from.IsZero() {
+		return nil
+	}
+
+	// Прошедшие конфликты уже неактуальны
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if to.Before(today) {
+		return nil
+	}
+	if from.Before(today) {
+		from = today
+	}
+
+	_, err := s.NotifyAbsenceConflicts(ctx, from, to)
+	return err
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/calendar"
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+)
+
+// GetWorkingNorm возвращает рабочие дни и норму часов за период по календарю для geo сотрудника
+func (s *WeekdayService) GetWorkingNorm(geo string, from, to time.Time) (calendar.Norm, error) {
+	if from.IsZero() || to.Before(from) {
+		return calendar.Norm{}, weekday.ErrInvalidPeriod
+	}
+	return s.calendars.ForGeo(geo).Norm(from, to), nil
+}
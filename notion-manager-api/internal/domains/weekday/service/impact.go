@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type impactTasksLister interface {
+	ListImpactTasks(ctx context.Context, weekdayID uuid.UUID, first, last time.Time) ([]weekday.ImpactTask, error)
+}
+
+type substitutesLister interface {
+	ListSubstitutes(ctx context.Context, weekdayID uuid.UUID, first, last time.Time, excludedStatuses []string, limit int) ([]weekday.Substitute, error)
+}
+
+type absenceImpactReportsLister interface {
+	ListAbsenceImpactReports(ctx context.Context, weekdayID uuid.UUID) ([]weekday.AbsenceImpactReport, error)
+}
+
+type absenceImpactReportSetter interface {
+	SetAbsenceImpactReport(ctx context.Context, report *weekday.AbsenceImpactReport) error
+}
+
+type absenceImpactReportDeleter interface {
+	DeleteAbsenceImpactReport(ctx context.Context, report *weekday.AbsenceImpactReport) error
+}
+
+// ListAbsenceImpact возвращает задачи, которые попадают на отсутствие, по проектам вместе с возможными заменами
+func (s *WeekdayService) ListAbsenceImpact(ctx context.Context, weekdayID uuid.UUID) ([]weekday.AbsenceImpact, error) {
+	weekdays, err := s.weekdaywLister.ListWeekdays(ctx, &weekday.Filter{ID: weekdayID})
+	if err != nil {
+		return nil, err
+	}
+	if len(weekdays) == 0 {
+		return nil, weekday.ErrWeekdayNotFound
+	}
+
+	return s.absenceImpact(ctx, &weekdays[0])
+}
+
+func (s *WeekdayService) absenceImpact(ctx context.Context, w *weekday.Weekday) ([]weekday.AbsenceImpact, error) {
+	first, last := (&weekday.Absence{Start: w.PeriodStart, End: w.PeriodEnd}).Days()
+
+	tasks, err := s.impactTasksLister.ListImpactTasks(ctx, w.ID, first, last)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return []weekday.AbsenceImpact{}, nil
+	}
+
+	substitutes, err := s.substitutesLister.ListSubstitutes(ctx, w.ID, first, last,
+		viper.GetStringSlice("absence_impact.excluded_statuses"), viper.GetInt("absence_impact.max_substitutes"))
+	if err != nil {
+		return nil, err
+	}
+
+	return weekday.ComputeAbsenceImpact(w, tasks, substitutes), nil
+}
+
+// reportAbsenceImpact сообщает менеджерам проектов, какие задачи попадают на отсутствие.
+// При изменении отсутствия отчет редактируется, а если задачи проекта на него больше не попадают - отзывается.
+func (s *WeekdayService) reportAbsenceImpact(ctx context.Context, w *weekday.Weekday) error {
+	// Прошедшее отсутствие уже ничего не блокирует
+	_, last := (&weekday.Absence{Start: w.PeriodStart, End: w.PeriodEnd}).Days()
+	if last.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		return nil
+	}
+
+	impacts, err := s.absenceImpact(ctx, w)
+	if err != nil {
+		return err
+	}
+
+	reports, err := s.absenceImpactReportsLister.ListAbsenceImpactReports(ctx, w.ID)
+	if err != nil {
+		return err
+	}
+	byProject := map[uuid.UUID]weekday.AbsenceImpactReport{}
+	for _, r := range reports {
+		byProject[r.ProjectID] = r
+	}
+
+	errs := []error{}
+	for i := range impacts {
+		impact := &impacts[i]
+		if impact.ManagerTgID == 0 {
+			slog.Warn("No manager to report absence impact", "weekday_id", w.ID, "project_id", impact.ProjectID)
+			continue
+		}
+
+		text := impact.Message()
+		report, ok := byProject[impact.ProjectID]
+		delete(byProject, impact.ProjectID)
+		if ok && report.ChatID == impact.ManagerTgID && report.Text == text {
+			continue
+		}
+
+		if ok && report.ChatID == impact.ManagerTgID {
+			err = s.weekdayNotificationEditor.EditWeekdayNotification(ctx, &report.NotificationMessage, text)
+		} else {
+			// Менеджер проекта сменился: отчет прежнему менеджеру отзывается до отправки новому,
+			// иначе запись о нем перезапишется и сообщение уже нельзя будет ни изменить, ни отозвать
+			if ok {
+				if err := s.cancelAbsenceImpactReport(ctx, &report); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+			}
+			report = weekday.AbsenceImpactReport{
+				ProjectID: impact.ProjectID,
+				NotificationMessage: weekday.NotificationMessage{
+					WeekdayID: w.ID,
+					Recipient: weekday.Recipient{ChatID: impact.ManagerTgID, Kind: weekday.RecipientProjectManager},
+				},
+			}
+			report.MessageID, err = s.weekdayNotificationSender.SendWeekdayNotification(ctx, report.Recipient, text)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		report.Text = text
+		if err := s.absenceImpactReportSetter.SetAbsenceImpactReport(ctx, &report); err != nil {
+			return err
+		}
+	}
+
+	for _, report := range byProject {
+		if err := s.cancelAbsenceImpactReport(ctx, &report); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *WeekdayService) cancelAbsenceImpactReport(ctx context.Context, report *weekday.AbsenceImpactReport) error {
+	if err := s.weekdayNotificationCanceller.CancelWeekdayNotification(ctx, &report.NotificationMessage); err != nil {
+		return err
+	}
+	return s.absenceImpactReportDeleter.DeleteAbsenceImpactReport(ctx, report)
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type weekdayRecipientsLister interface {
+	ListWeekdayRecipients(ctx context.Context, weekdayID uuid.UUID) ([]weekday.Recipient, error)
+}
+
+type directionRecipientsLister interface {
+	ListDirectionRecipients(ctx context.Context, direction string) ([]weekday.Recipient, error)
+}
+
+type notificationMessagesLister interface {
+	ListNotificationMessages(ctx context.Context, weekdayID uuid.UUID) ([]weekday.NotificationMessage, error)
+}
+
+type notificationMessageSetter interface {
+	SetNotificationMessage(ctx context.Context, message *weekday.NotificationMessage) error
+}
+
+type notificationMessageDeleter interface {
+	DeleteNotificationMessage(ctx context.Context, message *weekday.NotificationMessage) error
+}
+
+type weekdayDeleter interface {
+	DeleteWeekday(ctx context.Context, weekdayID uuid.UUID) error
+}
+
+type notificationSubscriptionsLister interface {
+	ListNotificationSubscriptions(ctx context.Context) ([]weekday.NotificationSubscription, error)
+}
+
+type notificationSubscriptionCreator interface {
+	CreateNotificationSubscription(ctx context.Context, subscription *weekday.NotificationSubscription) error
+}
+
+type notificationSubscriptionDeleter interface {
+	DeleteNotificationSubscription(ctx context.Context, subscriptionID int64) error
+}
+
+type directionLeadsLister interface {
+	ListDirectionLeads(ctx context.Context) ([]weekday.DirectionLead, error)
+}
+
+type directionLeadSetter interface {
+	SetDirectionLead(ctx context.Context, lead *weekday.DirectionLead) error
+}
+
+type directionLeadDeleter interface {
+	DeleteDirectionLead(ctx context.Context, direction string) error
+}
+
+type weekdayNotificationSender interface {
+	SendWeekdayNotification(ctx context.Context, recipient weekday.Recipient, text string) (int64, error)
+}
+
+type weekdayNotificationEditor interface {
+	EditWeekdayNotification(ctx context.Context, message *weekday.NotificationMessage, text string) error
+}
+
+type weekdayNotificationCanceller interface {
+	CancelWeekdayNotification(ctx context.Context, message *weekday.NotificationMessage) error
+}
+
+// deliverWeekdayNotification доводит text до всех получателей отсутствия: новым отправляет сообщение,
+// уже получившим - редактирует отправленное. Результат по каждому получателю сохраняется сразу,
+// поэтому при повторе сообщение не дублируется. Возвращает ErrNotificationNotDelivered, если кому-то доставить не удалось.
+func (s *WeekdayService) deliverWeekdayNotification(ctx context.Context, w *weekday.Weekday, text string) error {
+	recipients, err := s.weekdayRecipientsLister.ListWeekdayRecipients(ctx, w.ID)
+	if err != nil {
+		return err
+	}
+	sent, err := s.notificationMessagesLister.ListNotificationMessages(ctx, w.ID)
+	if err != nil {
+		return err
+	}
+
+	plan := weekday.PlanNotification(w.ID, recipients, sent, text, viper.GetInt("weekday_notifications.max_attempts"))
+	failed := 0
+	for i := range plan {
+		m := &plan[i]
+
+		if m.MessageID == 0 {
+			m.MessageID, err = s.weekdayNotificationSender.SendWeekdayNotification(ctx, m.Recipient, text)
+		} else {
+			err = s.weekdayNotificationEditor.EditWeekdayNotification(ctx, m, text)
+		}
+
+		m.Text = text
+		if err != nil {
+			failed++
+			m.Attempts++
+			m.LastError = err.Error()
+		} else {
+			m.Attempts = 0
+			m.LastError = ""
+		}
+
+		if err := s.notificationMessageSetter.SetNotificationMessage(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: failed %d of %d", weekday.ErrNotificationNotDelivered, failed, len(plan))
+	}
+	return nil
+}
+
+// DeleteWeekday удаляет отсутствие, удаленное в Notion, и отзывает уведомления и отчеты менеджерам о нем.
+// Уведомления, которые отозвать не удалось, остаются, чтобы повторить попытку при следующем удалении.
+func (s *WeekdayService) DeleteWeekday(ctx context.Context, weekdayID uuid.UUID) error {
+	messages, err := s.notificationMessagesLister.ListNotificationMessages(ctx, weekdayID)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for i := range messages {
+		m := &messages[i]
+		if m.MessageID != 0 {
+			if err := s.weekdayNotificationCanceller.CancelWeekdayNotification(ctx, m); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if err := s.notificationMessageDeleter.DeleteNotificationMessage(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	reports, err := s.absenceImpactReportsLister.ListAbsenceImpactReports(ctx, weekdayID)
+	if err != nil {
+		return err
+	}
+	for i := range reports {
+		if err := s.cancelAbsenceImpactReport(ctx, &reports[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := s.weekdayDeleter.DeleteWeekday(ctx, weekdayID); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+// absenceConflictRecipients возвращает получателей предупреждения о конфликте: менеджера проекта,
+// а если его нет - руководителя направления и подписанные чаты
+func (s *WeekdayService) absenceConflictRecipients(ctx context.Context, conflict *weekday.AbsenceConflict) ([]weekday.Recipient, error) {
+	if conflict.ManagerTgID != 0 {
+		return []weekday.Recipient{{ChatID: conflict.ManagerTgID, Kind: weekday.RecipientProjectManager}}, nil
+	}
+
+	direction := ""
+	if conflict.Scope == weekday.ConflictScopeDirection {
+		direction = conflict.Key
+	}
+	return s.directionRecipientsLister.ListDirectionRecipients(ctx, direction)
+}
+
+func (s *WeekdayService) ListNotificationSubscriptions(ctx context.Context) ([]weekday.NotificationSubscription, error) {
+	return s.notificationSubscriptionsLister.ListNotificationSubscriptions(ctx)
+}
+
+func (s *WeekdayService) CreateNotificationSubscription(ctx context.Context, subscription *weekday.NotificationSubscription) error {
+	if err := subscription.Validate(); err != nil {
+		return err
+	}
+	return s.notificationSubscriptionCreator.CreateNotificationSubscription(ctx, subscription)
+}
+
+func (s *WeekdayService) DeleteNotificationSubscription(ctx context.Context, subscriptionID int64) error {
+	return s.notificationSubscriptionDeleter.DeleteNotificationSubscription(ctx, subscriptionID)
+}
+
+func (s *WeekdayService) ListDirectionLeads(ctx context.Context) ([]weekday.DirectionLead, error) {
+	return s.directionLeadsLister.ListDirectionLeads(ctx)
+}
+
+func (s *WeekdayService) SetDirectionLead(ctx context.Context, lead *weekday.DirectionLead) error {
+	if lead.Direction == "" || lead.EmployeeID == "" {
+		return errors.Join(weekday.ErrInvalidDirectionLead, errors.New("direction and employee_id are required"))
+	}
+	if _, err := uuid.Parse(lead.EmployeeID); err != nil {
+		return errors.Join(weekday.ErrInvalidDirectionLead, errors.New("employee_id must be a uuid"))
+	}
+	return s.directionLeadSetter.SetDirectionLead(ctx, lead)
+}
+
+func (s *WeekdayService) DeleteDirectionLead(ctx context.Context, direction string) error {
+	return s.directionLeadDeleter.DeleteDirectionLead(ctx, direction)
+}
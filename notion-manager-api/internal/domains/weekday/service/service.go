@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/Corray333/employee_dashboard/internal/calendar"
 	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
 	"github.com/Corray333/employee_dashboard/internal/postgres"
 	"github.com/spf13/viper"
@@ -14,10 +15,46 @@ type WeekdayService struct {
 	weekdayLastUpdateTimeGetter weekdayLastUpdateTimeGetter
 	weekdaysNotionLister        weekdaysNotionLister
 	transactioner               postgres.Transactioner
-	newWeekdayNotifier          newWeekdayNotifier
 	weekdaywLister              weekdaywLister
 	weekdayNotifiedMaker        weekdayNotifiedMaker
 	sheetsRepository            sheetsRepository
+	weekdayGetter               weekdayGetter
+	changesRecorder             changesRecorder
+
+	absencesLister                 absencesLister
+	notifiedAbsenceConflictsLister notifiedAbsenceConflictsLister
+	absenceConflictNotifiedSetter  absenceConflictNotifiedSetter
+	absenceConflictSender          absenceConflictSender
+
+	weekdayRecipientsLister         weekdayRecipientsLister
+	directionRecipientsLister       directionRecipientsLister
+	notificationMessagesLister      notificationMessagesLister
+	notificationMessageSetter       notificationMessageSetter
+	notificationMessageDeleter      notificationMessageDeleter
+	weekdayDeleter                  weekdayDeleter
+	notificationSubscriptionsLister notificationSubscriptionsLister
+	notificationSubscriptionCreator notificationSubscriptionCreator
+	notificationSubscriptionDeleter notificationSubscriptionDeleter
+	directionLeadsLister            directionLeadsLister
+	directionLeadSetter             directionLeadSetter
+	directionLeadDeleter            directionLeadDeleter
+	weekdayNotificationSender       weekdayNotificationSender
+	weekdayNotificationEditor       weekdayNotificationEditor
+	weekdayNotificationCanceller    weekdayNotificationCanceller
+
+	impactTasksLister          impactTasksLister
+	substitutesLister          substitutesLister
+	absenceImpactReportsLister absenceImpactReportsLister
+	absenceImpactReportSetter  absenceImpactReportSetter
+	absenceImpactReportDeleter absenceImpactReportDeleter
+
+	vacationEmployeesLister       vacationEmployeesLister
+	vacationsLister               vacationsLister
+	vacationOpeningBalancesGetter vacationOpeningBalancesGetter
+	vacationOpeningBalanceSetter  vacationOpeningBalanceSetter
+	sheetsVacationBalancesUpdater sheetsVacationBalancesUpdater
+	employeeGeoGetter             employeeGeoGetter
+	calendars                     *calendar.Calendars
 }
 
 type postgresRepository interface {
@@ -26,41 +63,71 @@ type postgresRepository interface {
 	weekdayLastUpdateTimeGetter
 	weekdaywLister
 	weekdayNotifiedMaker
+	weekdayGetter
+	absencesLister
+	notifiedAbsenceConflictsLister
+	absenceConflictNotifiedSetter
+	vacationEmployeesLister
+	vacationsLister
+	vacationOpeningBalancesGetter
+	vacationOpeningBalanceSetter
+	employeeGeoGetter
+	weekdayRecipientsLister
+	directionRecipientsLister
+	notificationMessagesLister
+	notificationMessageSetter
+	notificationMessageDeleter
+	weekdayDeleter
+	notificationSubscriptionsLister
+	notificationSubscriptionCreator
+	notificationSubscriptionDeleter
+	directionLeadsLister
+	directionLeadSetter
+	directionLeadDeleter
+	impactTasksLister
+	substitutesLister
+	absenceImpactReportsLister
+	absenceImpactReportSetter
+	absenceImpactReportDeleter
 }
 type notionRepository interface {
 	weekdaysNotionLister
 }
 
 type telegramRepository interface {
-	newWeekdayNotifier
+	weekdayNotificationSender
+	weekdayNotificationEditor
+	weekdayNotificationCanceller
+	absenceConflictSender
 }
 
 type option func(*WeekdayService)
 
 type sheetsRepository interface {
 	UpdateSheetsWeekdays(ctx context.Context, sheetID string, weekdays []weekday.Weekday) error
+	sheetsVacationBalancesUpdater
 }
 
-func (s *WeekdayService) AcceptUpdate(ctx context.Context) {
-	go s.updateSheets(ctx)
-}
-
-func (s *WeekdayService) updateSheets(ctx context.Context) {
+func (s *WeekdayService) UpdateSheets(ctx context.Context) error {
 
 	tasks, err := s.weekdaywLister.ListWeekdays(ctx, &weekday.Filter{})
 	if err != nil {
 		slog.Error("Error getting tasks", "error", err)
-		return
+		return err
 	}
 
 	if err := s.sheetsRepository.UpdateSheetsWeekdays(ctx, viper.GetString("sheets.id"), tasks); err != nil {
 		slog.Error("Error updating sheets", "error", err)
-		return
+		return err
 	}
+
+	return nil
 }
 
 func NewWeekdayService(opts ...option) *WeekdayService {
-	service := &WeekdayService{}
+	service := &WeekdayService{
+		calendars: calendar.New(nil, nil, ""),
+	}
 
 	for _, opt := range opts {
 		opt(service)
@@ -76,12 +143,39 @@ func WithPostgresRepository(repository postgresRepository) option {
 		s.weekdayLastUpdateTimeGetter = repository
 		s.weekdaywLister = repository
 		s.weekdayNotifiedMaker = repository
+		s.weekdayGetter = repository
+		s.absencesLister = repository
+		s.notifiedAbsenceConflictsLister = repository
+		s.absenceConflictNotifiedSetter = repository
+		s.vacationEmployeesLister = repository
+		s.vacationsLister = repository
+		s.vacationOpeningBalancesGetter = repository
+		s.vacationOpeningBalanceSetter = repository
+		s.employeeGeoGetter = repository
+		s.weekdayRecipientsLister = repository
+		s.directionRecipientsLister = repository
+		s.notificationMessagesLister = repository
+		s.notificationMessageSetter = repository
+		s.notificationMessageDeleter = repository
+		s.weekdayDeleter = repository
+		s.notificationSubscriptionsLister = repository
+		s.notificationSubscriptionCreator = repository
+		s.notificationSubscriptionDeleter = repository
+		s.directionLeadsLister = repository
+		s.directionLeadSetter = repository
+		s.directionLeadDeleter = repository
+		s.impactTasksLister = repository
+		s.substitutesLister = repository
+		s.absenceImpactReportsLister = repository
+		s.absenceImpactReportSetter = repository
+		s.absenceImpactReportDeleter = repository
 	}
 }
 
 func WithSheetsRepository(repository sheetsRepository) option {
 	return func(s *WeekdayService) {
 		s.sheetsRepository = repository
+		s.sheetsVacationBalancesUpdater = repository
 	}
 }
 
@@ -93,7 +187,22 @@ func WithNotionRepository(repository notionRepository) option {
 
 func WithTelegramRepository(repository telegramRepository) option {
 	return func(s *WeekdayService) {
-		s.newWeekdayNotifier = repository
+		s.weekdayNotificationSender = repository
+		s.weekdayNotificationEditor = repository
+		s.weekdayNotificationCanceller = repository
+		s.absenceConflictSender = repository
+	}
+}
+
+func WithCalendars(calendars *calendar.Calendars) option {
+	return func(s *WeekdayService) {
+		s.calendars = calendars
+	}
+}
+
+func WithChangesRecorder(recorder changesRecorder) option {
+	return func(s *WeekdayService) {
+		s.changesRecorder = recorder
 	}
 }
 
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type vacationEmployeesLister interface {
+	ListVacationEmployees(ctx context.Context) ([]weekday.VacationEmployee, error)
+}
+
+type vacationsLister interface {
+	ListVacations(ctx context.Context, from, to time.Time) ([]weekday.Absence, error)
+}
+
+type vacationOpeningBalancesGetter interface {
+	GetVacationOpeningBalances(ctx context.Context) (map[uuid.UUID]float64, error)
+}
+
+type vacationOpeningBalanceSetter interface {
+	SetVacationOpeningBalance(ctx context.Context, employeeID uuid.UUID, days float64) error
+}
+
+type employeeGeoGetter interface {
+	GetEmployeeGeo(ctx context.Context, employeeID uuid.UUID) (string, error)
+}
+
+type sheetsVacationBalancesUpdater interface {
+	UpdateSheetsVacationBalances(ctx context.Context, sheetID string, year int, balances []weekday.VacationBalance) error
+}
+
+func vacationRules() (weekday.VacationRules, error) {
+	rules := weekday.VacationRules{}
+	if err := viper.UnmarshalKey("vacations", &rules); err != nil {
+		return rules, err
+	}
+	return rules, nil
+}
+
+// ListVacationBalances считает остатки отпусков на год. employeeID - profile_id сотрудника, uuid.Nil - все сотрудники.
+func (s *WeekdayService) ListVacationBalances(ctx context.Context, year int, employeeID uuid.UUID) ([]weekday.VacationBalance, error) {
+	rules, err := vacationRules()
+	if err != nil {
+		return nil, err
+	}
+	if year < rules.SinceYear {
+		return nil, fmt.Errorf("%w: vacations are tracked since %d", weekday.ErrInvalidVacationYear, rules.SinceYear)
+	}
+
+	employees, err := s.vacationEmployeesLister.ListVacationEmployees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vacations, err := s.vacationsLister.ListVacations(ctx,
+		time.Date(rules.SinceYear-1, time.December, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return nil, err
+	}
+	byEmployee := map[uuid.UUID][]weekday.Absence{}
+	for _, v := range vacations {
+		byEmployee[v.EmployeeID] = append(byEmployee[v.EmployeeID], v)
+	}
+
+	openings, err := s.vacationOpeningBalancesGetter.GetVacationOpeningBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	balances := []weekday.VacationBalance{}
+	for _, e := range employees {
+		if employeeID != uuid.Nil && e.ID != employeeID {
+			continue
+		}
+
+		rule := rules.Rule(e.Status)
+		usage := weekday.VacationDays(byEmployee[e.ID], now, s.calendars.ForGeo(e.Geo).IsWorkingDay)
+		years := weekday.ComputeVacationBalance(rule, rules.SinceYear, openings[e.ID], usage, year, now)
+
+		balances = append(balances, weekday.VacationBalance{
+			Employee:     e,
+			Rule:         rule,
+			VacationYear: years[len(years)-1],
+			History:      years[:len(years)-1],
+		})
+	}
+
+	return balances, nil
+}
+
+func (s *WeekdayService) SetVacationOpeningBalance(ctx context.Context, employeeID uuid.UUID, days float64) error {
+	return s.vacationOpeningBalanceSetter.SetVacationOpeningBalance(ctx, employeeID, days)
+}
+
+// ExportVacationBalances выгружает остатки отпусков за год в лист этого года
+func (s *WeekdayService) ExportVacationBalances(ctx context.Context, year int) error {
+	balances, err := s.ListVacationBalances(ctx, year, uuid.Nil)
+	if err != nil {
+		return err
+	}
+
+	return s.sheetsVacationBalancesUpdater.UpdateSheetsVacationBalances(ctx, viper.GetString("sheets.id"), year, balances)
+}
+
+// vacationWarning возвращает предупреждение, если отпуск не укладывается в остаток любого из годов,
+// на которые он приходится: отпуск на стыке лет расходует дни обоих
+func (s *WeekdayService) vacationWarning(ctx context.Context, w *weekday.Weekday) (string, error) {
+	if w.Category != weekday.CategoryVacation {
+		return "", nil
+	}
+
+	rules, err := vacationRules()
+	if err != nil {
+		return "", err
+	}
+
+	firstYear, lastYear := w.PeriodStart.Year(), w.PeriodStart.Year()
+	if w.PeriodEnd.After(w.PeriodStart) {
+		lastYear = w.PeriodEnd.Year()
+	}
+	if lastYear < rules.SinceYear {
+		return "", nil
+	}
+	firstYear = max(firstYear, rules.SinceYear)
+
+	// В отпуске сотрудник указан по profile_id, поэтому берем его из самого отпуска
+	vacations, err := s.vacationsLister.ListVacations(ctx,
+		time.Date(w.PeriodStart.Year(), time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(lastYear+1, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return "", err
+	}
+	employeeID := uuid.Nil
+	for _, v := range vacations {
+		if v.WeekdayID == w.ID {
+			employeeID = v.EmployeeID
+		}
+	}
+	if employeeID == uuid.Nil {
+		return "", nil
+	}
+
+	balances, err := s.ListVacationBalances(ctx, lastYear, employeeID)
+	if err != nil || len(balances) == 0 {
+		return "", err
+	}
+
+	warnings := []string{}
+	for _, y := range append(balances[0].History, balances[0].VacationYear) {
+		if y.Year < firstYear || y.Remaining >= 0 {
+			continue
+		}
+		// Доступное включает дни, которые начислятся до конца года при помесячном начислении
+		warnings = append(warnings, fmt.Sprintf("Внимание: отпуск превышает остаток на %d год на %g дн. (доступно %g, использовано и запланировано %g)",
+			y.Year, -y.Remaining, y.Remaining+y.Used+y.Planned, y.Used+y.Planned))
+	}
+
+	return strings.Join(warnings, "\n"), nil
+}
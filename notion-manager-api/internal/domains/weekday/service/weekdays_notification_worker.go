@@ -10,10 +10,6 @@ import (
 	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
 )
 
-type newWeekdayNotifier interface {
-	SendWeekendNotification(ctx context.Context, weekday *weekday.Weekday) error
-}
-
 type weekdaywLister interface {
 	ListWeekdays(ctx context.Context, filter *weekday.Filter) ([]weekday.Weekday, error)
 }
@@ -40,6 +36,22 @@ func (s *WeekdayService) StartWeekdaysNotificationWorker(ctx context.Context) {
 
 }
 
+// weekdayNotifyMsg собирает сообщение об отсутствии с рабочими днями по календарю сотрудника
+// и предупреждением о перерасходе отпуска. Если данных не хватает, отправляется сообщение без них.
+func (s *WeekdayService) weekdayNotifyMsg(ctx context.Context, w *weekday.Weekday) (string, error) {
+	geo, err := s.employeeGeoGetter.GetEmployeeGeo(ctx, w.Employee.ID)
+	if err != nil {
+		return w.GetNotifyMsg(), err
+	}
+	msg := w.GetNotifyMsgByCalendar(s.calendars.ForGeo(geo).IsWorkingDay)
+
+	warning, err := s.vacationWarning(ctx, w)
+	if warning != "" {
+		msg += "\n\n" + warning
+	}
+	return msg, err
+}
+
 func (s *WeekdayService) notifyAboutNewWeekdays(ctx context.Context) error {
 	notified := false
 
@@ -63,24 +75,25 @@ func (s *WeekdayService) notifyAboutNewWeekdays(ctx context.Context) error {
 		go func() {
 			defer wg.Done()
 			ctx := context.Background()
-			ctx, err = s.transactioner.Begin(ctx)
+
+			msg, err := s.weekdayNotifyMsg(ctx, &w)
 			if err != nil {
-				return
+				slog.Error("Error preparing weekday notification", "error", err, "weekday_id", w.ID)
 			}
-			defer s.transactioner.Rollback(ctx)
 
-			if err := s.newWeekdayNotifier.SendWeekendNotification(ctx, &w); err != nil {
+			// Недоставленные уведомления повторяются на следующей итерации
+			if err := s.deliverWeekdayNotification(ctx, &w, msg); err != nil {
+				slog.Error("Error delivering weekday notification", "error", err, "weekday_id", w.ID)
 				return
 			}
 
-			if err := s.weekdayNotifiedMaker.MakeWeekdayNotified(ctx, &w); err != nil {
-				return
+			if err := s.reportAbsenceImpact(ctx, &w); err != nil {
+				slog.Error("Error reporting absence impact", "error", err, "weekday_id", w.ID)
 			}
 
-			if err := s.transactioner.Commit(ctx); err != nil {
-				return
+			if err := s.weekdayNotifiedMaker.MakeWeekdayNotified(ctx, &w); err != nil {
+				slog.Error("Error marking weekday notified", "error", err, "weekday_id", w.ID)
 			}
-
 		}()
 	}
 
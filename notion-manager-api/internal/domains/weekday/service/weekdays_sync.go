@@ -5,7 +5,9 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
 	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/google/uuid"
 )
 
 type weekdaysNotionLister interface {
@@ -20,6 +22,14 @@ type weekdaySetter interface {
 	SetWeekday(ctx context.Context, weekday *weekday.Weekday) error
 }
 
+type weekdayGetter interface {
+	GetWeekday(ctx context.Context, weekdayID uuid.UUID) (*weekday.Weekday, error)
+}
+
+type changesRecorder interface {
+	RecordChanges(ctx context.Context, entity audit.Entity, entityID uuid.UUID, before, after map[string]string, editedAt time.Time, editedBy string) error
+}
+
 func (s *WeekdayService) WeekdaysSync(ctx context.Context) {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
@@ -52,21 +62,57 @@ func (s *WeekdayService) updateWeekdays(ctx context.Context) error {
 		return nil
 	}
 
-	ctx, err = s.transactioner.Begin(ctx)
+	txCtx, err := s.transactioner.Begin(ctx)
 	if err != nil {
 		return err
 	}
-	defer s.transactioner.Rollback(ctx)
+	defer s.transactioner.Rollback(txCtx)
 
-	for _, w := range weekdays {
-		if err := s.weekdaySetter.SetWeekday(ctx, &w); err != nil {
+	befores := make([]map[string]string, len(weekdays))
+	for i := range weekdays {
+		before, err := s.setWeekday(txCtx, &weekdays[i])
+		if err != nil {
 			return err
 		}
+		befores[i] = before
 	}
-	if err := s.transactioner.Commit(ctx); err != nil {
+	if err := s.transactioner.Commit(txCtx); err != nil {
 		return err
 	}
 
+	// Журнал пишется после коммита, чтобы ошибка записи не прерывала транзакцию синхронизации
+	if s.changesRecorder != nil {
+		for i, w := range weekdays {
+			if err := s.changesRecorder.RecordChanges(ctx, audit.EntityWeekday, w.ID, befores[i], w.AuditFields(), w.UpdatedAt, w.UpdatedBy); err != nil {
+				slog.Error("Error recording weekday changes", "error", err, "weekday_id", w.ID)
+			}
+		}
+	}
+
+	if err := s.checkAbsenceConflicts(ctx, weekdays); err != nil {
+		slog.Error("Error checking absence conflicts", "error", err)
+	}
+
 	return nil
 
 }
+
+// setWeekday сохраняет отсутствие и возвращает его поля до изменения для журнала
+func (s *WeekdayService) setWeekday(ctx context.Context, w *weekday.Weekday) (map[string]string, error) {
+	var before map[string]string
+	if s.changesRecorder != nil {
+		old, err := s.weekdayGetter.GetWeekday(ctx, w.ID)
+		if err != nil {
+			return nil, err
+		}
+		if old != nil {
+			before = old.AuditFields()
+		}
+	}
+
+	if err := s.weekdaySetter.SetWeekday(ctx, w); err != nil {
+		return nil, err
+	}
+
+	return before, nil
+}
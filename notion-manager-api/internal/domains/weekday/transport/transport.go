@@ -2,85 +2,485 @@ package transport
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
-	"github.com/Corray333/employee_dashboard/internal/domains/feedback/entities/feedback"
-	feedbackpb "github.com/Corray333/employee_dashboard/internal/domains/feedback/transport/grpc"
+	"github.com/Corray333/employee_dashboard/internal/calendar"
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/entities/weekday"
+	"github.com/Corray333/employee_dashboard/internal/transport"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type service interface {
-	ListFeedbacks(ctx context.Context, filter *feedback.Feedback) ([]feedback.Feedback, error)
+	ListAbsences(ctx context.Context, filter *weekday.CalendarFilter) ([]weekday.Absence, error)
+	ListAbsenceConflicts(ctx context.Context, filter *weekday.CalendarFilter) ([]weekday.AbsenceConflict, error)
+	NotifyAbsenceConflicts(ctx context.Context, from, to time.Time) ([]weekday.AbsenceConflict, error)
+	ListAbsenceImpact(ctx context.Context, weekdayID uuid.UUID) ([]weekday.AbsenceImpact, error)
+
+	ListVacationBalances(ctx context.Context, year int, employeeID uuid.UUID) ([]weekday.VacationBalance, error)
+	SetVacationOpeningBalance(ctx context.Context, employeeID uuid.UUID, days float64) error
+	ExportVacationBalances(ctx context.Context, year int) error
+
+	GetWorkingNorm(geo string, from, to time.Time) (calendar.Norm, error)
+
+	ListNotificationSubscriptions(ctx context.Context) ([]weekday.NotificationSubscription, error)
+	CreateNotificationSubscription(ctx context.Context, subscription *weekday.NotificationSubscription) error
+	DeleteNotificationSubscription(ctx context.Context, subscriptionID int64) error
+	ListDirectionLeads(ctx context.Context) ([]weekday.DirectionLead, error)
+	SetDirectionLead(ctx context.Context, lead *weekday.DirectionLead) error
+	DeleteDirectionLead(ctx context.Context, direction string) error
 }
+
 type WeekdayTransport struct {
-	feedbackpb.UnimplementedFeedbackServiceServer
 	service service
+	router  *chi.Mux
 }
 
-func NewWeekdayTransport(grpcServer *grpc.Server, service service) *WeekdayTransport {
-	t := &WeekdayTransport{
+func NewWeekdayTransport(router *chi.Mux, service service) *WeekdayTransport {
+	return &WeekdayTransport{
 		service: service,
+		router:  router,
+	}
+}
+
+func (t *WeekdayTransport) RegisterRoutes() {
+	t.router.Group(func(r chi.Router) {
+		r.Use(transport.NewTaskTrackerAuthMiddleware())
+
+		r.Get("/api/absences", t.listAbsences)
+		r.Get("/api/absences/conflicts", t.listAbsenceConflicts)
+		r.Post("/api/absences/conflicts/notify", t.notifyAbsenceConflicts)
+		r.Get("/api/absences/{weekdayID}/impact", t.listAbsenceImpact)
+
+		r.Get("/api/vacations/balances", t.listVacationBalances)
+		r.Get("/api/vacations/balances/{employeeID}", t.getVacationBalance)
+		r.Put("/api/vacations/balances/{employeeID}/opening", t.setVacationOpeningBalance)
+		r.Post("/api/vacations/export", t.exportVacationBalances)
+
+		r.Get("/api/calendar/norm", t.getWorkingNorm)
+
+		r.Get("/api/weekdays/notifications/subscriptions", t.listNotificationSubscriptions)
+		r.Post("/api/weekdays/notifications/subscriptions", t.createNotificationSubscription)
+		r.Delete("/api/weekdays/notifications/subscriptions/{subscriptionID}", t.deleteNotificationSubscription)
+		r.Get("/api/directions/leads", t.listDirectionLeads)
+		r.Put("/api/directions/leads/{direction}", t.setDirectionLead)
+		r.Delete("/api/directions/leads/{direction}", t.deleteDirectionLead)
+	})
+}
+
+// parseCalendarFilter разбирает параметры from, to (YYYY-MM-DD, включительно), employee_id, direction, project_id и category
+func parseCalendarFilter(r *http.Request) (*weekday.CalendarFilter, error) {
+	q := r.URL.Query()
+	filter := &weekday.CalendarFilter{
+		Direction: q.Get("direction"),
+	}
+
+	var err error
+	if filter.From, err = time.Parse(time.DateOnly, q.Get("from")); err != nil {
+		return nil, err
+	}
+	if filter.To, err = time.Parse(time.DateOnly, q.Get("to")); err != nil {
+		return nil, err
+	}
+	if id := q.Get("employee_id"); id != "" {
+		if filter.EmployeeID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+	}
+	if id := q.Get("project_id"); id != "" {
+		if filter.ProjectID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range q["category"] {
+		filter.Categories = append(filter.Categories, weekday.Category(c))
 	}
-	feedbackpb.RegisterFeedbackServiceServer(grpcServer, t)
 
-	return t
+	return filter, nil
 }
 
-func convertProtoToDomain(p *feedbackpb.Feedback) *feedback.Feedback {
-	createdDate := p.GetCreatedDate().AsTime().UTC()
-	id, _ := uuid.Parse(p.GetId().GetValue())
-	taskID, _ := uuid.Parse(p.GetTaskId().GetValue())
-	projectID, _ := uuid.Parse(p.GetProjectId().GetValue())
+func (t *WeekdayTransport) listAbsences(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseCalendarFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	return &feedback.Feedback{
-		ID:          id,
-		Text:        p.GetText(),
-		Type:        p.GetType(),
-		Priority:    p.GetPriority(),
-		TaskID:      taskID,
-		ProjectID:   projectID,
-		CreatedDate: createdDate,
-		Direction:   p.GetDirection(),
-		Status:      p.GetStatus(),
+	absences, err := t.service.ListAbsences(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, weekday.ErrInvalidPeriod) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error listing absences", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(absences); err != nil {
+		slog.Error("Error encoding absences", "error", err)
 	}
 }
 
-func convertDomainToProto(f *feedback.Feedback) *feedbackpb.Feedback {
-	ts := timestamppb.New(f.CreatedDate)
+func (t *WeekdayTransport) listAbsenceConflicts(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseCalendarFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conflicts, err := t.service.ListAbsenceConflicts(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, weekday.ErrInvalidPeriod) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error listing absence conflicts", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	return &feedbackpb.Feedback{
-		Id:          &feedbackpb.UUID{Value: f.ID.String()},
-		Text:        f.Text,
-		Type:        f.Type,
-		Priority:    f.Priority,
-		TaskId:      &feedbackpb.UUID{Value: f.TaskID.String()},
-		ProjectId:   &feedbackpb.UUID{Value: f.ProjectID.String()},
-		CreatedDate: ts,
-		Direction:   f.Direction,
-		Status:      f.Status,
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(conflicts); err != nil {
+		slog.Error("Error encoding absence conflicts", "error", err)
 	}
 }
 
-func (s *WeekdayTransport) ListFeedbacks(ctx context.Context, req *feedbackpb.ListFeedbacksRequest) (*feedbackpb.ListFeedbacksResponse, error) {
-	// Преобразовать req.Filter из proto в domain модель
-	filter := convertProtoToDomain(req.GetFilter())
+// notifyAbsenceConflicts предупреждает менеджеров о еще не оповещенных конфликтах за период и возвращает их
+func (t *WeekdayTransport) notifyAbsenceConflicts(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseCalendarFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// Получить список из базы
-	feedbacks, err := s.service.ListFeedbacks(ctx, filter)
+	sent, err := t.service.NotifyAbsenceConflicts(r.Context(), filter.From, filter.To)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to list feedbacks: %v", err)
+		if errors.Is(err, weekday.ErrInvalidPeriod) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error notifying absence conflicts", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	// Преобразовать результат обратно в proto
-	var protoFeedbacks []*feedbackpb.Feedback
-	for _, f := range feedbacks {
-		protoFeedbacks = append(protoFeedbacks, convertDomainToProto(&f))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sent); err != nil {
+		slog.Error("Error encoding absence conflicts", "error", err)
 	}
+}
+
+// parseYear разбирает параметр year, по умолчанию - текущий год
+func parseYear(r *http.Request) (int, error) {
+	if year := r.URL.Query().Get("year"); year != "" {
+		return strconv.Atoi(year)
+	}
+	return time.Now().Year(), nil
+}
+
+func (t *WeekdayTransport) listAbsenceImpact(w http.ResponseWriter, r *http.Request) {
+	weekdayID, err := uuid.Parse(chi.URLParam(r, "weekdayID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	impacts, err := t.service.ListAbsenceImpact(r.Context(), weekdayID)
+	if err != nil {
+		if errors.Is(err, weekday.ErrWeekdayNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		slog.Error("Error listing absence impact", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(impacts); err != nil {
+		slog.Error("Error encoding absence impact", "error", err)
+	}
+}
+
+func (t *WeekdayTransport) listVacationBalances(w http.ResponseWriter, r *http.Request) {
+	year, err := parseYear(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	balances, err := t.service.ListVacationBalances(r.Context(), year, uuid.Nil)
+	if err != nil {
+		if errors.Is(err, weekday.ErrInvalidVacationYear) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error listing vacation balances", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(balances); err != nil {
+		slog.Error("Error encoding vacation balances", "error", err)
+	}
+}
+
+func (t *WeekdayTransport) getVacationBalance(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := uuid.Parse(chi.URLParam(r, "employeeID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	year, err := parseYear(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	balances, err := t.service.ListVacationBalances(r.Context(), year, employeeID)
+	if err != nil {
+		if errors.Is(err, weekday.ErrInvalidVacationYear) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error getting vacation balance", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(balances) == 0 {
+		http.Error(w, "employee not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(balances[0]); err != nil {
+		slog.Error("Error encoding vacation balance", "error", err)
+	}
+}
+
+func (t *WeekdayTransport) setVacationOpeningBalance(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := uuid.Parse(chi.URLParam(r, "employeeID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := struct {
+		Days float64 `json:"days"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.SetVacationOpeningBalance(r.Context(), employeeID, req.Days); err != nil {
+		slog.Error("Error setting vacation opening balance", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *WeekdayTransport) exportVacationBalances(w http.ResponseWriter, r *http.Request) {
+	year, err := parseYear(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.ExportVacationBalances(r.Context(), year); err != nil {
+		if errors.Is(err, weekday.ErrInvalidVacationYear) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error exporting vacation balances", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseNormPeriod разбирает период нормы: from и to (YYYY-MM-DD) или год year с необязательным month либо quarter
+func parseNormPeriod(r *http.Request) (time.Time, time.Time, error) {
+	q := r.URL.Query()
+	if q.Get("from") != "" || q.Get("to") != "" {
+		from, err := time.Parse(time.DateOnly, q.Get("from"))
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to, err := time.Parse(time.DateOnly, q.Get("to"))
+		return from, to, err
+	}
+
+	year, err := parseYear(r)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	switch {
+	case q.Get("month") != "":
+		month, err := strconv.Atoi(q.Get("month"))
+		if err != nil || month < 1 || month > 12 {
+			return time.Time{}, time.Time{}, weekday.ErrInvalidPeriod
+		}
+		from, to := calendar.MonthBounds(year, time.Month(month))
+		return from, to, nil
+	case q.Get("quarter") != "":
+		quarter, err := strconv.Atoi(q.Get("quarter"))
+		if err != nil || quarter < 1 || quarter > 4 {
+			return time.Time{}, time.Time{}, weekday.ErrInvalidPeriod
+		}
+		from, to := calendar.QuarterBounds(year, quarter)
+		return from, to, nil
+	}
+
+	return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC), nil
+}
+
+// getWorkingNorm возвращает норму рабочих дней и часов за период по производственному календарю для geo
+func (t *WeekdayTransport) getWorkingNorm(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseNormPeriod(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	norm, err := t.service.GetWorkingNorm(r.URL.Query().Get("geo"), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(norm); err != nil {
+		slog.Error("Error encoding working norm", "error", err)
+	}
+}
+
+func (t *WeekdayTransport) listNotificationSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := t.service.ListNotificationSubscriptions(r.Context())
+	if err != nil {
+		slog.Error("Error listing notification subscriptions", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(subscriptions); err != nil {
+		slog.Error("Error encoding notification subscriptions", "error", err)
+	}
+}
+
+func (t *WeekdayTransport) createNotificationSubscription(w http.ResponseWriter, r *http.Request) {
+	subscription := &weekday.NotificationSubscription{}
+	if err := json.NewDecoder(r.Body).Decode(subscription); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.CreateNotificationSubscription(r.Context(), subscription); err != nil {
+		if errors.Is(err, weekday.ErrInvalidSubscription) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error creating notification subscription", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(subscription); err != nil {
+		slog.Error("Error encoding notification subscription", "error", err)
+	}
+}
+
+func (t *WeekdayTransport) deleteNotificationSubscription(w http.ResponseWriter, r *http.Request) {
+	subscriptionID, err := strconv.ParseInt(chi.URLParam(r, "subscriptionID"), 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.DeleteNotificationSubscription(r.Context(), subscriptionID); err != nil {
+		slog.Error("Error deleting notification subscription", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *WeekdayTransport) listDirectionLeads(w http.ResponseWriter, r *http.Request) {
+	leads, err := t.service.ListDirectionLeads(r.Context())
+	if err != nil {
+		slog.Error("Error listing direction leads", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(leads); err != nil {
+		slog.Error("Error encoding direction leads", "error", err)
+	}
+}
+
+func (t *WeekdayTransport) setDirectionLead(w http.ResponseWriter, r *http.Request) {
+	lead := &weekday.DirectionLead{}
+	err := json.NewDecoder(r.Body).Decode(lead)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if lead.Direction, err = directionParam(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.SetDirectionLead(r.Context(), lead); err != nil {
+		if errors.Is(err, weekday.ErrInvalidDirectionLead) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error setting direction lead", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(lead); err != nil {
+		slog.Error("Error encoding direction lead", "error", err)
+	}
+}
+
+func (t *WeekdayTransport) deleteDirectionLead(w http.ResponseWriter, r *http.Request) {
+	direction, err := directionParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.DeleteDirectionLead(r.Context(), direction); err != nil {
+		slog.Error("Error deleting direction lead", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	return &feedbackpb.ListFeedbacksResponse{
-		Feedbacks: protoFeedbacks,
-	}, nil
+// directionParam возвращает направление из пути, в названии могут быть пробелы и слэши
+func directionParam(r *http.Request) (string, error) {
+	return url.PathUnescape(chi.URLParam(r, "direction"))
 }
@@ -1,41 +1,47 @@
 package weekday
 
 import (
+	"github.com/Corray333/employee_dashboard/internal/calendar"
+	audit_service "github.com/Corray333/employee_dashboard/internal/domains/audit/service"
 	employee_service "github.com/Corray333/employee_dashboard/internal/domains/employee/service"
 	notion_repo "github.com/Corray333/employee_dashboard/internal/domains/weekday/repositories/notion"
 	postgres_repo "github.com/Corray333/employee_dashboard/internal/domains/weekday/repositories/postgres"
 	sheets_repo "github.com/Corray333/employee_dashboard/internal/domains/weekday/repositories/sheets"
 	tg_repo "github.com/Corray333/employee_dashboard/internal/domains/weekday/repositories/tg"
 	"github.com/Corray333/employee_dashboard/internal/domains/weekday/service"
+	"github.com/Corray333/employee_dashboard/internal/domains/weekday/transport"
+	"github.com/Corray333/employee_dashboard/internal/export"
 	"github.com/Corray333/employee_dashboard/internal/postgres"
 	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
 	"github.com/Corray333/employee_dashboard/internal/telegram"
 	notion "github.com/Corray333/employee_dashboard/pkg/notion/v2"
+	"github.com/go-chi/chi/v5"
 )
 
 type WeekdayController struct {
 	postgresRepo *postgres_repo.WeekdayPostgresRepository
 	notionRepo   *notion_repo.WeekdayNotionRepository
 	service      *service.WeekdayService
-	// transport    *transport.WeekdayTransport
+	transport    *transport.WeekdayTransport
 }
 
-func NewWeekdayController(store *postgres.PostgresClient, notionClient *notion.Client, tgClient *telegram.TelegramClient, userGetter *employee_service.EmployeeService, sheetsClient *gsheets.Client) *WeekdayController {
+func NewWeekdayController(router *chi.Mux, store *postgres.PostgresClient, calendars *calendar.Calendars, notionClient *notion.Client, tgClient *telegram.TelegramClient, userGetter *employee_service.EmployeeService, sheetsClient *gsheets.Client, auditService *audit_service.AuditService) *WeekdayController {
 
 	postgresRepo := postgres_repo.NewWeekdayPostgresRepository(store, userGetter)
 	notionRepo := notion_repo.NewWeekdayNotionRepository(notionClient)
 	tgRepo := tg_repo.NewWeekdayTelegramRepository(tgClient)
-	sheetsRepo := sheets_repo.NewWeekdaySheetsRepository(sheetsClient)
+	sheetsRepo := sheets_repo.NewWeekdaySheetsRepository(sheetsClient, export.NewSink("weekdays", sheetsClient, tgClient), export.NewSink("vacations", sheetsClient, tgClient))
 
-	service := service.NewWeekdayService(service.WithPostgresRepository(postgresRepo), service.WithNotionRepository(notionRepo), service.WithTelegramRepository(tgRepo), service.WithSheetsRepository(sheetsRepo), service.WithSheetsRepository(sheetsRepo))
+	service := service.NewWeekdayService(service.WithPostgresRepository(postgresRepo), service.WithNotionRepository(notionRepo), service.WithTelegramRepository(tgRepo), service.WithSheetsRepository(sheetsRepo), service.WithSheetsRepository(sheetsRepo), service.WithChangesRecorder(auditService), service.WithCalendars(calendars))
 
-	// transport := transport.NewWeekdayTransport(grpcServer, service)
+	transport := transport.NewWeekdayTransport(router, service)
 
 	return &WeekdayController{
 		postgresRepo: postgresRepo,
 		notionRepo:   notionRepo,
 
-		service: service,
+		service:   service,
+		transport: transport,
 	}
 }
 
@@ -44,7 +50,7 @@ func (c *WeekdayController) GetService() *service.WeekdayService {
 }
 
 func (c *WeekdayController) Build() {
-	// c.transport.RegisterRoutes()
+	c.transport.RegisterRoutes()
 }
 
 func (c *WeekdayController) Run() {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
 	"github.com/google/uuid"
 )
 
@@ -78,9 +79,20 @@ type Project struct {
 
 	ManagerID string `json:"managerID" db:"manager_id"`
 
-	TotalHours       float64 `json:"-" db:"total_hours"`
-	ManagementTaskID string  `json:"-" db:"management_task_id"`
-	TestingTaskID    string  `json:"-" db:"testing_task_id"`
+	LastEditedTime time.Time `json:"-" db:"-"`
+	LastEditedBy   string    `json:"-" db:"-"`
+}
+
+// AuditFields возвращает поля проекта, изменения которых пишутся в журнал
+func (p Project) AuditFields() map[string]string {
+	return map[string]string{
+		"name":        p.Name,
+		"status":      p.Status,
+		"type":        p.Type,
+		"manager_id":  p.ManagerID,
+		"sheets_link": p.SheetsLink,
+		"client_id":   audit.FormatUUID(p.ClientID),
+	}
 }
 
 type Employee struct {
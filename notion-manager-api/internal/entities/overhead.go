@@ -0,0 +1,193 @@
+package entities
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrInvalidOverheadRule = errors.New("invalid overhead rule")
+
+type OverheadKind string
+
+const (
+	OverheadKindPercent OverheadKind = "percent" // процент от часов проекта
+	OverheadKindFixed   OverheadKind = "fixed"   // фиксированное количество часов
+	OverheadKindNone    OverheadKind = "none"    // отключает служебную задачу для типа или проекта
+)
+
+// OverheadRule задает оценку служебной задачи проекта (менеджмент, тестирование и т.д.).
+// Правило проекта важнее правила типа проекта, правило типа - важнее общего (пустой тип).
+type OverheadRule struct {
+	ID          int64        `json:"id" db:"rule_id"`
+	ProjectType string       `json:"projectType" db:"project_type"`
+	ProjectID   string       `json:"projectID" db:"project_id"`
+	Role        string       `json:"role" db:"role"`
+	TitlePrefix string       `json:"titlePrefix" db:"title_prefix"`
+	Kind        OverheadKind `json:"kind" db:"kind"`
+	Value       float64      `json:"value" db:"value"`
+	Cap         float64      `json:"cap" db:"cap"` // 0 - без ограничения
+}
+
+func (r *OverheadRule) Validate() error {
+	if r.Role == "" {
+		return errors.Join(ErrInvalidOverheadRule, errors.New("empty role"))
+	}
+	switch r.Kind {
+	case OverheadKindPercent, OverheadKindFixed, OverheadKindNone:
+	default:
+		return errors.Join(ErrInvalidOverheadRule, errors.New("unknown kind "+string(r.Kind)))
+	}
+	if r.Value < 0 || r.Cap < 0 {
+		return errors.Join(ErrInvalidOverheadRule, errors.New("negative value or cap"))
+	}
+	return nil
+}
+
+func (r *OverheadRule) specificity() int {
+	switch {
+	case r.ProjectID != "":
+		return 2
+	case r.ProjectType != "":
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (r *OverheadRule) appliesTo(project *Project) bool {
+	if r.ProjectID != "" {
+		return r.ProjectID == project.ID
+	}
+	return r.ProjectType == "" || r.ProjectType == project.Type
+}
+
+func (r *OverheadRule) Estimate(baseHours float64) float64 {
+	estimate := 0.0
+	switch r.Kind {
+	case OverheadKindPercent:
+		estimate = baseHours * r.Value / 100
+	case OverheadKindFixed:
+		estimate = r.Value
+	}
+	if r.Cap > 0 && estimate > r.Cap {
+		estimate = r.Cap
+	}
+	return math.Round(estimate*100) / 100
+}
+
+// OverheadTask - задача проекта с оценкой
+type OverheadTask struct {
+	ProjectID string  `db:"project_id"`
+	TaskID    string  `db:"task_id"`
+	Title     string  `db:"title"`
+	Estimate  float64 `db:"estimate"`
+}
+
+// OverheadEstimate - оценка служебной задачи, которая будет записана в Notion
+type OverheadEstimate struct {
+	ProjectID       string  `json:"projectID"`
+	ProjectName     string  `json:"projectName"`
+	Role            string  `json:"role"`
+	RuleID          int64   `json:"ruleID"`
+	TaskID          string  `json:"taskID"`
+	TaskTitle       string  `json:"taskTitle"`
+	BaseHours       float64 `json:"baseHours"`
+	CurrentEstimate float64 `json:"currentEstimate"`
+	Estimate        float64 `json:"estimate"`
+}
+
+func (e *OverheadEstimate) Changed() bool {
+	return math.Abs(e.Estimate-e.CurrentEstimate) >= 0.01
+}
+
+type OverheadEstimateLog struct {
+	ID          int64     `json:"id" db:"log_id"`
+	ProjectID   string    `json:"projectID" db:"project_id"`
+	Role        string    `json:"role" db:"role"`
+	TaskID      string    `json:"taskID" db:"task_id"`
+	BaseHours   float64   `json:"baseHours" db:"base_hours"`
+	OldEstimate float64   `json:"oldEstimate" db:"old_estimate"`
+	NewEstimate float64   `json:"newEstimate" db:"new_estimate"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+// ResolveOverheadRules выбирает для каждой роли самое специфичное правило проекта
+func ResolveOverheadRules(project *Project, rules []OverheadRule) []OverheadRule {
+	byRole := map[string]OverheadRule{}
+	roles := []string{}
+	for _, rule := range rules {
+		if !rule.appliesTo(project) {
+			continue
+		}
+		current, ok := byRole[rule.Role]
+		if !ok {
+			roles = append(roles, rule.Role)
+		}
+		if !ok || rule.specificity() > current.specificity() {
+			byRole[rule.Role] = rule
+		}
+	}
+
+	resolved := make([]OverheadRule, 0, len(roles))
+	for _, role := range roles {
+		if byRole[role].Kind != OverheadKindNone {
+			resolved = append(resolved, byRole[role])
+		}
+	}
+	return resolved
+}
+
+// CalculateOverheads считает оценки служебных задач проекта. Служебная задача ищется по
+// привязке к проекту (roleTasks: роль -> задача), иначе по названию "<TitlePrefix> <проект>".
+// Часы проекта считаются без найденных служебных задач.
+func CalculateOverheads(project *Project, tasks []OverheadTask, roleTasks map[string]string, rules []OverheadRule) []OverheadEstimate {
+	resolved := ResolveOverheadRules(project, rules)
+
+	byID := map[string]OverheadTask{}
+	byTitle := map[string]OverheadTask{}
+	for _, t := range tasks {
+		byID[t.TaskID] = t
+		byTitle[t.Title] = t
+	}
+
+	estimates := []OverheadEstimate{}
+	overheadIDs := map[string]bool{}
+	for _, rule := range resolved {
+		task, ok := byID[roleTasks[rule.Role]]
+		if !ok && rule.TitlePrefix != "" {
+			task, ok = byTitle[rule.TitlePrefix+" "+project.Name]
+		}
+		if !ok {
+			continue
+		}
+		overheadIDs[task.TaskID] = true
+		estimates = append(estimates, OverheadEstimate{
+			ProjectID:       project.ID,
+			ProjectName:     project.Name,
+			Role:            rule.Role,
+			RuleID:          rule.ID,
+			TaskID:          task.TaskID,
+			TaskTitle:       task.Title,
+			CurrentEstimate: task.Estimate,
+		})
+	}
+
+	baseHours := 0.0
+	for _, t := range tasks {
+		if !overheadIDs[t.TaskID] {
+			baseHours += t.Estimate
+		}
+	}
+
+	for i := range estimates {
+		estimates[i].BaseHours = baseHours
+		for _, rule := range resolved {
+			if rule.Role == estimates[i].Role {
+				estimates[i].Estimate = rule.Estimate(baseHours)
+			}
+		}
+	}
+
+	return estimates
+}
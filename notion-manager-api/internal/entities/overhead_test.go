@@ -0,0 +1,73 @@
+package entities
+
+import "testing"
+
+func TestCalculateOverheads(t *testing.T) {
+	rules := []OverheadRule{
+		{ID: 1, Role: "management", TitlePrefix: "Менеджмент", Kind: OverheadKindPercent, Value: 20},
+		{ID: 2, Role: "testing", TitlePrefix: "Тестирование", Kind: OverheadKindPercent, Value: 15},
+		{ID: 3, ProjectType: "Внутренний", Role: "testing", Kind: OverheadKindNone},
+		{ID: 4, ProjectType: "Внешний", Role: "management", TitlePrefix: "Менеджмент", Kind: OverheadKindPercent, Value: 25, Cap: 20},
+		{ID: 5, ProjectID: "p2", Role: "management", TitlePrefix: "Менеджмент", Kind: OverheadKindFixed, Value: 12},
+		{ID: 6, Role: "design", TitlePrefix: "Дизайн-ревью", Kind: OverheadKindFixed, Value: 4},
+	}
+
+	tasks := []OverheadTask{
+		{TaskID: "m", Title: "Менеджмент Shop", Estimate: 10},
+		{TaskID: "t", Title: "Тестирование Shop", Estimate: 5},
+		{TaskID: "linked", Title: "QA", Estimate: 0},
+		{TaskID: "a", Title: "Backend", Estimate: 60},
+		{TaskID: "b", Title: "Frontend", Estimate: 40},
+	}
+
+	tests := []struct {
+		name      string
+		project   Project
+		roleTasks map[string]string
+		expects   map[string]float64
+		baseHours float64
+	}{
+		{
+			name:      "defaults by title",
+			project:   Project{ID: "p1", Name: "Shop", Type: "Личный"},
+			expects:   map[string]float64{"m": 20, "t": 15},
+			baseHours: 100,
+		},
+		{
+			name:      "type override with cap",
+			project:   Project{ID: "p1", Name: "Shop", Type: "Внешний"},
+			expects:   map[string]float64{"m": 20, "t": 15},
+			baseHours: 100,
+		},
+		{
+			name:      "testing disabled for type, its task counts to base hours",
+			project:   Project{ID: "p1", Name: "Shop", Type: "Внутренний"},
+			expects:   map[string]float64{"m": 21},
+			baseHours: 105,
+		},
+		{
+			name:      "project override and linked task",
+			project:   Project{ID: "p2", Name: "Shop", Type: "Внешний"},
+			roleTasks: map[string]string{"testing": "linked"},
+			expects:   map[string]float64{"m": 12, "linked": 15.75},
+			baseHours: 105,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			estimates := CalculateOverheads(&tc.project, tasks, tc.roleTasks, rules)
+			if len(estimates) != len(tc.expects) {
+				t.Fatalf("expected %d estimates, got %+v", len(tc.expects), estimates)
+			}
+			for _, e := range estimates {
+				if e.Estimate != tc.expects[e.TaskID] {
+					t.Errorf("task %s: expected %v, got %v", e.TaskID, tc.expects[e.TaskID], e.Estimate)
+				}
+				if e.BaseHours != tc.baseHours {
+					t.Errorf("task %s: expected base %v, got %v", e.TaskID, tc.baseHours, e.BaseHours)
+				}
+			}
+		})
+	}
+}
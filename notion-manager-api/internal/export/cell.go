@@ -0,0 +1,51 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hyperlinkRe = regexp.MustCompile(`^=HYPERLINK\("((?:[^"]|"")*)"\s*[;,]\s*"((?:[^"]|"")*)"\)$`)
+
+// hyperlink разбирает формулу HYPERLINK, которой выгрузки ссылаются на страницы Notion
+func hyperlink(v interface{}) (url, label string, ok bool) {
+	s, isString := v.(string)
+	if !isString {
+		return "", "", false
+	}
+	match := hyperlinkRe.FindStringSubmatch(s)
+	if match == nil {
+		return "", "", false
+	}
+	return strings.ReplaceAll(match[1], `""`, `"`), strings.ReplaceAll(match[2], `""`, `"`), true
+}
+
+// cellText - текст ячейки в файле: у ссылок остается подпись, числа пишутся без экспоненты
+func cellText(v interface{}) string {
+	if _, label, ok := hyperlink(v); ok {
+		return label
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// padRow дополняет строку пустыми ячейками до числа столбцов таблицы
+func padRow(row []interface{}, columns int) []interface{} {
+	if len(row) >= columns {
+		return row
+	}
+	padded := make([]interface{}, columns)
+	copy(padded, row)
+	return padded
+}
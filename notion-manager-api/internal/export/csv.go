@@ -0,0 +1,47 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+)
+
+// CSVSink пишет каждую таблицу в файл <path>/<target>/<таблица>.csv
+type CSVSink struct {
+	dir string
+}
+
+func NewCSVSink(dir string) *CSVSink {
+	return &CSVSink{dir: dir}
+}
+
+func (s *CSVSink) WriteTable(ctx context.Context, target string, table Table, rows [][]interface{}, keyOf gsheets.KeyFunc) error {
+	return writeFile(s.dir, target, fileName(table.Name)+".csv", func(f *os.File) error {
+		return EncodeCSV(f, table, rows)
+	})
+}
+
+// EncodeCSV записывает таблицу с заголовком в w, в том числе для отправки файлом в Telegram через TelegramSink
+func EncodeCSV(w io.Writer, table Table, rows [][]interface{}) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(table.Header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(table.Header))
+	for _, row := range rows {
+		record = record[:0]
+		for _, v := range padRow(row, len(table.Header)) {
+			record = append(record, cellText(v))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
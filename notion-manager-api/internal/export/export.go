@@ -0,0 +1,104 @@
+package export
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+	"github.com/Corray333/employee_dashboard/internal/telegram"
+	"github.com/spf13/viper"
+)
+
+// Table - выгружаемая таблица: название листа или файла и заголовки столбцов
+type Table struct {
+	Name   string
+	Header []string
+}
+
+// Sink - место, куда выгружается таблица. target - таблица Google или каталог выгрузки,
+// keyOf нужен приемникам, которые обновляют строки по месту, а не перезаписывают целиком.
+type Sink interface {
+	WriteTable(ctx context.Context, target string, table Table, rows [][]interface{}, keyOf gsheets.KeyFunc) error
+}
+
+type Kind string
+
+const (
+	KindSheets   Kind = "sheets"
+	KindXLSX     Kind = "xlsx"
+	KindCSV      Kind = "csv"
+	KindTelegram Kind = "telegram"
+)
+
+// NewSink создает приемник выгрузки name по секции export.<name> конфига.
+// Если выгрузка не настроена, данные, как и раньше, уходят в Google Sheets.
+func NewSink(name string, client *gsheets.Client, tgClient *telegram.TelegramClient) Sink {
+	kind := Kind(viper.GetString("export." + name + ".kind"))
+	dir := viper.GetString("export." + name + ".path")
+
+	switch kind {
+	case KindCSV:
+		return NewCSVSink(dir)
+	case KindXLSX:
+		return NewXLSXSink(dir)
+	case KindTelegram:
+		chatIDs := []int64{}
+		if err := viper.UnmarshalKey("export."+name+".chat_ids", &chatIDs); err != nil {
+			slog.Error("Invalid export chat ids", "export", name, "error", err)
+		}
+		return NewTelegramSink(tgClient, Kind(viper.GetString("export."+name+".format")), chatIDs, viper.GetDuration("export."+name+".interval"))
+	case KindSheets, "":
+		return NewSheetsSink(client)
+	default:
+		slog.Error("Unknown export sink, falling back to Google Sheets", "export", name, "kind", kind)
+		return NewSheetsSink(client)
+	}
+}
+
+// SheetsSink пишет таблицу в лист Google-таблицы target, меняя только отличающиеся строки
+type SheetsSink struct {
+	client *gsheets.Client
+}
+
+func NewSheetsSink(client *gsheets.Client) *SheetsSink {
+	return &SheetsSink{client: client}
+}
+
+func (s *SheetsSink) WriteTable(ctx context.Context, target string, table Table, rows [][]interface{}, keyOf gsheets.KeyFunc) error {
+	return s.client.WriteRows(ctx, target, table.Name, len(table.Header), rows, keyOf)
+}
+
+// writeFile атомарно заменяет файл таблицы в каталоге выгрузки, чтобы читатель не увидел его наполовину записанным
+func writeFile(dir, target, name string, encode func(f *os.File) error) error {
+	if target != "" {
+		dir = filepath.Join(dir, fileName(target))
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := encode(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+var fileNameReplacer = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+
+func fileName(name string) string {
+	return fileNameReplacer.Replace(name)
+}
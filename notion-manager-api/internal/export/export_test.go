@@ -0,0 +1,185 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testTable = Table{Name: "Task", Header: []string{"Задача", "Часы", "Статус"}}
+
+var testRows = [][]interface{}{
+	{`=HYPERLINK("https://notion.so/0123456789abcdef0123456789abcdef"; "Сверстать ""главную""")`, 1.5, "В работе"},
+	{"Без ссылки", 2.0},
+}
+
+func TestHyperlink(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		wantURL   string
+		wantLabel string
+		wantOK    bool
+	}{
+		{name: "sheets separator", value: `=HYPERLINK("https://notion.so/abc"; "Задача")`, wantURL: "https://notion.so/abc", wantLabel: "Задача", wantOK: true},
+		{name: "comma separator", value: `=HYPERLINK("https://notion.so/abc","Задача")`, wantURL: "https://notion.so/abc", wantLabel: "Задача", wantOK: true},
+		{name: "escaped quotes", value: `=HYPERLINK("u"; "a ""b""")`, wantURL: "u", wantLabel: `a "b"`, wantOK: true},
+		{name: "plain text", value: "Задача"},
+		{name: "other formula", value: "=SUM(A1:A2)"},
+		{name: "not a string", value: 1.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, label, ok := hyperlink(tt.value)
+			if ok != tt.wantOK || url != tt.wantURL || label != tt.wantLabel {
+				t.Errorf("hyperlink(%v) = %q, %q, %v, want %q, %q, %v", tt.value, url, label, ok, tt.wantURL, tt.wantLabel, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodeCSV(&buf, testTable, testRows); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Задача,Часы,Статус\n\"Сверстать \"\"главную\"\"\",1.5,В работе\nБез ссылки,2,\n"
+	if buf.String() != want {
+		t.Errorf("EncodeCSV() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestEncodeXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodeXLSX(&buf, testTable, testRows); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">Задача</t></is></c>`,
+		`<c r="A2" t="str"><f>HYPERLINK(&#34;https://notion.so/0123456789abcdef0123456789abcdef&#34;,&#34;Сверстать &#34;&#34;главную&#34;&#34;&#34;)</f>`,
+		`<c r="B2"><v>1.5</v></c>`,
+		`<c r="B3"><v>2</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet1.xml does not contain %s", want)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="Task"`) {
+		t.Errorf("workbook.xml has no sheet named Task")
+	}
+}
+
+func TestCSVSinkWritesTargetDir(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewCSVSink(dir)
+
+	if err := sink.WriteTable(context.Background(), "project/1", Table{Name: "Day off", Header: []string{"A"}}, [][]interface{}{{"x"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "project_1", "Day off.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "A\nx\n" {
+		t.Errorf("file content = %q", b)
+	}
+}
+
+func TestCellRef(t *testing.T) {
+	tests := []struct {
+		col, row int
+		want     string
+	}{
+		{0, 1, "A1"},
+		{25, 2, "Z2"},
+		{26, 3, "AA3"},
+		{27, 4, "AB4"},
+		{701, 5, "ZZ5"},
+		{702, 6, "AAA6"},
+	}
+
+	for _, tt := range tests {
+		if got := cellRef(tt.col, tt.row); got != tt.want {
+			t.Errorf("cellRef(%d, %d) = %q, want %q", tt.col, tt.row, got, tt.want)
+		}
+	}
+}
+
+func TestTelegramSinkSendsChangedTables(t *testing.T) {
+	sink := NewTelegramSink(nil, KindCSV, []int64{1, 2}, time.Nanosecond)
+	sent := []string{}
+	fail := map[int64]bool{}
+	sink.send = func(ctx context.Context, chatID int64, name string, data []byte, caption string) error {
+		if fail[chatID] {
+			return errors.New("blocked")
+		}
+		sent = append(sent, fmt.Sprintf("%d:%s", chatID, name))
+		return nil
+	}
+
+	if err := sink.WriteTable(context.Background(), "", testTable, testRows, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1:Task.csv", "2:Task.csv"}; !slices.Equal(sent, want) {
+		t.Fatalf("sent = %v, want %v", sent, want)
+	}
+
+	// Та же таблица повторно не отправляется
+	sent = sent[:0]
+	if err := sink.WriteTable(context.Background(), "", testTable, testRows, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("unchanged table sent again: %v", sent)
+	}
+
+	// Недоставленная таблица отправляется снова со следующей выгрузкой
+	fail[1], fail[2] = true, true
+	changed := append(slices.Clone(testRows), []interface{}{"Новая задача"})
+	if err := sink.WriteTable(context.Background(), "", testTable, changed, nil); err == nil {
+		t.Fatal("WriteTable() error = nil, want send error")
+	}
+	fail[1], fail[2] = false, false
+	if err := sink.WriteTable(context.Background(), "", testTable, changed, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("changed table sent to %v, want both chats", sent)
+	}
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+	"github.com/Corray333/employee_dashboard/internal/telegram"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+type telegramSent struct {
+	hash [sha256.Size]byte
+	at   time.Time
+}
+
+// TelegramSink отправляет таблицу файлом CSV или XLSX в чаты chatIDs. Выгрузки идут при каждой
+// синхронизации, поэтому файл уходит, только если таблица изменилась и с прошлой отправки прошло interval
+type TelegramSink struct {
+	format   Kind
+	chatIDs  []int64
+	interval time.Duration
+	send     func(ctx context.Context, chatID int64, name string, data []byte, caption string) error
+
+	mu   sync.Mutex
+	sent map[string]telegramSent
+}
+
+func NewTelegramSink(client *telegram.TelegramClient, format Kind, chatIDs []int64, interval time.Duration) *TelegramSink {
+	if format != KindCSV {
+		format = KindXLSX
+	}
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	return &TelegramSink{
+		format:   format,
+		chatIDs:  chatIDs,
+		interval: interval,
+		send: func(ctx context.Context, chatID int64, name string, data []byte, caption string) error {
+			_, err := client.GetBot().SendDocumentWithContext(ctx, chatID, gotgbot.InputFileByReader(name, bytes.NewReader(data)), &gotgbot.SendDocumentOpts{
+				Caption: caption,
+			})
+			return err
+		},
+		sent: map[string]telegramSent{},
+	}
+}
+
+func (s *TelegramSink) WriteTable(ctx context.Context, target string, table Table, rows [][]interface{}, keyOf gsheets.KeyFunc) error {
+	var buf bytes.Buffer
+	encode := EncodeXLSX
+	if s.format == KindCSV {
+		encode = EncodeCSV
+	}
+	if err := encode(&buf, table, rows); err != nil {
+		return err
+	}
+
+	id := target + "|" + table.Name
+	hash := sha256.Sum256(buf.Bytes())
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.sent[id]; ok && (last.hash == hash || now.Sub(last.at) < s.interval) {
+		return nil
+	}
+
+	name := fmt.Sprintf("%s.%s", fileName(table.Name), s.format)
+	caption := fmt.Sprintf("%s на %s", table.Name, now.Format("02.01.2006 15:04"))
+
+	// Файл считается отправленным, если дошел хотя бы в один чат, иначе отправка повторится со следующей выгрузкой
+	var sendErr error
+	delivered := false
+	for _, chatID := range s.chatIDs {
+		if err := s.send(ctx, chatID, name, buf.Bytes(), caption); err != nil {
+			slog.Error("Error sending export to telegram", "error", err, "chat_id", chatID, "table", table.Name)
+			sendErr = err
+			continue
+		}
+		delivered = true
+	}
+	if !delivered {
+		return sendErr
+	}
+
+	s.sent[id] = telegramSent{hash: hash, at: now}
+	return nil
+}
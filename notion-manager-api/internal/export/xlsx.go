@@ -0,0 +1,148 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
+)
+
+// XLSXSink пишет каждую таблицу в книгу <path>/<target>/<таблица>.xlsx с одним листом
+type XLSXSink struct {
+	dir string
+}
+
+func NewXLSXSink(dir string) *XLSXSink {
+	return &XLSXSink{dir: dir}
+}
+
+func (s *XLSXSink) WriteTable(ctx context.Context, target string, table Table, rows [][]interface{}, keyOf gsheets.KeyFunc) error {
+	return writeFile(s.dir, target, fileName(table.Name)+".xlsx", func(f *os.File) error {
+		return EncodeXLSX(f, table, rows)
+	})
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+)
+
+// EncodeXLSX записывает таблицу с заголовком в w как книгу Excel с одним листом.
+// Ссылки на Notion остаются формулами HYPERLINK, числа - числами.
+func EncodeXLSX(w io.Writer, table Table, rows [][]interface{}) error {
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, escapeXML(sheetName(table.Name)))},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeWorksheet(f, table, rows); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeWorksheet(w io.Writer, table Table, rows [][]interface{}) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	bw.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, len(table.Header))
+	for i, h := range table.Header {
+		header[i] = h
+	}
+	writeRow(bw, 1, header)
+	for i, row := range rows {
+		writeRow(bw, i+2, padRow(row, len(table.Header)))
+	}
+
+	bw.WriteString(`</sheetData></worksheet>`)
+	return bw.Flush()
+}
+
+func writeRow(w *bufio.Writer, n int, row []interface{}) {
+	fmt.Fprintf(w, `<row r="%d">`, n)
+	for i, v := range row {
+		ref := cellRef(i, n)
+		if url, label, ok := hyperlink(v); ok {
+			formula := fmt.Sprintf(`HYPERLINK("%s","%s")`, strings.ReplaceAll(url, `"`, `""`), strings.ReplaceAll(label, `"`, `""`))
+			fmt.Fprintf(w, `<c r="%s" t="str"><f>%s</f><v>%s</v></c>`, ref, escapeXML(formula), escapeXML(label))
+			continue
+		}
+
+		switch v := v.(type) {
+		case nil:
+		case int, int32, int64, float32, float64:
+			fmt.Fprintf(w, `<c r="%s"><v>%s</v></c>`, ref, cellText(v))
+		case bool:
+			fmt.Fprintf(w, `<c r="%s" t="b"><v>%s</v></c>`, ref, map[bool]string{true: "1", false: "0"}[v])
+		default:
+			if text := cellText(v); text != "" {
+				fmt.Fprintf(w, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escapeXML(text))
+			}
+		}
+	}
+	w.WriteString(`</row>`)
+}
+
+// cellRef - адрес ячейки вида A1 по номеру столбца с нуля и номеру строки с единицы
+func cellRef(col, row int) string {
+	letter := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		letter = string(rune('A'+(col-1)%26)) + letter
+	}
+	return letter + strconv.Itoa(row)
+}
+
+var sheetNameReplacer = strings.NewReplacer("[", "(", "]", ")", ":", "_", "*", "_", "?", "_", "/", "_", "\\", "_")
+
+// sheetName приводит название листа к ограничениям Excel: без []:*?/\ и не длиннее 31 символа
+func sheetName(name string) string {
+	name = sheetNameReplacer.Replace(name)
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = "Sheet1"
+	}
+	return name
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
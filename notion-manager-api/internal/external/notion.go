@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/Corray333/employee_dashboard/internal/entities"
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
 	"github.com/Corray333/employee_dashboard/pkg/mindmap"
 	"github.com/Corray333/employee_dashboard/pkg/notion"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -20,7 +21,8 @@ import (
 )
 
 type External struct {
-	tg *TelegramClient
+	tg     *TelegramClient
+	sheets *gsheets.Client
 }
 
 type TelegramClient struct {
@@ -44,9 +46,10 @@ func NewClient(token string) *TelegramClient {
 	}
 }
 
-func New() *External {
+func New(sheetsClient *gsheets.Client) *External {
 	return &External{
-		tg: NewClient(os.Getenv("BOT_TOKEN")),
+		tg:     NewClient(os.Getenv("BOT_TOKEN")),
+		sheets: sheetsClient,
 	}
 }
 
@@ -698,7 +701,10 @@ type Project struct {
 	ID             string `json:"id"`
 	CreatedTime    string `json:"created_time"`
 	LastEditedTime string `json:"last_edited_time"`
-	Icon           struct {
+	LastEditedBy   struct {
+		ID string `json:"id"`
+	} `json:"last_edited_by"`
+	Icon struct {
 		Type        string   `json:"type"`
 		External    external `json:"external"`
 		File        file     `json:"file"`
@@ -849,12 +855,14 @@ func (e *External) GetProjects(lastSynced int64) (projects []entities.Project, l
 				}
 				return id
 			}(),
+			LastEditedBy: w.LastEditedBy.ID,
 		})
 
 		lastEditedTime, err := time.Parse(notion.TIME_LAYOUT_IN, w.LastEditedTime)
 		if err != nil {
 			return nil, 0, err
 		}
+		projects[len(projects)-1].LastEditedTime = lastEditedTime
 
 		lastUpdate = lastEditedTime.Unix()
 	}
@@ -1223,11 +1231,8 @@ type Projects struct {
 	}
 }
 
-func (e *External) CreateMindmapTasks(projectName string, tasks []mindmap.Task) error {
-	fmt.Println("Creating tasks for project:", projectName)
-
-	fmt.Println(projectName)
-	// Поиск проекта в Notion
+// FindMindmapProject ищет в Notion проект по названию из майндмапы, "" - если не найден
+func (e *External) FindMindmapProject(projectName string) (string, error) {
 	projectFilter := map[string]interface{}{
 		"filter": map[string]interface{}{
 			"property": "Name",
@@ -1239,24 +1244,70 @@ func (e *External) CreateMindmapTasks(projectName string, tasks []mindmap.Task)
 	projectsResp, err := notion.SearchPages(os.Getenv("PROJECTS_DB"), projectFilter)
 	if err != nil {
 		slog.Error("Notion error while searching projects: " + err.Error())
-		return err
+		return "", err
 	}
 
 	// Извлекаем ID проекта
 	projects := Projects{}
 	if err := json.Unmarshal(projectsResp, &projects); err != nil {
 		slog.Error("Error unmarshalling projects response: " + err.Error())
-		return err
+		return "", err
 	}
 
-	projectID := ""
-	if len(projects.Results) != 0 {
-		projectID = projects.Results[0].ID
+	if len(projects.Results) == 0 {
+		return "", nil
+	}
+	return projects.Results[0].ID, nil
+}
+
+func (e *External) CreateMindmapTasks(projectName string, tasks []mindmap.Task) (*mindmap.ApplyResult, error) {
+	fmt.Println("Creating tasks for project:", projectName)
+
+	result := &mindmap.ApplyResult{
+		ProjectName: projectName,
+		Created:     []mindmap.CreatedPage{},
 	}
 
+	projectID, err := e.FindMindmapProject(projectName)
+	if err != nil {
+		return result, err
+	}
+	result.ProjectID = projectID
+
 	// Создаем задачи для проекта
 	for _, task := range tasks {
-		if err := createMindmapTask(projectID, &task, "", 0); err != nil {
+		if err := createMindmapNode(projectID, &task, "", 0, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// createMindmapNode пропускает уже существующие задачи и создает только новые узлы,
+// привязывая их к родителю. Дети обходятся и у существующих, и у новых узлов:
+// у новой задачи второго уровня новые дети становятся чекбоксами, но под уже
+// существующими детьми новые узлы все равно создаются
+func createMindmapNode(projectID string, task *mindmap.Task, parentID string, level int, result *mindmap.ApplyResult) error {
+	if task.TaskID != "" {
+		for _, subtask := range task.Subtasks {
+			if err := createMindmapNode(projectID, &subtask, task.TaskID, level+1, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	pageID, err := createMindmapTask(projectID, task, parentID, level, result)
+	if err != nil {
+		return err
+	}
+
+	for _, subtask := range task.Subtasks {
+		if level > 0 && subtask.TaskID == "" {
+			continue
+		}
+		if err := createMindmapNode(projectID, &subtask, pageID, level+1, result); err != nil {
 			return err
 		}
 	}
@@ -1268,7 +1319,8 @@ type PageCreated struct {
 	ID string `json:"id"`
 }
 
-func createMindmapTask(projectID string, task *mindmap.Task, parentID string, level int) error {
+// createMindmapTask создает страницу одной задачи и возвращает ее ID
+func createMindmapTask(projectID string, task *mindmap.Task, parentID string, level int, result *mindmap.ApplyResult) (string, error) {
 	fmt.Printf("Creating task: %s\n", task.Title)
 
 	// Основная структура для страницы задачи
@@ -1310,34 +1362,41 @@ func createMindmapTask(projectID string, task *mindmap.Task, parentID string, le
 				},
 			},
 		}
-
-		// Массив для вложенных задач, если они есть
-		content = createCheckboxes(task.Subtasks)
+	}
+	if level > 0 {
+		// Новые вложенные задачи второго уровня становятся чекбоксами
+		content = createCheckboxes(newMindmapTasks(task.Subtasks))
 	}
 
 	// Создаем страницу задачи в Notion
 	resp, err := notion.CreatePage(viper.GetString("notion.databases.tasks"), req, content, "")
 	if err != nil {
 		slog.Error("Notion error while creating task: " + err.Error())
-		return err
+		return "", err
 	}
 
 	var page PageCreated
 	if err := json.Unmarshal(resp, &page); err != nil {
 		slog.Error("Error unmarshalling response: " + err.Error())
-		return err
+		return "", err
 	}
+	result.Created = append(result.Created, mindmap.CreatedPage{
+		Title:    task.Title,
+		PageID:   page.ID,
+		ParentID: parentID,
+	})
 
-	if level == 0 {
-		// Рекурсивно создаем подзадачи
-		for _, subtask := range task.Subtasks {
-			if err := createMindmapTask(projectID, &subtask, page.ID, level+1); err != nil {
-				return err
-			}
+	return page.ID, nil
+}
+
+func newMindmapTasks(tasks []mindmap.Task) []mindmap.Task {
+	result := []mindmap.Task{}
+	for _, task := range tasks {
+		if task.TaskID == "" {
+			result = append(result, task)
 		}
 	}
-
-	return nil
+	return result
 }
 
 func createCheckboxes(tasks []mindmap.Task) []map[string]interface{} {
@@ -1,16 +1,16 @@
 package external
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/Corray333/employee_dashboard/internal/entities"
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
 	"github.com/Corray333/employee_dashboard/pkg/notion"
-	"golang.org/x/oauth2/google"
 	"google.golang.org/api/sheets/v4"
 )
 
@@ -351,26 +351,24 @@ const spreadsheetId = "1dStGuMfFU2Vq2V2xgXLyKUq_j3zYBeP15LA0eUQtTAQ"
 
 func (e *External) NewSheetsClient() (*sheets.Service, error) {
 	slog.Info("Updating Google Sheets")
-	b, err := os.ReadFile("../secrets/credentials.json")
-	if err != nil {
-		slog.Error("Unable to read client secret file", "error", err)
+	if err := e.sheets.Ready(); err != nil {
+		slog.Error("Google Sheets are not available", "error", err)
 		return nil, err
 	}
 
-	config, err := google.ConfigFromJSON(b, sheets.SpreadsheetsScope)
-	if err != nil {
-		slog.Error("Unable to parse client secret file to config", "error", err)
-		return nil, err
-	}
-	client := GetClient(config)
+	return e.sheets.Svc(), nil
+}
 
-	srv, err := sheets.New(client)
-	if err != nil {
-		slog.Error("Unable to retrieve Sheets Client", "error", err)
-		return nil, err
-	}
+func (e *External) SheetsHealth() gsheets.Health {
+	return e.sheets.Health()
+}
+
+func (e *External) SheetsConsentURL() (string, error) {
+	return e.sheets.ConsentURL()
+}
 
-	return srv, nil
+func (e *External) CompleteSheetsConsent(ctx context.Context, state, code string) error {
+	return e.sheets.CompleteConsent(ctx, state, code)
 }
 
 type UpdateRequest struct {
@@ -68,34 +68,103 @@ func (s *Storage) GetProjects(userID string) (projects []entities.Project, err e
 	return projects, nil
 }
 
-func (s *Storage) GetProjectsWithHoursSums(ctx context.Context) ([]entities.Project, error) {
-	projects := []entities.Project{}
-	if err := s.db.Select(&projects, `
-		SELECT
-			p.project_id,
-			COALESCE(SUM(t.estimate) FILTER (
-				WHERE t.title NOT IN (
-					'Менеджмент ' || p.name,
-					'Тестирование ' || p.name
-				)
-			), 0) AS total_hours,
-			COALESCE(MAX(t.task_id::text) FILTER (
-				WHERE t.title = 'Менеджмент ' || p.name
-			)::text, '') AS management_task_id,
-			COALESCE(MAX(t.task_id::text) FILTER (
-				WHERE t.title = 'Тестирование ' || p.name
-			)::text, '') AS testing_task_id
-		FROM
-			projects p
-		JOIN
-			tasks t ON p.project_id = t.project_id
-		GROUP BY
-			p.project_id, p.name;
+func (s *Storage) GetOverheadRules(ctx context.Context) ([]entities.OverheadRule, error) {
+	rules := []entities.OverheadRule{}
+	if err := s.db.SelectContext(ctx, &rules, `
+		SELECT rule_id, project_type, project_id, role, title_prefix, kind, value, cap
+		FROM overhead_rules
+		ORDER BY project_id, project_type, role
 	`); err != nil {
-		slog.Error("Error while getting projects with hours sums", "error", err)
+		slog.Error("Error getting overhead rules", "error", err)
 		return nil, err
 	}
-	return projects, nil
+	return rules, nil
+}
+
+func (s *Storage) SetOverheadRule(ctx context.Context, rule *entities.OverheadRule) error {
+	if err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO overhead_rules (project_type, project_id, role, title_prefix, kind, value, cap)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (project_type, project_id, role) DO UPDATE SET
+			title_prefix = EXCLUDED.title_prefix, kind = EXCLUDED.kind, value = EXCLUDED.value, cap = EXCLUDED.cap
+		RETURNING rule_id
+	`, rule.ProjectType, rule.ProjectID, rule.Role, rule.TitlePrefix, rule.Kind, rule.Value, rule.Cap).Scan(&rule.ID); err != nil {
+		slog.Error("Error setting overhead rule", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Storage) DeleteOverheadRule(ctx context.Context, ruleID int64) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM overhead_rules WHERE rule_id = $1`, ruleID); err != nil {
+		slog.Error("Error deleting overhead rule", "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetOverheadTasks возвращает задачи проектов с оценками для расчета служебных задач
+func (s *Storage) GetOverheadTasks(ctx context.Context) ([]entities.OverheadTask, error) {
+	tasks := []entities.OverheadTask{}
+	if err := s.db.SelectContext(ctx, &tasks, `
+		SELECT project_id::text AS project_id, task_id::text AS task_id, title, estimate
+		FROM tasks
+		WHERE project_id IS NOT NULL
+	`); err != nil {
+		slog.Error("Error getting overhead tasks", "error", err)
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetProjectRoleTasks возвращает служебные задачи, привязанные к проектам: проект -> роль -> задача
+func (s *Storage) GetProjectRoleTasks(ctx context.Context) (map[string]map[string]string, error) {
+	rows := []struct {
+		ProjectID string `db:"project_id"`
+		Role      string `db:"role"`
+		TaskID    string `db:"task_id"`
+	}{}
+	if err := s.db.SelectContext(ctx, &rows, `
+		SELECT project_id, role, task_id::text AS task_id FROM project_role_tasks
+	`); err != nil {
+		slog.Error("Error getting project role tasks", "error", err)
+		return nil, err
+	}
+
+	result := map[string]map[string]string{}
+	for _, row := range rows {
+		if result[row.ProjectID] == nil {
+			result[row.ProjectID] = map[string]string{}
+		}
+		result[row.ProjectID][row.Role] = row.TaskID
+	}
+	return result, nil
+}
+
+func (s *Storage) LogOverheadEstimate(ctx context.Context, estimate *entities.OverheadEstimate) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO overhead_estimate_log (project_id, role, task_id, base_hours, old_estimate, new_estimate)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, estimate.ProjectID, estimate.Role, estimate.TaskID, estimate.BaseHours, estimate.CurrentEstimate, estimate.Estimate); err != nil {
+		slog.Error("Error logging overhead estimate", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Storage) GetOverheadEstimateLog(ctx context.Context, projectID string) ([]entities.OverheadEstimateLog, error) {
+	logs := []entities.OverheadEstimateLog{}
+	if err := s.db.SelectContext(ctx, &logs, `
+		SELECT log_id, project_id, role, task_id, base_hours, old_estimate, new_estimate, created_at
+		FROM overhead_estimate_log
+		WHERE $1 = '' OR project_id = $1
+		ORDER BY created_at DESC
+		LIMIT 500
+	`, projectID); err != nil {
+		slog.Error("Error getting overhead estimate log", "error", err)
+		return nil, err
+	}
+	return logs, nil
 }
 
 func (s *Storage) GetActiveTasks(userID string, projectID string) (tasks []entities.Task, err error) {
@@ -116,6 +185,24 @@ func (s *Storage) GetActiveTasks(userID string, projectID string) (tasks []entit
 	return tasks, nil
 }
 
+// GetExistingTaskIDs возвращает те из taskIDs, которые уже есть в базе
+func (s *Storage) GetExistingTaskIDs(ctx context.Context, taskIDs []string) (map[string]bool, error) {
+	ids := []string{}
+	if err := s.db.SelectContext(ctx, &ids, `
+        SELECT task_id::text FROM tasks WHERE task_id::text = ANY($1)
+    `, pq.Array(taskIDs)); err != nil {
+		slog.Error("error getting existing tasks: " + err.Error())
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		existing[id] = true
+	}
+
+	return existing, nil
+}
+
 func (s *Storage) GetQuarterTasks(quarter int) (tasks []entities.Task, err error) {
 	tasks = []entities.Task{}
 	if err := s.db.Select(&tasks, `
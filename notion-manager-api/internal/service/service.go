@@ -7,10 +7,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Corray333/employee_dashboard/internal/calendar"
+	"github.com/Corray333/employee_dashboard/internal/domains/audit/entities/audit"
+	"github.com/Corray333/employee_dashboard/internal/domains/job/entities/job"
 	"github.com/Corray333/employee_dashboard/internal/entities"
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
 	"github.com/Corray333/employee_dashboard/pkg/mindmap"
 	"github.com/go-co-op/gocron"
 	"github.com/google/uuid"
+	"github.com/spf13/viper"
 	"google.golang.org/api/sheets/v4"
 )
 
@@ -38,6 +43,7 @@ type repository interface {
 
 	GetTasksOfEmployee(employee_id string, period_start, period_end int64, quarter int) ([]entities.Task, error)
 	GetQuarterTasks(quarter int) (tasks []entities.Task, err error)
+	GetExistingTaskIDs(ctx context.Context, taskIDs []string) (map[string]bool, error)
 	GetEmployeesByNotificationFlag(ctx context.Context, flag entities.NotificationFlag) (employees []entities.Employee, err error)
 	GetUserRole(username string, userID int64) entities.DashboardRole
 
@@ -45,7 +51,13 @@ type repository interface {
 	GetExpertises() (expertises []entities.Expertise, err error)
 
 	GetExtertiseByID(ctx context.Context, id string) (expertise entities.Expertise, err error)
-	GetProjectsWithHoursSums(ctx context.Context) ([]entities.Project, error)
+	GetOverheadRules(ctx context.Context) ([]entities.OverheadRule, error)
+	SetOverheadRule(ctx context.Context, rule *entities.OverheadRule) error
+	DeleteOverheadRule(ctx context.Context, ruleID int64) error
+	GetOverheadTasks(ctx context.Context) ([]entities.OverheadTask, error)
+	GetProjectRoleTasks(ctx context.Context) (map[string]map[string]string, error)
+	LogOverheadEstimate(ctx context.Context, estimate *entities.OverheadEstimate) error
+	GetOverheadEstimateLog(ctx context.Context, projectID string) ([]entities.OverheadEstimateLog, error)
 
 	DeleteFeedback(ctx context.Context, feedbackID uuid.UUID) error
 }
@@ -64,7 +76,11 @@ type external interface {
 	SetProfileInTime(timeID, profileID string) error
 
 	NewSheetsClient() (*sheets.Service, error)
-	CreateMindmapTasks(projectName string, tasks []mindmap.Task) error
+	SheetsHealth() gsheets.Health
+	SheetsConsentURL() (string, error)
+	CompleteSheetsConsent(ctx context.Context, state, code string) error
+	CreateMindmapTasks(projectName string, tasks []mindmap.Task) (*mindmap.ApplyResult, error)
+	FindMindmapProject(projectName string) (string, error)
 	SendSalaryNotification(ctx context.Context, employeeID int64) error
 
 	UpdateTimeSheet(srv *sheets.Service, getExpertise func(string) string) error
@@ -78,9 +94,23 @@ type external interface {
 }
 
 type updateSubscriber interface {
-	AcceptUpdate(ctx context.Context)
+	UpdateSheets(ctx context.Context) error
 }
 
+type namedUpdateSubscriber struct {
+	name string
+	updateSubscriber
+}
+
+type jobQueue interface {
+	RegisterKind(kind string, steps func() []job.StepFunc)
+	Enqueue(ctx context.Context, kind, key string) (*job.Job, error)
+	GetJob(ctx context.Context, id uuid.UUID) (*job.Job, error)
+}
+
+// SheetsExportJobKind - выгрузка всех данных в общую таблицу и таблицы проектов
+const SheetsExportJobKind = "sheets_export"
+
 type taskService interface {
 	DeleteTask(ctx context.Context, taskID uuid.UUID) error
 }
@@ -89,14 +119,25 @@ type timeService interface {
 	DeleteTime(ctx context.Context, timeID uuid.UUID) error
 }
 
+type weekdayService interface {
+	DeleteWeekday(ctx context.Context, weekdayID uuid.UUID) error
+}
+
+type changesRecorder interface {
+	RecordChanges(ctx context.Context, entity audit.Entity, entityID uuid.UUID, before, after map[string]string, editedAt time.Time, editedBy string) error
+}
+
 type Service struct {
 	repo     repository
 	external external
 	cron     *gocron.Scheduler
 
-	updateSubs  []updateSubscriber
-	taskService taskService
-	timeService timeService
+	updateSubs      []namedUpdateSubscriber
+	jobQueue        jobQueue
+	taskService     taskService
+	timeService     timeService
+	weekdayService  weekdayService
+	changesRecorder changesRecorder
 }
 
 func New(repo repository, external external) *Service {
@@ -112,8 +153,14 @@ func New(repo repository, external external) *Service {
 	return svc
 }
 
-func (s *Service) AddUpdateSubscriber(sub updateSubscriber) {
-	s.updateSubs = append(s.updateSubs, sub)
+// AddUpdateSubscriber добавляет шаг name в выгрузку таблиц
+func (s *Service) AddUpdateSubscriber(name string, sub updateSubscriber) {
+	s.updateSubs = append(s.updateSubs, namedUpdateSubscriber{name: name, updateSubscriber: sub})
+}
+
+func (s *Service) SetJobQueue(queue jobQueue) {
+	s.jobQueue = queue
+	queue.RegisterKind(SheetsExportJobKind, s.sheetsExportSteps)
 }
 
 func (s *Service) SetTaskService(taskSvc taskService) {
@@ -124,6 +171,14 @@ func (s *Service) SetTimeService(timeSvc timeService) {
 	s.timeService = timeSvc
 }
 
+func (s *Service) SetWeekdayService(weekdaySvc weekdayService) {
+	s.weekdayService = weekdaySvc
+}
+
+func (s *Service) SetChangesRecorder(recorder changesRecorder) {
+	s.changesRecorder = recorder
+}
+
 func (s *Service) Run() {
 	go s.StartUpdatingWorker()
 	// go s.StartOutboxWorker()
@@ -175,14 +230,42 @@ func (s *Service) CheckInvalid() {
 
 }
 
-func (s *Service) UpdateGoogleSheets(ctx context.Context) error {
+// UpdateGoogleSheets ставит выгрузку таблиц в очередь. Пока предыдущий запрос ждет очереди,
+// повторные присоединяются к нему, а выгрузки в одну таблицу выполняются по очереди.
+func (s *Service) UpdateGoogleSheets(ctx context.Context) (*job.Job, error) {
+	return s.jobQueue.Enqueue(ctx, SheetsExportJobKind, viper.GetString("sheets.id"))
+}
+
+func (s *Service) GetJob(ctx context.Context, id uuid.UUID) (*job.Job, error) {
+	return s.jobQueue.GetJob(ctx, id)
+}
+
+// SheetsHealth возвращает статус учетных данных Google Sheets
+func (s *Service) SheetsHealth() gsheets.Health {
+	return s.external.SheetsHealth()
+}
+
+// SheetsConsentURL возвращает ссылку для повторного согласия Google, например чтобы выдать скоуп Drive
+func (s *Service) SheetsConsentURL() (string, error) {
+	return s.external.SheetsConsentURL()
+}
 
-	ctx = context.Background()
+// CompleteSheetsConsent сохраняет токен, полученный после повторного согласия
+func (s *Service) CompleteSheetsConsent(ctx context.Context, state, code string) error {
+	return s.external.CompleteSheetsConsent(ctx, state, code)
+}
 
+func (s *Service) sheetsExportSteps() []job.StepFunc {
+	steps := make([]job.StepFunc, 0, len(s.updateSubs)+1)
 	for _, sub := range s.updateSubs {
-		go sub.AcceptUpdate(ctx)
+		steps = append(steps, job.StepFunc{Name: sub.name, Run: sub.UpdateSheets})
 	}
+	steps = append(steps, job.StepFunc{Name: "dashboard", Run: s.updateDashboardSheets})
+	return steps
+}
 
+// updateDashboardSheets обновляет листы проектов, экспертиз и сотрудников, которые ведет старый сервис
+func (s *Service) updateDashboardSheets(ctx context.Context) error {
 	projects, err := s.repo.GetProjects("")
 	if err != nil {
 		return err
@@ -231,13 +314,11 @@ func (s *Service) UpdateGoogleSheets(ctx context.Context) error {
 }
 
 func (s *Service) GetTasksOfEmployee(employee_username string, period_start, period_end int64) ([]entities.Task, error) {
-	currentQuarter := (int(time.Now().Month())-1)/3 + 1
-	return s.repo.GetTasksOfEmployee(employee_username, period_start, period_end, int(currentQuarter))
+	return s.repo.GetTasksOfEmployee(employee_username, period_start, period_end, calendar.Quarter(time.Now()))
 }
 
 func (s *Service) GetQuarterTasks() ([]entities.Task, error) {
-	currentQuarter := (int(time.Now().Month())-1)/3 + 1
-	return s.repo.GetQuarterTasks(int(currentQuarter))
+	return s.repo.GetQuarterTasks(calendar.Quarter(time.Now()))
 }
 
 func (s *Service) groupByEmployeeID(rows []entities.Row) map[string][]entities.Row {
@@ -337,9 +418,14 @@ func (s *Service) Actualize() (updated bool, err error) {
 	if err != nil {
 		return false, err
 	}
+	oldProjects, err := s.repo.GetProjects("")
+	if err != nil {
+		return false, err
+	}
 	if err := s.repo.SetProjects(projects); err != nil {
 		return false, err
 	}
+	s.recordProjectsChanges(context.Background(), oldProjects, projects)
 
 	// fmt.Println("Getting tasks")
 	// tasks, tasksLastUpdate, err := s.external.GetTasks("last_edited_time", system.TasksDBLastSynced.Unix(), "", false)
@@ -381,6 +467,34 @@ func (s *Service) Actualize() (updated bool, err error) {
 	return len(employees) > 0 || len(projects) > 0, nil
 }
 
+// recordProjectsChanges пишет в журнал изменения полей проектов после их перезаписи
+func (s *Service) recordProjectsChanges(ctx context.Context, oldProjects, projects []entities.Project) {
+	if s.changesRecorder == nil {
+		return
+	}
+
+	before := make(map[string]entities.Project, len(oldProjects))
+	for _, p := range oldProjects {
+		before[p.ID] = p
+	}
+
+	for _, p := range projects {
+		projectID, err := uuid.Parse(p.ID)
+		if err != nil || p.Name == "" {
+			continue
+		}
+
+		var oldFields map[string]string
+		if old, ok := before[p.ID]; ok {
+			oldFields = old.AuditFields()
+		}
+
+		if err := s.changesRecorder.RecordChanges(ctx, audit.EntityProject, projectID, oldFields, p.AuditFields(), p.LastEditedTime, p.LastEditedBy); err != nil {
+			slog.Error("Error recording project changes", "error", err, "project_id", p.ID)
+		}
+	}
+}
+
 func (s *Service) SetProfileInTimes(times []entities.Time) error {
 	for _, time := range times {
 		if time.EmployeeID == "" {
@@ -486,45 +600,164 @@ func (s *Service) NotifyEmployeesAboutSalary(ctx context.Context) error {
 
 }
 
-func (s *Service) CreateMindmapTasks(mindmapData string) error {
-	projectName, tasks, err := mindmap.ParseMarkdownTasks(mindmapData)
+func (s *Service) CreateMindmapTasks(data []byte, format mindmap.Format) (*mindmap.ApplyResult, error) {
+	projectName, tasks, err := mindmap.Parse(format, data, viper.GetInt64("mindmap.max_size"))
 	if err != nil {
 		slog.Error("Failed to parse tasks from mindmap", "error", err)
-		return err
+		return nil, err
 	}
 
-	if err := s.external.CreateMindmapTasks(projectName, tasks); err != nil {
-		slog.Error("Failed to create tasks in Notion", "error", err)
-		return err
+	return s.ApplyMindmap(projectName, tasks)
+}
+
+// markExistingMindmapTasks помечает узлы, ссылки которых ведут на уже существующие задачи,
+// чтобы при повторной загрузке выгруженной майндмапы создавались только новые узлы
+func (s *Service) markExistingMindmapTasks(tasks []mindmap.Task) error {
+	ids := mindmap.LinkedPageIDs(tasks)
+	existing := map[string]bool{}
+	if len(ids) > 0 {
+		var err error
+		existing, err = s.repo.GetExistingTaskIDs(context.Background(), ids)
+		if err != nil {
+			return err
+		}
 	}
 
+	mindmap.MarkExisting(tasks, existing)
 	return nil
 }
 
+// PreviewMindmap разбирает майндмапу и проверяет ее, ничего не создавая в Notion
+func (s *Service) PreviewMindmap(data []byte, format mindmap.Format) (*mindmap.Preview, error) {
+	projectName, tasks, err := mindmap.Parse(format, data, viper.GetInt64("mindmap.max_size"))
+	if err != nil {
+		slog.Error("Failed to parse tasks from mindmap", "error", err)
+		return nil, err
+	}
+
+	if err := s.markExistingMindmapTasks(tasks); err != nil {
+		return nil, err
+	}
+
+	preview := &mindmap.Preview{
+		ProjectName: projectName,
+		TotalHours:  mindmap.TotalHours(tasks),
+		Tasks:       tasks,
+		NewTasks:    mindmap.CountNew(tasks),
+		Warnings:    mindmap.Validate(tasks),
+	}
+	if preview.Tasks == nil {
+		preview.Tasks = []mindmap.Task{}
+	}
+
+	preview.ProjectID, err = s.external.FindMindmapProject(projectName)
+	if err != nil {
+		slog.Error("Failed to find mindmap project in Notion", "error", err)
+		return nil, err
+	}
+	if preview.ProjectID == "" {
+		preview.Warnings = append(preview.Warnings, mindmap.Warning{
+			Code:    mindmap.WarningProjectNotFound,
+			Message: fmt.Sprintf("Проект «%s» не найден, задачи будут созданы без проекта", projectName),
+		})
+	}
+
+	return preview, nil
+}
+
+// ApplyMindmap создает в Notion задачи из ранее просмотренного дерева
+func (s *Service) ApplyMindmap(projectName string, tasks []mindmap.Task) (*mindmap.ApplyResult, error) {
+	if err := s.markExistingMindmapTasks(tasks); err != nil {
+		return nil, err
+	}
+
+	result, err := s.external.CreateMindmapTasks(projectName, tasks)
+	if err != nil {
+		slog.Error("Failed to create tasks in Notion", "error", err)
+		if result != nil {
+			result.Error = err.Error()
+		}
+		return result, err
+	}
+
+	return result, nil
+}
+
+// PreviewOverheadEstimates считает оценки служебных задач проектов по правилам, ничего не записывая в Notion
+func (s *Service) PreviewOverheadEstimates(ctx context.Context) ([]entities.OverheadEstimate, error) {
+	rules, err := s.repo.GetOverheadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.repo.GetProjects("")
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.repo.GetOverheadTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasksByProject := map[string][]entities.OverheadTask{}
+	for _, t := range tasks {
+		tasksByProject[t.ProjectID] = append(tasksByProject[t.ProjectID], t)
+	}
+
+	roleTasks, err := s.repo.GetProjectRoleTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	estimates := []entities.OverheadEstimate{}
+	for i := range projects {
+		estimates = append(estimates, entities.CalculateOverheads(&projects[i], tasksByProject[projects[i].ID], roleTasks[projects[i].ID], rules)...)
+	}
+
+	return estimates, nil
+}
+
 func (s *Service) updateProjectsEstimates(ctx context.Context) error {
-	projects, err := s.repo.GetProjectsWithHoursSums(ctx)
+	estimates, err := s.PreviewOverheadEstimates(ctx)
 	if err != nil {
-		slog.Error("Error getting projects with hours sums", "error", err)
+		slog.Error("Error calculating overhead estimates", "error", err)
 		return err
 	}
 
-	for _, project := range projects {
-		if project.ManagementTaskID != "" {
-			if err := s.external.UpdateTaskEstimate(ctx, project.ManagementTaskID, (project.TotalHours*0.2*100)/100); err != nil {
-				slog.Error("Error updating task estimate", "error", err)
-				return err
-			}
+	for _, estimate := range estimates {
+		if !estimate.Changed() {
+			continue
 		}
-		if project.TestingTaskID != "" {
-			if err := s.external.UpdateTaskEstimate(ctx, project.TestingTaskID, (project.TotalHours*0.15*100)/100); err != nil {
-				slog.Error("Error updating task estimate", "error", err)
-				return err
-			}
+		if err := s.external.UpdateTaskEstimate(ctx, estimate.TaskID, estimate.Estimate); err != nil {
+			slog.Error("Error updating task estimate", "error", err)
+			return err
+		}
+		if err := s.repo.LogOverheadEstimate(ctx, &estimate); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+func (s *Service) GetOverheadRules(ctx context.Context) ([]entities.OverheadRule, error) {
+	return s.repo.GetOverheadRules(ctx)
+}
+
+func (s *Service) SetOverheadRule(ctx context.Context, rule *entities.OverheadRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	return s.repo.SetOverheadRule(ctx, rule)
+}
+
+func (s *Service) DeleteOverheadRule(ctx context.Context, ruleID int64) error {
+	return s.repo.DeleteOverheadRule(ctx, ruleID)
+}
+
+func (s *Service) GetOverheadEstimateLog(ctx context.Context, projectID string) ([]entities.OverheadEstimateLog, error) {
+	return s.repo.GetOverheadEstimateLog(ctx, projectID)
+}
+
 func (s *Service) DeleteFeedback(ctx context.Context, feedbackID uuid.UUID) error {
 	return s.repo.DeleteFeedback(ctx, feedbackID)
 }
@@ -542,3 +775,10 @@ func (s *Service) DeleteTime(ctx context.Context, timeID uuid.UUID) error {
 	}
 	return s.timeService.DeleteTime(ctx, timeID)
 }
+
+func (s *Service) DeleteWeekday(ctx context.Context, weekdayID uuid.UUID) error {
+	if s.weekdayService == nil {
+		return fmt.Errorf("weekday service not available")
+	}
+	return s.weekdayService.DeleteWeekday(ctx, weekdayID)
+}
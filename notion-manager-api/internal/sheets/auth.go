@@ -0,0 +1,166 @@
+package gsheets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/sheets/v4"
+)
+
+var (
+	ErrUnknownAuthMode = errors.New("unknown google sheets auth mode")
+	ErrTokenNotFound   = errors.New("google oauth token not found")
+)
+
+// Drive нужен, чтобы копировать шаблон таблицы проекта и выдавать к ней доступ
+var scopes = []string{sheets.SpreadsheetsScope, drive.DriveScope}
+
+// AuthMode - способ, которым сервис получает доступ к Google Sheets
+type AuthMode string
+
+const (
+	// AuthModeServiceAccount - JSON-ключ сервисного аккаунта
+	AuthModeServiceAccount AuthMode = "service_account"
+	// AuthModeWorkload - учетные данные окружения (ADC) или ключ workload identity federation
+	AuthModeWorkload AuthMode = "workload"
+	// AuthModeOAuth - OAuth-клиент пользователя с сохраненным токеном
+	AuthModeOAuth AuthMode = "oauth"
+)
+
+// TokenStoreKind - где хранится OAuth-токен
+type TokenStoreKind string
+
+const (
+	TokenStoreFile     TokenStoreKind = "file"
+	TokenStorePostgres TokenStoreKind = "postgres"
+)
+
+// AuthConfig - настройки доступа к Google Sheets из секции sheets.auth
+type AuthConfig struct {
+	Mode            AuthMode       `mapstructure:"mode"`
+	CredentialsPath string         `mapstructure:"credentials_path"`
+	TokenStore      TokenStoreKind `mapstructure:"token_store"`
+	TokenPath       string         `mapstructure:"token_path"`
+	// RedirectURL - куда Google возвращает код после повторного согласия, только для oauth
+	RedirectURL string `mapstructure:"redirect_url"`
+}
+
+func authConfigFromViper() AuthConfig {
+	cfg := AuthConfig{
+		Mode:            AuthModeOAuth,
+		CredentialsPath: "../secrets/credentials.json",
+		TokenStore:      TokenStoreFile,
+		TokenPath:       "../secrets/token.json",
+	}
+	_ = viper.UnmarshalKey("sheets.auth", &cfg)
+	if cfg.RedirectURL == "" {
+		cfg.RedirectURL = viper.GetString("server.public_url") + "/api/sheets/oauth/callback"
+	}
+	return cfg
+}
+
+// TokenStore хранит OAuth-токен между перезапусками и после каждого обновления
+type TokenStore interface {
+	GetToken(ctx context.Context) (*oauth2.Token, error)
+	SaveToken(ctx context.Context, token *oauth2.Token) error
+}
+
+// tokenSource собирает источник токенов для выбранного способа авторизации.
+// Ни один из способов не требует участия человека: если токена нет или он отозван,
+// возвращается ошибка, которая попадает в статус здоровья клиента.
+func tokenSource(ctx context.Context, cfg AuthConfig, store TokenStore) (oauth2.TokenSource, error) {
+	switch cfg.Mode {
+	case AuthModeServiceAccount:
+		b, err := os.ReadFile(cfg.CredentialsPath)
+		if err != nil {
+			return nil, fmt.Errorf("read service account key: %w", err)
+		}
+		creds, err := google.CredentialsFromJSON(ctx, b, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("parse service account key: %w", err)
+		}
+		return creds.TokenSource, nil
+	case AuthModeWorkload:
+		if cfg.CredentialsPath != "" {
+			b, err := os.ReadFile(cfg.CredentialsPath)
+			if err != nil {
+				return nil, fmt.Errorf("read workload credentials: %w", err)
+			}
+			creds, err := google.CredentialsFromJSON(ctx, b, scopes...)
+			if err != nil {
+				return nil, fmt.Errorf("parse workload credentials: %w", err)
+			}
+			return creds.TokenSource, nil
+		}
+		creds, err := google.FindDefaultCredentials(ctx, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("find default credentials: %w", err)
+		}
+		return creds.TokenSource, nil
+	case AuthModeOAuth:
+		config, err := oauthConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		tok, err := store.GetToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &persistingTokenSource{
+			ctx:   ctx,
+			base:  config.TokenSource(ctx, tok),
+			store: store,
+			last:  tok.AccessToken,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAuthMode, cfg.Mode)
+	}
+}
+
+// oauthConfig читает OAuth-клиент. Скоупы берутся из scopes, поэтому повторное согласие
+// выдает токен со всеми нужными правами, в том числе Drive
+func oauthConfig(cfg AuthConfig) (*oauth2.Config, error) {
+	b, err := os.ReadFile(cfg.CredentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("read oauth client secret: %w", err)
+	}
+	config, err := google.ConfigFromJSON(b, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("parse oauth client secret: %w", err)
+	}
+	config.RedirectURL = cfg.RedirectURL
+	return config, nil
+}
+
+// persistingTokenSource сохраняет обновленный токен, чтобы после перезапуска не начинать с истекшего
+type persistingTokenSource struct {
+	mu    sync.Mutex
+	ctx   context.Context
+	base  oauth2.TokenSource
+	store TokenStore
+	last  string
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tok, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok.AccessToken != s.last {
+		if err := s.store.SaveToken(s.ctx, tok); err != nil {
+			return nil, fmt.Errorf("save refreshed token: %w", err)
+		}
+		s.last = tok.AccessToken
+	}
+	return tok, nil
+}
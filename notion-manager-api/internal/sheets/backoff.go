@@ -0,0 +1,62 @@
+package gsheets
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// IsQuotaError - Google отклонил запрос из-за превышения квоты
+func IsQuotaError(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+	if apiErr.Code == http.StatusForbidden {
+		for _, e := range apiErr.Errors {
+			if e.Reason == "rateLimitExceeded" || e.Reason == "userRateLimitExceeded" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Backoff повторяет запросы, упершиеся в квоту, с экспоненциально растущей паузой
+type Backoff struct {
+	Attempts int
+	Initial  time.Duration
+	Max      time.Duration
+}
+
+// Do вызывает fn, пока она возвращает ошибку квоты и не исчерпаны попытки.
+// Остальные ошибки возвращаются сразу.
+func (b Backoff) Do(ctx context.Context, fn func() error) error {
+	delay := b.Initial
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsQuotaError(err) || attempt >= b.Attempts {
+			return err
+		}
+
+		// Разброс, чтобы параллельные воркеры не повторяли запросы одновременно
+		wait := delay + rand.N(delay/2+1)
+		slog.Warn("Google Sheets quota exceeded, retrying", "attempt", attempt, "wait", wait)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		delay = min(delay*2, b.Max)
+	}
+}
@@ -0,0 +1,52 @@
+package gsheets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestBackoffDo(t *testing.T) {
+	quotaErr := &googleapi.Error{Code: http.StatusTooManyRequests}
+	rateLimitErr := &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}}
+	forbiddenErr := &googleapi.Error{Code: http.StatusForbidden}
+	otherErr := errors.New("boom")
+
+	tests := []struct {
+		name     string
+		errs     []error
+		calls    int
+		wantErr  error
+		attempts int
+	}{
+		{name: "success", errs: []error{nil}, calls: 1, attempts: 3},
+		{name: "quota then success", errs: []error{quotaErr, rateLimitErr, nil}, calls: 3, attempts: 3},
+		{name: "attempts exhausted", errs: []error{quotaErr, quotaErr, quotaErr}, calls: 2, wantErr: quotaErr, attempts: 2},
+		{name: "permission error is not retried", errs: []error{forbiddenErr, nil}, calls: 1, wantErr: forbiddenErr, attempts: 3},
+		{name: "other error is not retried", errs: []error{otherErr, nil}, calls: 1, wantErr: otherErr, attempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Backoff{Attempts: tt.attempts, Initial: time.Millisecond, Max: 2 * time.Millisecond}
+
+			calls := 0
+			err := b.Do(context.Background(), func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package gsheets
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+var (
+	ErrConsentUnsupported  = errors.New("google re-consent is available only in oauth mode")
+	ErrInvalidConsentState = errors.New("invalid or expired google oauth state")
+	// ErrDriveScopeMissing возвращается, если токен выдан до того, как сервису понадобился Drive:
+	// token.json, полученный только со скоупом таблиц, не дает копировать шаблон и выдавать доступ
+	ErrDriveScopeMissing = errors.New("google token has no drive scope, re-consent via GET /api/sheets/oauth/url")
+)
+
+// consentStateTTL - сколько ждать возврата с экрана согласия Google
+const consentStateTTL = 10 * time.Minute
+
+// ConsentURL возвращает ссылку на экран согласия Google. Согласие запрашивается заново
+// со всеми скоупами сервиса, поэтому так же выдается недостающий скоуп Drive.
+func (c *Client) ConsentURL() (string, error) {
+	config, err := c.consentConfig()
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	state := hex.EncodeToString(b)
+
+	c.mu.Lock()
+	now := time.Now()
+	for s, expires := range c.states {
+		if now.After(expires) {
+			delete(c.states, s)
+		}
+	}
+	c.states[state] = now.Add(consentStateTTL)
+	c.mu.Unlock()
+
+	return config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce), nil
+}
+
+// CompleteConsent обменивает код с экрана согласия на токен, сохраняет его и
+// пересобирает источник токенов без перезапуска сервиса
+func (c *Client) CompleteConsent(ctx context.Context, state, code string) error {
+	c.mu.Lock()
+	expires, ok := c.states[state]
+	delete(c.states, state)
+	c.mu.Unlock()
+	if !ok || time.Now().After(expires) {
+		return ErrInvalidConsentState
+	}
+
+	config, err := c.consentConfig()
+	if err != nil {
+		return err
+	}
+
+	tok, err := config.Exchange(ctx, code)
+	if err != nil {
+		return fmt.Errorf("exchange oauth code: %w", err)
+	}
+	if err := c.tokens.SaveToken(ctx, tok); err != nil {
+		return fmt.Errorf("save oauth token: %w", err)
+	}
+
+	return c.Reload()
+}
+
+// Reload заново загружает учетные данные по настройкам sheets.auth, не дожидаясь
+// очередной попытки после ошибки
+func (c *Client) Reload() error {
+	err := c.auth.reload()
+	c.Check()
+	return err
+}
+
+func (c *Client) consentConfig() (*oauth2.Config, error) {
+	if c.cfg.Mode != AuthModeOAuth {
+		return nil, ErrConsentUnsupported
+	}
+	return oauthConfig(c.cfg)
+}
+
+// WrapDriveError помечает ошибку Drive, вызванную нехваткой скоупа в токене
+func WrapDriveError(err error) error {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) || gerr.Code != http.StatusForbidden {
+		return err
+	}
+	insufficient := strings.Contains(gerr.Message, "insufficient authentication scopes")
+	for _, item := range gerr.Errors {
+		if item.Reason == "insufficientPermissions" {
+			insufficient = true
+		}
+	}
+	if insufficient {
+		return fmt.Errorf("%w: %v", ErrDriveScopeMissing, err)
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package gsheets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestWrapDriveError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		missing bool
+	}{
+		{
+			name: "insufficient permissions reason",
+			err: &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{
+				{Reason: "insufficientPermissions"},
+			}},
+			missing: true,
+		},
+		{
+			name:    "insufficient scopes message",
+			err:     &googleapi.Error{Code: http.StatusForbidden, Message: "Request had insufficient authentication scopes."},
+			missing: true,
+		},
+		{
+			name: "forbidden file",
+			err: &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{
+				{Reason: "forbidden"},
+			}},
+		},
+		{
+			name: "not found",
+			err:  &googleapi.Error{Code: http.StatusNotFound},
+		},
+		{
+			name: "plain error",
+			err:  errors.New("boom"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WrapDriveError(tt.err)
+			if got := errors.Is(err, ErrDriveScopeMissing); got != tt.missing {
+				t.Fatalf("errors.Is(ErrDriveScopeMissing) = %v, want %v (err = %v)", got, tt.missing, err)
+			}
+			if !tt.missing && err != tt.err {
+				t.Fatalf("WrapDriveError() = %v, want original error", err)
+			}
+		})
+	}
+}
+
+func TestCompleteConsentState(t *testing.T) {
+	c := &Client{
+		cfg:    AuthConfig{Mode: AuthModeOAuth},
+		states: map[string]time.Time{"expired": time.Now().Add(-time.Minute)},
+	}
+
+	for _, state := range []string{"unknown", "expired"} {
+		if err := c.CompleteConsent(context.Background(), state, "code"); !errors.Is(err, ErrInvalidConsentState) {
+			t.Fatalf("CompleteConsent(%q) error = %v, want %v", state, err, ErrInvalidConsentState)
+		}
+	}
+	if len(c.states) != 0 {
+		t.Fatalf("states = %v, want consumed", c.states)
+	}
+
+	c.cfg.Mode = AuthModeServiceAccount
+	if _, err := c.ConsentURL(); !errors.Is(err, ErrConsentUnsupported) {
+		t.Fatalf("ConsentURL() error = %v, want %v", err, ErrConsentUnsupported)
+	}
+}
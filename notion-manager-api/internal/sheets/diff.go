@@ -0,0 +1,219 @@
+package gsheets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// KeyFunc возвращает ключ строки таблицы. Строки с пустым ключом сопоставляются по порядку.
+type KeyFunc func(row []interface{}) string
+
+// RowsUpdate - подряд идущие строки данных, которые нужно перезаписать, начиная с Start (с нуля)
+type RowsUpdate struct {
+	Start  int
+	Values [][]interface{}
+}
+
+// RowsDiff - изменения, которые приводят строки листа к нужному состоянию
+type RowsDiff struct {
+	Updates []RowsUpdate
+	Appends [][]interface{}
+	// Deletes - индексы удаляемых строк данных по убыванию, чтобы удаление не сдвигало следующие
+	Deletes []int
+}
+
+func (d *RowsDiff) Empty() bool {
+	return len(d.Updates) == 0 && len(d.Appends) == 0 && len(d.Deletes) == 0
+}
+
+func (d *RowsDiff) UpdatedRows() int {
+	updated := 0
+	for _, u := range d.Updates {
+		updated += len(u.Values)
+	}
+	return updated
+}
+
+// DiffRows сравнивает строки листа existing с нужными rows по ключу keyOf.
+// Измененные строки перезаписываются на месте, новые добавляются в конец,
+// строки, ключей которых больше нет (и дубликаты), удаляются. Строки rows без ключа
+// по порядку занимают место строк листа без ключа, лишние строки листа без ключа не трогаются.
+// Ключи и значения сравниваются в том виде, в котором их возвращает таблица (см. normalizeRow).
+func DiffRows(existing, rows [][]interface{}, keyOf KeyFunc) RowsDiff {
+	diff := RowsDiff{}
+
+	existingNorm := make([][]interface{}, len(existing))
+	for i, row := range existing {
+		existingNorm[i] = normalizeRow(row)
+	}
+	rowsNorm := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		rowsNorm[i] = normalizeRow(row)
+	}
+
+	wanted := make(map[string]int, len(rows))
+	keyless := []int{}
+	for i, row := range rowsNorm {
+		if key := keyOf(row); key != "" {
+			wanted[key] = i
+		} else {
+			keyless = append(keyless, i)
+		}
+	}
+
+	written := make(map[string]bool, len(rows))
+	changed := []int{}
+	changedValues := map[int][]interface{}{}
+	for i, row := range existingNorm {
+		key := keyOf(row)
+		if key == "" {
+			if len(keyless) == 0 {
+				continue
+			}
+			j := keyless[0]
+			keyless = keyless[1:]
+			if !rowsEqual(row, rowsNorm[j]) {
+				changed = append(changed, i)
+				changedValues[i] = rows[j]
+			}
+			continue
+		}
+
+		j, ok := wanted[key]
+		if !ok || written[key] {
+			diff.Deletes = append(diff.Deletes, i)
+			continue
+		}
+		written[key] = true
+
+		if !rowsEqual(row, rowsNorm[j]) {
+			changed = append(changed, i)
+			changedValues[i] = rows[j]
+		}
+	}
+
+	appendKeyless := make(map[int]bool, len(keyless))
+	for _, j := range keyless {
+		appendKeyless[j] = true
+	}
+	for j, row := range rowsNorm {
+		key := keyOf(row)
+		if key == "" {
+			if appendKeyless[j] {
+				diff.Appends = append(diff.Appends, rows[j])
+			}
+			continue
+		}
+		if !written[key] {
+			diff.Appends = append(diff.Appends, rows[j])
+			written[key] = true
+		}
+	}
+
+	for _, i := range changed {
+		last := len(diff.Updates) - 1
+		if last >= 0 && diff.Updates[last].Start+len(diff.Updates[last].Values) == i {
+			diff.Updates[last].Values = append(diff.Updates[last].Values, changedValues[i])
+			continue
+		}
+		diff.Updates = append(diff.Updates, RowsUpdate{Start: i, Values: [][]interface{}{changedValues[i]}})
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(diff.Deletes)))
+
+	return diff
+}
+
+// sheetDateLayouts - форматы, в которых даты пишутся в таблицу. С USER_ENTERED таблица хранит их
+// как даты и отдает серийными номерами, поэтому перед сравнением они переводятся в серийные номера.
+var sheetDateLayouts = []string{"02/01/2006 15:04:05", "02/01/2006"}
+
+// sheetsEpoch - нулевой день серийных номеров дат Google Sheets
+var sheetsEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// normalizeRow приводит ячейки к виду, в котором их возвращает ReadRows:
+// даты - к серийному номеру, целые числа - к float64
+func normalizeRow(row []interface{}) []interface{} {
+	norm := make([]interface{}, len(row))
+	for i, v := range row {
+		norm[i] = normalizeCell(v)
+	}
+	return norm
+}
+
+func normalizeCell(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		for _, layout := range sheetDateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.Sub(sheetsEpoch).Hours() / 24
+			}
+		}
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	return v
+}
+
+// rowsEqual сравнивает строки по строковому представлению ячеек: из таблицы числа приходят как float64,
+// а недостающие в конце строки ячейки не приходят вовсе. Числа сравниваются с точностью до секунды
+// в серийном номере даты, чтобы время не расходилось из-за округления.
+func rowsEqual(existing, row []interface{}) bool {
+	for i := range max(len(existing), len(row)) {
+		a, aNum := cellNumber(existing, i)
+		b, bNum := cellNumber(row, i)
+		if aNum && bNum {
+			if math.Abs(a-b) > serialSecond/2 {
+				return false
+			}
+			continue
+		}
+		if cellString(existing, i) != cellString(row, i) {
+			return false
+		}
+	}
+	return true
+}
+
+// serialSecond - одна секунда в серийном номере даты
+const serialSecond = 1.0 / 86400
+
+func cellNumber(row []interface{}, i int) (float64, bool) {
+	if i >= len(row) {
+		return 0, false
+	}
+	v, ok := row[i].(float64)
+	return v, ok
+}
+
+func cellString(row []interface{}, i int) string {
+	if i >= len(row) || row[i] == nil {
+		return ""
+	}
+	return fmt.Sprint(row[i])
+}
+
+var notionLinkRe = regexp.MustCompile(`notion\.so/([0-9a-fA-F]{32})`)
+
+// NotionID достает ID страницы Notion из ссылки или формулы HYPERLINK в ячейке col
+func NotionID(row []interface{}, col int) string {
+	match := notionLinkRe.FindStringSubmatch(cellString(row, col))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// NotionLinkKey - ключ по ссылке на страницу Notion в столбце col
+func NotionLinkKey(col int) KeyFunc {
+	return func(row []interface{}) string {
+		return NotionID(row, col)
+	}
+}
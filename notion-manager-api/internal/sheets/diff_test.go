@@ -0,0 +1,80 @@
+package gsheets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffRows(t *testing.T) {
+	link := func(id string) string {
+		return `=HYPERLINK("https://notion.so/` + id + `"; "title")`
+	}
+	a := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	b := "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	c := "cccccccccccccccccccccccccccccccc"
+	d := "dddddddddddddddddddddddddddddddd"
+
+	tests := []struct {
+		name     string
+		existing [][]interface{}
+		rows     [][]interface{}
+		want     RowsDiff
+	}{
+		{
+			name:     "unchanged rows are not written",
+			existing: [][]interface{}{{link(a), "1.5", "x"}, {link(b), "2"}},
+			rows:     [][]interface{}{{link(a), 1.5, "x"}, {link(b), 2, ""}},
+			want:     RowsDiff{},
+		},
+		{
+			name:     "changed, new and removed rows",
+			existing: [][]interface{}{{link(a), "1"}, {link(b), "2"}, {link(c), "3"}, {link(d), "4"}},
+			rows:     [][]interface{}{{link(d), 4}, {link(b), 20}, {link(c), 30}, {link("eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"), 5}},
+			want: RowsDiff{
+				Updates: []RowsUpdate{{Start: 1, Values: [][]interface{}{{link(b), 20}, {link(c), 30}}}},
+				Appends: [][]interface{}{{link("eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"), 5}},
+				Deletes: []int{0},
+			},
+		},
+		{
+			name:     "duplicates and rows without key",
+			existing: [][]interface{}{{link(a), "1"}, {"manual row"}, {link(a), "1"}, {link(b), "2"}},
+			rows:     [][]interface{}{{link(a), 1}},
+			want:     RowsDiff{Deletes: []int{3, 2}},
+		},
+		{
+			name:     "dates read back as serial numbers are unchanged",
+			existing: [][]interface{}{{link(a), 45659.0, 45659.625}, {"", "", "Готово", 45659.0}},
+			rows:     [][]interface{}{{link(a), "02/01/2025", "02/01/2025 15:00:00"}, {"", "", "Готово", "02/01/2025"}},
+			want:     RowsDiff{},
+		},
+		{
+			name:     "rows without key replace sheet rows without key in place",
+			existing: [][]interface{}{{"old 1"}, {link(a), "1"}, {"old 2"}, {"manual"}},
+			rows:     [][]interface{}{{link(a), 1}, {"new 1"}, {"old 2"}},
+			want: RowsDiff{
+				Updates: []RowsUpdate{{Start: 0, Values: [][]interface{}{{"new 1"}}}},
+			},
+		},
+		{
+			name:     "extra rows without key are appended",
+			existing: [][]interface{}{{"old 1"}},
+			rows:     [][]interface{}{{"old 1"}, {"new 2"}},
+			want:     RowsDiff{Appends: [][]interface{}{{"new 2"}}},
+		},
+		{
+			name: "empty sheet",
+			rows: [][]interface{}{{link(a), 1}, {link(b), 2}},
+			want: RowsDiff{Appends: [][]interface{}{{link(a), 1}, {link(b), 2}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffRows(tt.existing, tt.rows, NotionLinkKey(0))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffRows() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
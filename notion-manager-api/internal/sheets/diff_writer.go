@@ -0,0 +1,158 @@
+package gsheets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+var ErrSheetNotFound = errors.New("sheet not found")
+
+// SheetID возвращает ID листа по его названию
+func (s *Client) SheetID(ctx context.Context, spreadsheetID, sheetName string) (int64, error) {
+	spreadsheet, err := s.svc.Spreadsheets.Get(spreadsheetID).Context(ctx).Do()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties.Title == sheetName {
+			return sheet.Properties.SheetId, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: '%s'", ErrSheetNotFound, sheetName)
+}
+
+// AddSheet добавляет в таблицу лист с заголовком в первой строке
+func (s *Client) AddSheet(ctx context.Context, spreadsheetID, sheetName string, header []string) error {
+	if _, err := s.svc.Spreadsheets.BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			AddSheet: &sheets.AddSheetRequest{
+				Properties: &sheets.SheetProperties{Title: sheetName},
+			},
+		}},
+	}).Context(ctx).Do(); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, 0, len(header))
+	for _, h := range header {
+		values = append(values, h)
+	}
+	_, err := s.svc.Spreadsheets.Values.Update(spreadsheetID, sheetName+"!A1", &sheets.ValueRange{
+		MajorDimension: "ROWS",
+		Values:         [][]interface{}{values},
+	}).ValueInputOption("RAW").Context(ctx).Do()
+	return err
+}
+
+// ReadRows читает строки листа со 2-й, столбцы A..columns. Формулы приходят текстом формулы,
+// поэтому по ним можно узнать ссылку на страницу Notion. Остальные ячейки приходят без форматирования,
+// как с UNFORMATTED_VALUE, а даты - серийными номерами, чтобы значения не зависели от локали таблицы.
+func (s *Client) ReadRows(ctx context.Context, spreadsheetID, sheetName string, columns int) ([][]interface{}, error) {
+	current, err := s.svc.Spreadsheets.Values.Get(spreadsheetID, sheetName+"!A2:"+columnLetter(columns)).
+		ValueRenderOption("FORMULA").
+		DateTimeRenderOption("SERIAL_NUMBER").
+		Context(ctx).
+		Do()
+	if err != nil {
+		slog.Error("Error reading sheet rows", "error", err, "sheet", sheetName)
+		return nil, err
+	}
+
+	return current.Values, nil
+}
+
+func columnLetter(columns int) string {
+	letter := ""
+	for columns > 0 {
+		columns--
+		letter = string(rune('A'+columns%26)) + letter
+		columns /= 26
+	}
+	return letter
+}
+
+// WriteRows приводит строки листа sheetName (со 2-й строки, столбцы A..columns) к rows:
+// читает текущие строки, перезаписывает только изменившиеся, добавляет новые и удаляет пропавшие.
+// Столбцы правее columns, например ручные заметки, остаются на месте вместе со своей строкой.
+func (s *Client) WriteRows(ctx context.Context, spreadsheetID, sheetName string, columns int, rows [][]interface{}, keyOf KeyFunc) error {
+	lastCol := columnLetter(columns)
+
+	current, err := s.ReadRows(ctx, spreadsheetID, sheetName, columns)
+	if err != nil {
+		return err
+	}
+
+	diff := DiffRows(current, rows, keyOf)
+	if diff.Empty() {
+		return nil
+	}
+
+	if len(diff.Updates) > 0 {
+		data := make([]*sheets.ValueRange, 0, len(diff.Updates))
+		for _, u := range diff.Updates {
+			first := u.Start + 2
+			data = append(data, &sheets.ValueRange{
+				Range:          fmt.Sprintf("%s!A%d:%s%d", sheetName, first, lastCol, first+len(u.Values)-1),
+				MajorDimension: "ROWS",
+				Values:         u.Values,
+			})
+		}
+
+		if _, err := s.svc.Spreadsheets.Values.BatchUpdate(spreadsheetID, &sheets.BatchUpdateValuesRequest{
+			ValueInputOption: "USER_ENTERED",
+			Data:             data,
+		}).Context(ctx).Do(); err != nil {
+			slog.Error("Error updating changed rows", "error", err, "sheet", sheetName)
+			return err
+		}
+	}
+
+	if len(diff.Deletes) > 0 {
+		sheetID, err := s.SheetID(ctx, spreadsheetID, sheetName)
+		if err != nil {
+			slog.Error("Error getting sheet ID by name", "error", err, "sheet", sheetName)
+			return err
+		}
+
+		requests := make([]*sheets.Request, 0, len(diff.Deletes))
+		for _, i := range diff.Deletes {
+			requests = append(requests, &sheets.Request{
+				DeleteDimension: &sheets.DeleteDimensionRequest{
+					Range: &sheets.DimensionRange{
+						SheetId:    sheetID,
+						Dimension:  "ROWS",
+						StartIndex: int64(i + 1),
+						EndIndex:   int64(i + 2),
+					},
+				},
+			})
+		}
+
+		if _, err := s.svc.Spreadsheets.BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: requests,
+		}).Context(ctx).Do(); err != nil {
+			slog.Error("Error deleting removed rows", "error", err, "sheet", sheetName)
+			return err
+		}
+	}
+
+	if len(diff.Appends) > 0 {
+		if _, err := s.svc.Spreadsheets.Values.Append(spreadsheetID, sheetName+"!A2:"+lastCol, &sheets.ValueRange{
+			MajorDimension: "ROWS",
+			Values:         diff.Appends,
+		}).ValueInputOption("USER_ENTERED").InsertDataOption("INSERT_ROWS").Context(ctx).Do(); err != nil {
+			slog.Error("Error appending new rows", "error", err, "sheet", sheetName)
+			return err
+		}
+	}
+
+	slog.Info("Sheet rows written", "sheet", sheetName, "updated", diff.UpdatedRows(), "appended", len(diff.Appends), "deleted", len(diff.Deletes))
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package gsheets
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type HealthStatus string
+
+const (
+	HealthStatusUnknown HealthStatus = "unknown"
+	HealthStatusOK      HealthStatus = "ok"
+	HealthStatusFailing HealthStatus = "failing"
+)
+
+// Health - результат последнего получения токена Google
+type Health struct {
+	Status    HealthStatus `json:"status"`
+	Mode      AuthMode     `json:"mode"`
+	Error     string       `json:"error,omitempty"`
+	CheckedAt *time.Time   `json:"checked_at,omitempty"`
+}
+
+// reloadInterval - как часто после ошибки заново загружать учетные данные
+const reloadInterval = time.Minute
+
+// tokenLoader загружает учетные данные и собирает из них источник токенов
+type tokenLoader func() (oauth2.TokenSource, error)
+
+// healthTokenSource запоминает, удалось ли получить токен в последний раз.
+// Если учетные данные не загрузились или токен перестал обновляться, запрос
+// завершается ошибкой, а не блокирует и не роняет процесс. Не чаще раза в
+// reloadInterval учетные данные загружаются заново, поэтому токен, сохраненный
+// после старта, подхватывается без перезапуска.
+type healthTokenSource struct {
+	load     tokenLoader
+	interval time.Duration
+
+	mu       sync.RWMutex
+	base     oauth2.TokenSource
+	initErr  error
+	loadedAt time.Time
+	health   Health
+}
+
+func newHealthTokenSource(mode AuthMode, load tokenLoader) *healthTokenSource {
+	s := &healthTokenSource{
+		load:     load,
+		interval: reloadInterval,
+		health:   Health{Status: HealthStatusUnknown, Mode: mode},
+	}
+	s.reload()
+	return s
+}
+
+func (s *healthTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.token()
+	if err != nil && s.reloadDue() {
+		if s.reload() == nil {
+			tok, err = s.token()
+		}
+	}
+	s.record(err)
+	return tok, err
+}
+
+func (s *healthTokenSource) token() (*oauth2.Token, error) {
+	s.mu.RLock()
+	base, err := s.base, s.initErr
+	s.mu.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return base.Token()
+}
+
+// reloadDue сообщает, пора ли загрузить учетные данные заново, и занимает попытку,
+// чтобы параллельные запросы не загружали их одновременно
+func (s *healthTokenSource) reloadDue() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.loadedAt) < s.interval {
+		return false
+	}
+	s.loadedAt = time.Now()
+	return true
+}
+
+// reload заново загружает учетные данные, например после повторного согласия
+func (s *healthTokenSource) reload() error {
+	base, err := s.load()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.base = base
+	s.initErr = err
+	s.loadedAt = time.Now()
+	return err
+}
+
+func (s *healthTokenSource) record(err error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.health.CheckedAt = &now
+	if err != nil {
+		s.health.Status = HealthStatusFailing
+		s.health.Error = err.Error()
+		return
+	}
+	s.health.Status = HealthStatusOK
+	s.health.Error = ""
+}
+
+func (s *healthTokenSource) Health() Health {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.health
+}
@@ -0,0 +1,82 @@
+package gsheets
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+type tokenFunc func() (*oauth2.Token, error)
+
+func (f tokenFunc) Token() (*oauth2.Token, error) { return f() }
+
+func TestHealthTokenSource(t *testing.T) {
+	errRevoked := errors.New("invalid_grant")
+	fail := true
+	ts := newHealthTokenSource(AuthModeOAuth, func() (oauth2.TokenSource, error) {
+		return tokenFunc(func() (*oauth2.Token, error) {
+			if fail {
+				return nil, errRevoked
+			}
+			return &oauth2.Token{AccessToken: "token"}, nil
+		}), nil
+	})
+
+	if h := ts.Health(); h.Status != HealthStatusUnknown || h.CheckedAt != nil {
+		t.Fatalf("initial health = %+v, want unknown", h)
+	}
+
+	if _, err := ts.Token(); !errors.Is(err, errRevoked) {
+		t.Fatalf("Token() error = %v, want %v", err, errRevoked)
+	}
+	if h := ts.Health(); h.Status != HealthStatusFailing || h.Error != errRevoked.Error() {
+		t.Fatalf("health after failure = %+v", h)
+	}
+
+	fail = false
+	if _, err := ts.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if h := ts.Health(); h.Status != HealthStatusOK || h.Error != "" || h.CheckedAt == nil {
+		t.Fatalf("health after recovery = %+v", h)
+	}
+}
+
+func TestHealthTokenSourceInitError(t *testing.T) {
+	errNoKey := errors.New("read service account key: no such file")
+	loads := 0
+	var loadErr error = errNoKey
+	ts := newHealthTokenSource(AuthModeServiceAccount, func() (oauth2.TokenSource, error) {
+		loads++
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		return tokenFunc(func() (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "token"}, nil
+		}), nil
+	})
+
+	if _, err := ts.Token(); !errors.Is(err, errNoKey) {
+		t.Fatalf("Token() error = %v, want %v", err, errNoKey)
+	}
+	if h := ts.Health(); h.Status != HealthStatusFailing || h.Mode != AuthModeServiceAccount {
+		t.Fatalf("health = %+v", h)
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, want no reload before the interval passes", loads)
+	}
+
+	// Ключ появился после старта: после интервала учетные данные загружаются заново
+	loadErr = nil
+	ts.interval = 0
+	if _, err := ts.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if h := ts.Health(); h.Status != HealthStatusOK {
+		t.Fatalf("health after reload = %+v", h)
+	}
+	if loads != 2 {
+		t.Fatalf("loads = %d, want 2", loads)
+	}
+}
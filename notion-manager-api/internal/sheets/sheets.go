@@ -1,39 +1,105 @@
 package gsheets
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
-	"os"
+	"sync"
+	"time"
 
-	"golang.org/x/oauth2/google"
+	"github.com/Corray333/employee_dashboard/internal/postgres"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 )
 
+var ErrSheetsUnavailable = errors.New("google sheets credentials are not available")
+
 type Client struct {
-	svc *sheets.Service
+	svc      *sheets.Service
+	driveSvc *drive.Service
+	auth     *healthTokenSource
+
+	cfg    AuthConfig
+	tokens TokenStore
+
+	mu     sync.Mutex
+	states map[string]time.Time
 }
 
-func NewSheetsClient() *Client {
-	b, err := os.ReadFile("../secrets/credentials.json")
-	if err != nil {
-		slog.Error("Unable to read client secret file", "error", err)
-		panic(err)
+// NewSheetsClient собирает клиент по настройкам sheets.auth. Ошибки учетных данных
+// не останавливают сервис: клиент создается всегда, а причина видна в Health.
+func NewSheetsClient(store *postgres.PostgresClient) *Client {
+	ctx := context.Background()
+	cfg := authConfigFromViper()
+
+	var tokens TokenStore
+	switch cfg.TokenStore {
+	case TokenStorePostgres:
+		tokens = NewPostgresTokenStore(store, cfg.TokenPath)
+	default:
+		tokens = NewFileTokenStore(cfg.TokenPath)
 	}
 
-	config, err := google.ConfigFromJSON(b, sheets.SpreadsheetsScope)
+	auth := newHealthTokenSource(cfg.Mode, func() (oauth2.TokenSource, error) {
+		base, err := tokenSource(context.Background(), cfg, tokens)
+		if err != nil {
+			slog.Error("Unable to load Google Sheets credentials", "mode", cfg.Mode, "error", err)
+		}
+		return base, err
+	})
+	httpClient := oauth2.NewClient(ctx, auth)
+
+	svc, err := sheets.NewService(ctx, option.WithHTTPClient(httpClient))
 	if err != nil {
-		slog.Error("Unable to parse client secret file to config", "error", err)
+		slog.Error("Unable to retrieve Sheets Client", "error", err)
 		panic(err)
 	}
-	client := GetClient(config)
 
-	svc, err := sheets.New(client)
+	driveSvc, err := drive.NewService(ctx, option.WithHTTPClient(httpClient))
 	if err != nil {
-		slog.Error("Unable to retrieve Sheets Client", "error", err)
+		slog.Error("Unable to retrieve Drive Client", "error", err)
 		panic(err)
 	}
-	return &Client{svc: svc}
+
+	c := &Client{
+		svc:      svc,
+		driveSvc: driveSvc,
+		auth:     auth,
+		cfg:      cfg,
+		tokens:   tokens,
+		states:   map[string]time.Time{},
+	}
+	go c.Check()
+	return c
 }
 
 func (s *Client) Svc() *sheets.Service {
 	return s.svc
 }
+
+func (s *Client) Drive() *drive.Service {
+	return s.driveSvc
+}
+
+// Check пробует получить токен и возвращает обновленный статус
+func (s *Client) Check() Health {
+	if _, err := s.auth.Token(); err != nil {
+		slog.Error("Google Sheets credentials check failed", "error", err)
+	}
+	return s.auth.Health()
+}
+
+func (s *Client) Health() Health {
+	return s.auth.Health()
+}
+
+// Ready проверяет учетные данные перед выгрузкой, чтобы она сразу завершилась понятной ошибкой
+func (s *Client) Ready() error {
+	if h := s.Check(); h.Status == HealthStatusFailing {
+		return fmt.Errorf("%w: %s", ErrSheetsUnavailable, h.Error)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package gsheets
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/Corray333/employee_dashboard/internal/postgres"
+	"golang.org/x/oauth2"
+)
+
+// FileTokenStore хранит токен в JSON-файле, как раньше хранился secrets/token.json
+type FileTokenStore struct {
+	path string
+}
+
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+func (s *FileTokenStore) GetToken(ctx context.Context) (*oauth2.Token, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	tok := &oauth2.Token{}
+	if err := json.Unmarshal(b, tok); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+func (s *FileTokenStore) SaveToken(ctx context.Context, token *oauth2.Token) error {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0600)
+}
+
+// googleSheetsTokenName - под этим именем токен таблиц лежит в oauth_tokens
+const googleSheetsTokenName = "google_sheets"
+
+// PostgresTokenStore хранит токен в таблице oauth_tokens, чтобы обновленный токен
+// переживал пересоздание контейнера. Если в базе токена еще нет, он один раз
+// переносится из файла seed.
+type PostgresTokenStore struct {
+	*postgres.PostgresClient
+	seed *FileTokenStore
+}
+
+func NewPostgresTokenStore(store *postgres.PostgresClient, seedPath string) *PostgresTokenStore {
+	s := &PostgresTokenStore{PostgresClient: store}
+	if seedPath != "" {
+		s.seed = NewFileTokenStore(seedPath)
+	}
+	return s
+}
+
+func (s *PostgresTokenStore) GetToken(ctx context.Context) (*oauth2.Token, error) {
+	var raw []byte
+	err := s.DB().GetContext(ctx, &raw, `SELECT token FROM oauth_tokens WHERE name = $1`, googleSheetsTokenName)
+	if err == nil {
+		tok := &oauth2.Token{}
+		if err := json.Unmarshal(raw, tok); err != nil {
+			return nil, err
+		}
+		return tok, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if s.seed == nil {
+		return nil, ErrTokenNotFound
+	}
+	tok, err := s.seed.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SaveToken(ctx, tok); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+func (s *PostgresTokenStore) SaveToken(ctx context.Context, token *oauth2.Token) error {
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB().ExecContext(ctx, `
+		INSERT INTO oauth_tokens (name, token, updated_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (name) DO UPDATE SET token = EXCLUDED.token, updated_at = EXCLUDED.updated_at
+	`, googleSheetsTokenName, raw)
+	return err
+}
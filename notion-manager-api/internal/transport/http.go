@@ -8,6 +8,7 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -16,8 +17,11 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/Corray333/employee_dashboard/internal/domains/job/entities/job"
 	"github.com/Corray333/employee_dashboard/internal/entities"
+	gsheets "github.com/Corray333/employee_dashboard/internal/sheets"
 	"github.com/Corray333/employee_dashboard/pkg/auth"
+	"github.com/Corray333/employee_dashboard/pkg/mindmap"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
@@ -38,9 +42,15 @@ type service interface {
 
 	GetTasksOfEmployee(employee_id string, period_start, period_end int64) ([]entities.Task, error)
 
-	UpdateGoogleSheets(ctx context.Context) error
+	UpdateGoogleSheets(ctx context.Context) (*job.Job, error)
+	GetJob(ctx context.Context, id uuid.UUID) (*job.Job, error)
+	SheetsHealth() gsheets.Health
+	SheetsConsentURL() (string, error)
+	CompleteSheetsConsent(ctx context.Context, state, code string) error
 
-	CreateMindmapTasks(mindmap string) error
+	CreateMindmapTasks(data []byte, format mindmap.Format) (*mindmap.ApplyResult, error)
+	PreviewMindmap(data []byte, format mindmap.Format) (*mindmap.Preview, error)
+	ApplyMindmap(projectName string, tasks []mindmap.Task) (*mindmap.ApplyResult, error)
 	GetQuarterTasks() ([]entities.Task, error)
 
 	NotifyEmployeesAboutSalary(ctx context.Context) error
@@ -48,8 +58,15 @@ type service interface {
 	GetUserRole(username string, userID int64) entities.DashboardRole
 
 	DeleteFeedback(ctx context.Context, feedbackID uuid.UUID) error
+
+	GetOverheadRules(ctx context.Context) ([]entities.OverheadRule, error)
+	SetOverheadRule(ctx context.Context, rule *entities.OverheadRule) error
+	DeleteOverheadRule(ctx context.Context, ruleID int64) error
+	PreviewOverheadEstimates(ctx context.Context) ([]entities.OverheadEstimate, error)
+	GetOverheadEstimateLog(ctx context.Context, projectID string) ([]entities.OverheadEstimateLog, error)
 	DeleteTask(ctx context.Context, taskID uuid.UUID) error
 	DeleteTime(ctx context.Context, timeID uuid.UUID) error
+	DeleteWeekday(ctx context.Context, weekdayID uuid.UUID) error
 }
 
 func New(router *chi.Mux, service service) *Transport {
@@ -83,6 +100,9 @@ func (s *Transport) Run() {
 func (t *Transport) RegisterRoutes() {
 
 	t.router.Post("/api/notion-webhooks", t.handleNotionWebhooks)
+	t.router.Get("/api/health/sheets", t.getSheetsHealth)
+	// Google возвращает сюда браузер после согласия, запрос проверяется по state из ссылки
+	t.router.Get("/api/sheets/oauth/callback", t.completeSheetsConsent)
 
 	t.router.Group(func(r chi.Router) {
 		env := os.Getenv("ENV")
@@ -98,9 +118,19 @@ func (t *Transport) RegisterRoutes() {
 		})
 		r.Get("/api/tasks/employee/{employee_username}", t.getTasksOfEmployee)
 		r.Post("/api/update-sheets", t.updateGoogleSheets)
+		r.Get("/api/jobs/{id}", t.getJob)
+		r.Get("/api/sheets/oauth/url", t.getSheetsConsentURL)
 		r.Post("/api/mindmap", t.parseMindmap)
+		r.Post("/api/mindmap/preview", t.previewMindmap)
+		r.Post("/api/mindmap/apply", t.applyMindmap)
 		r.Get("/api/quarter-tasks", t.getQuarterTasks)
 		r.Post("/api/salary-notify", t.notifyEmployeesAboutSalary)
+
+		r.Get("/api/overheads/rules", t.getOverheadRules)
+		r.Post("/api/overheads/rules", t.setOverheadRule)
+		r.Delete("/api/overheads/rules/{ruleID}", t.deleteOverheadRule)
+		r.Get("/api/overheads/preview", t.previewOverheadEstimates)
+		r.Get("/api/overheads/log", t.getOverheadEstimateLog)
 	})
 
 	t.router.Group(func(r chi.Router) {
@@ -181,6 +211,17 @@ func (t *Transport) handleNotionWebhooks(w http.ResponseWriter, r *http.Request)
 					http.Error(w, fmt.Sprintf("Error deleting time: %s", err.Error()), http.StatusInternalServerError)
 					return
 				}
+			} else if parentID == viper.GetString("notion.databases.weekday") {
+				weekdayID, err := uuid.Parse(data.Entity.ID)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("Error parsing weekday ID: %s", err.Error()), http.StatusBadRequest)
+					return
+				}
+
+				if err := t.service.DeleteWeekday(r.Context(), weekdayID); err != nil {
+					http.Error(w, fmt.Sprintf("Error deleting weekday: %s", err.Error()), http.StatusInternalServerError)
+					return
+				}
 			}
 		}
 	}
@@ -425,44 +466,210 @@ func (t *Transport) getTasksOfEmployee(w http.ResponseWriter, r *http.Request) {
 
 // UpdateGoogleSheets godoc
 // @Summary Update Google Sheets with the latest data
-// @Description Updates Google Sheets with the latest data from the system.
+// @Description Queues an export of the latest data to Google Sheets. Repeated requests join the export that is still queued.
 // @Tags sheets
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Success 200 {string} string "Sheets updated successfully"
+// @Success 202 {object} job.Job "Queued export job"
 // @Failure 500 {string} string "Internal Server Error"
 // @Router /api/update-sheets [post]
 func (t *Transport) updateGoogleSheets(w http.ResponseWriter, r *http.Request) {
-	if err := t.service.UpdateGoogleSheets(r.Context()); err != nil {
+	j, err := t.service.UpdateGoogleSheets(r.Context())
+	if err != nil {
 		http.Error(w, fmt.Sprintf("Error updating Google Sheets: %s", err.Error()), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Sheets updated successfully"))
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+// GetJob godoc
+// @Summary Get background job status
+// @Description Returns status, progress and per-step errors of a background job.
+// @Tags jobs
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Job ID"
+// @Success 200 {object} job.Job
+// @Failure 404 {string} string "Job not found"
+// @Router /api/jobs/{id} [get]
+func (t *Transport) getJob(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	j, err := t.service.GetJob(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, job.ErrJobNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, j)
+}
+
+// GetSheetsHealth godoc
+// @Summary Google Sheets credentials health
+// @Description Returns whether the service can obtain a Google token. Responds with 503 while credentials are failing.
+// @Tags health
+// @Produce json
+// @Success 200 {object} gsheets.Health
+// @Failure 503 {object} gsheets.Health
+// @Router /api/health/sheets [get]
+func (t *Transport) getSheetsHealth(w http.ResponseWriter, r *http.Request) {
+	health := t.service.SheetsHealth()
+	if health.Status == gsheets.HealthStatusFailing {
+		writeJSON(w, http.StatusServiceUnavailable, health)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, health)
+}
+
+// GetSheetsConsentURL godoc
+// @Summary Google re-consent link
+// @Description Returns a link to the Google consent screen. Open it to grant all scopes again, e.g. Drive for project spreadsheets. Only for oauth mode.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 409 {string} string "Credentials are not in oauth mode"
+// @Router /api/sheets/oauth/url [get]
+func (t *Transport) getSheetsConsentURL(w http.ResponseWriter, r *http.Request) {
+	url, err := t.service.SheetsConsentURL()
+	if err != nil {
+		if errors.Is(err, gsheets.ErrConsentUnsupported) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		slog.Error("Error building google consent url", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"url": url})
+}
+
+func (t *Transport) completeSheetsConsent(w http.ResponseWriter, r *http.Request) {
+	if errMsg := r.URL.Query().Get("error"); errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.CompleteSheetsConsent(r.Context(), r.URL.Query().Get("state"), r.URL.Query().Get("code")); err != nil {
+		if errors.Is(err, gsheets.ErrInvalidConsentState) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("Error completing google consent", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "Доступ к Google выдан, окно можно закрыть")
+}
+
+// readMindmapFile читает файл майндмапы и определяет его формат по content type части формы
+func readMindmapFile(r *http.Request) ([]byte, mindmap.Format, error) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	maxSize := viper.GetInt64("mindmap.max_size")
+	if maxSize <= 0 {
+		maxSize = mindmap.DefaultMaxSize
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", mindmap.ErrTooLarge
+	}
+
+	return data, mindmap.DetectFormat(header.Header.Get("Content-Type"), header.Filename), nil
+}
+
+func writeMindmapResult(w http.ResponseWriter, result *mindmap.ApplyResult, err error) {
+	if result == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		slog.Error(err.Error())
+	}
 }
 
 func (t *Transport) parseMindmap(w http.ResponseWriter, r *http.Request) {
-	file, _, err := r.FormFile("file")
+	data, format, err := readMindmapFile(r)
 	if err != nil {
 		slog.Error(err.Error())
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	data, err := io.ReadAll(file)
+	result, err := t.service.CreateMindmapTasks(data, format)
 	if err != nil {
 		slog.Error(err.Error())
-		w.WriteHeader(http.StatusInternalServerError)
+	}
+	writeMindmapResult(w, result, err)
+}
+
+func (t *Transport) previewMindmap(w http.ResponseWriter, r *http.Request) {
+	data, format, err := readMindmapFile(r)
+	if err != nil {
+		slog.Error(err.Error())
+		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	if err := t.service.CreateMindmapTasks(string(data)); err != nil {
+	preview, err := t.service.PreviewMindmap(data, format)
+	if err != nil {
 		slog.Error(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(preview); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+type applyMindmapRequest struct {
+	ProjectName string         `json:"project_name"`
+	Tasks       []mindmap.Task `json:"tasks"`
+}
+
+func (t *Transport) applyMindmap(w http.ResponseWriter, r *http.Request) {
+	req := applyMindmapRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Tasks) == 0 {
+		http.Error(w, "no tasks to create", http.StatusBadRequest)
+		return
+	}
+
+	result, err := t.service.ApplyMindmap(req.ProjectName, req.Tasks)
+	if err != nil {
+		slog.Error(err.Error())
+	}
+	writeMindmapResult(w, result, err)
 }
 
 // GetQuarterTasks godoc
@@ -506,3 +713,70 @@ func (t *Transport) notifyEmployeesAboutSalary(w http.ResponseWriter, r *http.Re
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Notifications sent successfully"))
 }
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+func (t *Transport) getOverheadRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := t.service.GetOverheadRules(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+func (t *Transport) setOverheadRule(w http.ResponseWriter, r *http.Request) {
+	rule := &entities.OverheadRule{}
+	if err := json.NewDecoder(r.Body).Decode(rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.SetOverheadRule(r.Context(), rule); err != nil {
+		if errors.Is(err, entities.ErrInvalidOverheadRule) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, rule)
+}
+
+func (t *Transport) deleteOverheadRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := strconv.ParseInt(chi.URLParam(r, "ruleID"), 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := t.service.DeleteOverheadRule(r.Context(), ruleID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *Transport) previewOverheadEstimates(w http.ResponseWriter, r *http.Request) {
+	estimates, err := t.service.PreviewOverheadEstimates(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, estimates)
+}
+
+func (t *Transport) getOverheadEstimateLog(w http.ResponseWriter, r *http.Request) {
+	logs, err := t.service.GetOverheadEstimateLog(r.Context(), r.URL.Query().Get("project_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, logs)
+}
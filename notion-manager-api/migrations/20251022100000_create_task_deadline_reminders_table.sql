@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS task_deadline_reminders (
+    task_id UUID NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    deadline TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (task_id, kind, deadline)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_deadline_reminders;
+-- +goose StatementEnd
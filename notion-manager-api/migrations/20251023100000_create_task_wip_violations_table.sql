@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS task_wip_violations (
+    scope TEXT PRIMARY KEY,
+    in_progress INT NOT NULL,
+    notified_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_wip_violations;
+-- +goose StatementEnd
@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS task_templates (
+    template_id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS task_template_items (
+    item_id BIGSERIAL PRIMARY KEY,
+    template_id BIGINT NOT NULL REFERENCES task_templates(template_id) ON DELETE CASCADE,
+    parent_item_id BIGINT REFERENCES task_template_items(item_id) ON DELETE CASCADE,
+    position INT NOT NULL DEFAULT 0,
+    title TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT '',
+    start_offset_days INT NOT NULL DEFAULT 0,
+    duration_days INT NOT NULL DEFAULT 0,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    estimate TEXT NOT NULL DEFAULT '',
+    priority TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS task_template_items_template_idx ON task_template_items (template_id);
+
+CREATE TABLE IF NOT EXISTS project_role_tasks (
+    project_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    task_id UUID NOT NULL,
+    PRIMARY KEY (project_id, role)
+);
+
+-- attempts - неудачные попытки обработать сообщение, после последней оно остается в outbox с last_error
+ALTER TABLE task_outbox
+    ADD COLUMN parent_msg_id BIGINT REFERENCES task_outbox(task_msg_id) ON DELETE SET NULL,
+    ADD COLUMN parent_task_id UUID,
+    ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN role TEXT NOT NULL DEFAULT '',
+    ADD COLUMN created_task_id UUID,
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE task_outbox
+    DROP COLUMN parent_msg_id,
+    DROP COLUMN parent_task_id,
+    DROP COLUMN tags,
+    DROP COLUMN role,
+    DROP COLUMN created_task_id,
+    DROP COLUMN attempts,
+    DROP COLUMN last_error;
+
+DROP TABLE IF EXISTS project_role_tasks;
+DROP TABLE IF EXISTS task_template_items;
+DROP TABLE IF EXISTS task_templates;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS overhead_rules (
+    rule_id BIGSERIAL PRIMARY KEY,
+    project_type TEXT NOT NULL DEFAULT '',
+    project_id TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL,
+    title_prefix TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    value FLOAT NOT NULL DEFAULT 0,
+    cap FLOAT NOT NULL DEFAULT 0,
+    UNIQUE (project_type, project_id, role)
+);
+
+-- Значения, которые раньше были зашиты в updateProjectsEstimates
+INSERT INTO overhead_rules (role, title_prefix, kind, value) VALUES
+    ('management', 'Менеджмент', 'percent', 20),
+    ('testing', 'Тестирование', 'percent', 15);
+
+CREATE TABLE IF NOT EXISTS overhead_estimate_log (
+    log_id BIGSERIAL PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    task_id TEXT NOT NULL,
+    base_hours FLOAT NOT NULL,
+    old_estimate FLOAT NOT NULL,
+    new_estimate FLOAT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS overhead_estimate_log_project_idx ON overhead_estimate_log (project_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS overhead_estimate_log;
+DROP TABLE IF EXISTS overhead_rules;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS project_budgets (
+    project_id UUID PRIMARY KEY,
+    hours FLOAT NOT NULL,
+    money FLOAT NOT NULL DEFAULT 0,
+    hour_rate FLOAT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS project_budget_alerts (
+    project_id UUID NOT NULL REFERENCES project_budgets(project_id) ON DELETE CASCADE,
+    threshold INT NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, threshold)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS project_budget_alerts;
+DROP TABLE IF EXISTS project_budgets;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE jobs (
+    job_id UUID PRIMARY KEY,
+    kind TEXT NOT NULL,
+    key TEXT NOT NULL,
+    status TEXT NOT NULL,
+    steps JSONB NOT NULL DEFAULT '[]',
+    progress INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+-- В очереди может стоять только одно задание одного вида для одного ключа, повторные запросы к нему присоединяются
+CREATE UNIQUE INDEX jobs_queued_kind_key_idx ON jobs (kind, key) WHERE status = 'queued';
+CREATE INDEX jobs_key_status_idx ON jobs (key, status, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE jobs;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Значения редактируемых ячеек листа задач на момент последней выгрузки в таблицу
+CREATE TABLE task_sheet_cells (
+    spreadsheet_id TEXT NOT NULL,
+    task_id UUID NOT NULL,
+    field TEXT NOT NULL,
+    value TEXT NOT NULL,
+    exported_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (spreadsheet_id, task_id, field)
+);
+
+CREATE TABLE task_sheet_edits (
+    edit_id BIGSERIAL PRIMARY KEY,
+    spreadsheet_id TEXT NOT NULL,
+    task_id UUID NOT NULL,
+    task_name TEXT NOT NULL DEFAULT '',
+    field TEXT NOT NULL,
+    value TEXT NOT NULL,
+    base_value TEXT NOT NULL,
+    notion_value TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    -- Неудачные отправки правки в Notion откладываются, а после последней попытки правка получает статус failed
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Повторная правка той же ячейки до отправки в Notion заменяет ожидающую
+CREATE UNIQUE INDEX task_sheet_edits_pending_idx ON task_sheet_edits (spreadsheet_id, task_id, field) WHERE status = 'pending';
+CREATE INDEX task_sheet_edits_spreadsheet_idx ON task_sheet_edits (spreadsheet_id, status, created_at);
+CREATE INDEX task_sheet_edits_due_idx ON task_sheet_edits (next_attempt_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE task_sheet_edits;
+DROP TABLE task_sheet_cells;
+-- +goose StatementEnd
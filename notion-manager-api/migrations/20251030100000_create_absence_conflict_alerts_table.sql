@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Конфликты отсутствий, о которых уже предупредили менеджеров
+CREATE TABLE absence_conflict_alerts (
+    signature TEXT PRIMARY KEY,
+    scope TEXT NOT NULL,
+    key TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    notified_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX absence_conflict_alerts_end_date_idx ON absence_conflict_alerts (end_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE absence_conflict_alerts;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Остаток отпуска на начало учета (vacations.since_year), employee_id - profile_id сотрудника, как в weekdays
+CREATE TABLE vacation_opening_balances (
+    employee_id UUID PRIMARY KEY,
+    days NUMERIC NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE vacation_opening_balances;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Токены подписки на iCalendar-фиды, хранится только sha256 токена. employee_id - profile_id сотрудника
+CREATE TABLE ical_tokens (
+    token_hash TEXT PRIMARY KEY,
+    employee_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX ical_tokens_employee_id_idx ON ical_tokens (employee_id) WHERE revoked_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE ical_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Руководители направлений, employee_id - employees.employee_id
+CREATE TABLE direction_leads (
+    direction TEXT PRIMARY KEY,
+    employee_id UUID NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Чаты и темы форумов, подписанные на уведомления об отсутствиях. Пустое направление - все направления, thread_id 0 - весь чат
+CREATE TABLE weekday_notification_subscriptions (
+    subscription_id BIGSERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL,
+    thread_id BIGINT NOT NULL DEFAULT 0,
+    direction TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (chat_id, thread_id, direction)
+);
+
+-- Менеджеры, которые раньше были зашиты в коде, продолжают получать все уведомления
+INSERT INTO weekday_notification_subscriptions (chat_id, title) VALUES
+    (377742748, 'Менеджеры'),
+    (373352303, 'Менеджеры'),
+    (56218566, 'Менеджеры'),
+    (795836353, 'Менеджеры');
+
+-- Отправленные уведомления об отсутствиях. message_id 0 - сообщение еще не доставлено, attempts - неудачные попытки отправить text
+CREATE TABLE weekday_notification_messages (
+    weekday_id UUID NOT NULL,
+    chat_id BIGINT NOT NULL,
+    thread_id BIGINT NOT NULL DEFAULT 0,
+    message_id BIGINT NOT NULL DEFAULT 0,
+    text TEXT NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (weekday_id, chat_id, thread_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE weekday_notification_messages;
+DROP TABLE weekday_notification_subscriptions;
+DROP TABLE direction_leads;
+-- +goose StatementEnd
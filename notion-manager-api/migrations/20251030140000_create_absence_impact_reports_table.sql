@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Отчеты менеджерам проектов о задачах, попадающих на отсутствие сотрудника
+CREATE TABLE absence_impact_reports (
+    weekday_id UUID NOT NULL,
+    project_id UUID NOT NULL,
+    chat_id BIGINT NOT NULL,
+    message_id BIGINT NOT NULL,
+    text TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (weekday_id, project_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE absence_impact_reports;
+-- +goose StatementEnd
@@ -4,5 +4,6 @@ type Task struct {
 	Title    string  `json:"title"`
 	Link     string  `json:"link"`
 	Hours    float64 `json:"hours"`
+	TaskID   string  `json:"task_id,omitempty"` // ID существующей задачи Notion, найденной по ссылке
 	Subtasks []Task  `json:"subtasks"`
 }
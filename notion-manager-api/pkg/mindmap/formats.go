@@ -0,0 +1,146 @@
+package mindmap
+
+import (
+	"errors"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported mindmap format")
+	ErrTooLarge          = errors.New("mindmap is too large")
+)
+
+// DefaultMaxSize - ограничение размера майндмапы, если в конфиге оно не задано
+const DefaultMaxSize = 10 << 20
+
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatOPML     Format = "opml"
+	FormatFreeMind Format = "freemind"
+	FormatXMind    Format = "xmind"
+)
+
+var contentTypeFormats = map[string]Format{
+	"text/markdown":                  FormatMarkdown,
+	"text/x-markdown":                FormatMarkdown,
+	"text/plain":                     FormatMarkdown,
+	"text/x-opml":                    FormatOPML,
+	"text/x-opml+xml":                FormatOPML,
+	"application/x-opml":             FormatOPML,
+	"application/x-freemind":         FormatFreeMind,
+	"application/vnd.xmind.workbook": FormatXMind,
+	"application/x-xmind":            FormatXMind,
+}
+
+var extensionFormats = map[string]Format{
+	".md":       FormatMarkdown,
+	".markdown": FormatMarkdown,
+	".txt":      FormatMarkdown,
+	".opml":     FormatOPML,
+	".mm":       FormatFreeMind,
+	".xmind":    FormatXMind,
+}
+
+// DetectFormat определяет формат по расширению файла, а если оно неизвестно - по content type.
+// Расширение проверяется первым: браузеры часто отправляют .opml и .mm как text/plain
+// или application/octet-stream
+func DetectFormat(contentType, filename string) Format {
+	if format, ok := extensionFormats[strings.ToLower(filepath.Ext(filename))]; ok {
+		return format
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if format, ok := contentTypeFormats[strings.ToLower(mediaType)]; ok {
+		return format
+	}
+	return FormatMarkdown
+}
+
+// Parse разбирает майндмапу в указанном формате, возвращая название проекта и дерево задач.
+// maxSize ограничивает размер распакованного содержимого для архивных форматов, 0 - DefaultMaxSize
+func Parse(format Format, data []byte, maxSize int64) (string, []Task, error) {
+	switch format {
+	case FormatMarkdown, "":
+		return ParseMarkdownTasks(string(data))
+	case FormatOPML:
+		return ParseOPML(data)
+	case FormatFreeMind:
+		return ParseFreeMind(data)
+	case FormatXMind:
+		return ParseXMind(data, maxSize)
+	default:
+		return "", nil, ErrUnsupportedFormat
+	}
+}
+
+// node - узел майндмапы в форматах с явной структурой, до приведения к Task
+type node struct {
+	title    string
+	link     string
+	note     string
+	children []node
+}
+
+var noteHoursRegex = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(?:h|hours?|ч|час(?:а|ов)?)(?:[^\p{L}]|$)`)
+
+// parseHours читает часы из узла-числа (как в markdown-диалекте) или из заметки вида "8", "8h", "8 ч"
+func parseHours(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+	if num, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64); err == nil {
+		return num, true
+	}
+	match := noteHoursRegex.FindStringSubmatch(text)
+	if match == nil {
+		return 0, false
+	}
+	num, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", "."), 64)
+	return num, err == nil
+}
+
+func toTasks(nodes []node) []Task {
+	tasks := []Task{}
+	for _, n := range nodes {
+		task := Task{Title: strings.TrimSpace(n.title), Link: n.link}
+		if hours, ok := parseHours(n.note); ok {
+			task.Hours += hours
+		}
+		for _, child := range n.children {
+			// Узел-число без детей - это часы родителя, как в markdown-диалекте
+			if hours, err := strconv.ParseFloat(strings.TrimSpace(child.title), 64); err == nil && len(child.children) == 0 {
+				task.Hours += hours
+				continue
+			}
+			task.Subtasks = append(task.Subtasks, toTasks([]node{child})...)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// fromRoots приводит корни к результату Parse: единственный корень - это проект
+func fromRoots(projectName string, roots []node) (string, []Task, error) {
+	if len(roots) == 1 {
+		projectName = strings.TrimSpace(roots[0].title)
+		roots = roots[0].children
+	}
+
+	tasks := toTasks(roots)
+	// Узлы-числа верхнего уровня не относятся ни к одной задаче
+	filtered := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		if _, err := strconv.ParseFloat(task.Title, 64); err == nil && len(task.Subtasks) == 0 {
+			continue
+		}
+		filtered = append(filtered, task)
+	}
+
+	for i := range filtered {
+		SumTaskHours(&filtered[i])
+	}
+	return projectName, filtered, nil
+}
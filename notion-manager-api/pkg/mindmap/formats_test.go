@@ -0,0 +1,139 @@
+package mindmap
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var expectedFormatTasks = []Task{
+	{
+		Title: "Backend",
+		Hours: 12,
+		Subtasks: []Task{
+			{Title: "Auth", Link: "https://notion.so/1f2e3d4c5b6a79801f2e3d4c5b6a7980", Hours: 8},
+			{Title: "Payments", Hours: 4},
+		},
+	},
+	{Title: "Design", Hours: 6},
+}
+
+func TestParseOPML(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Shop</title></head>
+  <body>
+    <outline text="Shop">
+      <outline text="Backend">
+        <outline text="Auth" url="https://notion.so/1f2e3d4c5b6a79801f2e3d4c5b6a7980">
+          <outline text="8"/>
+        </outline>
+        <outline text="Payments" _note="Оценка: 4 часа"/>
+      </outline>
+      <outline text="Design" _note="6h"/>
+    </outline>
+  </body>
+</opml>`
+
+	assertParsed(t, FormatOPML, []byte(data))
+}
+
+func TestParseFreeMind(t *testing.T) {
+	data := `<map version="1.0.1">
+<node TEXT="Shop">
+  <node TEXT="Backend">
+    <node TEXT="Auth" LINK="https://notion.so/1f2e3d4c5b6a79801f2e3d4c5b6a7980">
+      <node TEXT="8"/>
+    </node>
+    <node TEXT="Payments">
+      <richcontent TYPE="NOTE"><html><body><p>4 ч</p></body></html></richcontent>
+    </node>
+  </node>
+  <node TEXT="Design"><node TEXT="6"/></node>
+</node>
+</map>`
+
+	assertParsed(t, FormatFreeMind, []byte(data))
+}
+
+func TestParseXMind(t *testing.T) {
+	content := `[{"title": "Sheet 1", "rootTopic": {"title": "Shop", "children": {"attached": [
+		{"title": "Backend", "children": {"attached": [
+			{"title": "Auth", "href": "https://notion.so/1f2e3d4c5b6a79801f2e3d4c5b6a7980", "children": {"attached": [{"title": "8"}]}},
+			{"title": "Payments", "notes": {"plain": {"content": "4"}}}
+		]}},
+		{"title": "Design", "notes": {"plain": {"content": "6 hours, без анимаций"}}}
+	]}}}]`
+
+	assertParsed(t, FormatXMind, xmindArchive(t, content))
+}
+
+func TestParseXMindTooLarge(t *testing.T) {
+	content := `[{"title": "Sheet 1", "rootTopic": {"title": "` + strings.Repeat("a", 1024) + `"}}]`
+
+	if _, _, err := Parse(FormatXMind, xmindArchive(t, content), 512); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func xmindArchive(t *testing.T, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("content.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func assertParsed(t *testing.T, format Format, data []byte) {
+	t.Helper()
+
+	projectName, tasks, err := Parse(format, data, DefaultMaxSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projectName != "Shop" {
+		t.Errorf("expected project Shop, got %q", projectName)
+	}
+	if !reflect.DeepEqual(tasks, expectedFormatTasks) {
+		t.Errorf("unexpected tasks:\nexpected %+v\ngot      %+v", expectedFormatTasks, tasks)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		contentType string
+		filename    string
+		expects     Format
+	}{
+		{"text/markdown; charset=utf-8", "plan.md", FormatMarkdown},
+		{"text/x-opml", "plan", FormatOPML},
+		{"application/xml", "plan.opml", FormatOPML},
+		{"application/octet-stream", "plan.mm", FormatFreeMind},
+		{"application/zip", "Plan.XMIND", FormatXMind},
+		{"text/plain", "plan.opml", FormatOPML},
+		{"text/plain", "plan.mm", FormatFreeMind},
+		{"text/plain", "plan.txt", FormatMarkdown},
+		{"application/vnd.xmind.workbook", "", FormatXMind},
+		{"", "plan", FormatMarkdown},
+	}
+
+	for _, tc := range tests {
+		if got := DetectFormat(tc.contentType, tc.filename); got != tc.expects {
+			t.Errorf("DetectFormat(%q, %q) = %q, expected %q", tc.contentType, tc.filename, got, tc.expects)
+		}
+	}
+}
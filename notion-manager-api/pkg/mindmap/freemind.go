@@ -0,0 +1,66 @@
+package mindmap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+type freeMindMap struct {
+	Nodes []freeMindNode `xml:"node"`
+}
+
+type freeMindNode struct {
+	Text     string             `xml:"TEXT,attr"`
+	Link     string             `xml:"LINK,attr"`
+	Rich     []freeMindRichText `xml:"richcontent"`
+	Children []freeMindNode     `xml:"node"`
+}
+
+type freeMindRichText struct {
+	Type    string `xml:"TYPE,attr"`
+	Content string `xml:",innerxml"`
+}
+
+func plainText(htmlContent string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTagRegex.ReplaceAllString(htmlContent, " ")))
+}
+
+func (f freeMindNode) toNode() node {
+	n := node{title: f.Text, link: f.Link}
+	for _, rich := range f.Rich {
+		switch rich.Type {
+		case "NOTE":
+			n.note = plainText(rich.Content)
+		case "NODE":
+			if n.title == "" {
+				n.title = plainText(rich.Content)
+			}
+		}
+	}
+	for _, child := range f.Children {
+		n.children = append(n.children, child.toNode())
+	}
+	return n
+}
+
+// ParseFreeMind разбирает FreeMind/Freeplane .mm
+func ParseFreeMind(data []byte) (string, []Task, error) {
+	doc := freeMindMap{}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return "", nil, err
+	}
+
+	roots := make([]node, 0, len(doc.Nodes))
+	for _, n := range doc.Nodes {
+		roots = append(roots, n.toNode())
+	}
+
+	return fromRoots("", roots)
+}
@@ -0,0 +1,55 @@
+package mindmap
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+type opmlDocument struct {
+	Title    string        `xml:"head>title"`
+	Outlines []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	URL      string        `xml:"url,attr"`
+	HTMLURL  string        `xml:"htmlUrl,attr"`
+	Note     string        `xml:"_note,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+func (o opmlOutline) toNode() node {
+	n := node{title: o.Text, link: o.URL, note: o.Note}
+	if n.title == "" {
+		n.title = o.Title
+	}
+	if n.link == "" {
+		n.link = o.HTMLURL
+	}
+	title, link := extractLink(n.title)
+	if link != "" && n.link == "" {
+		n.title, n.link = title, link
+	}
+	for _, child := range o.Outlines {
+		n.children = append(n.children, child.toNode())
+	}
+	return n
+}
+
+// ParseOPML разбирает OPML (MindNode, XMind, OmniOutliner)
+func ParseOPML(data []byte) (string, []Task, error) {
+	doc := opmlDocument{}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return "", nil, err
+	}
+
+	roots := make([]node, 0, len(doc.Outlines))
+	for _, outline := range doc.Outlines {
+		roots = append(roots, outline.toNode())
+	}
+
+	return fromRoots(doc.Title, roots)
+}
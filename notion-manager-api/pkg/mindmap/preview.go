@@ -0,0 +1,94 @@
+package mindmap
+
+import (
+	"fmt"
+	"strings"
+)
+
+type WarningCode string
+
+const (
+	WarningMissingHours    WarningCode = "missing_hours"
+	WarningDuplicateTitle  WarningCode = "duplicate_title"
+	WarningProjectNotFound WarningCode = "project_not_found"
+	WarningNoTasks         WarningCode = "no_tasks"
+)
+
+type Warning struct {
+	Code    WarningCode `json:"code"`
+	Path    string      `json:"path,omitempty"`
+	Message string      `json:"message"`
+}
+
+// Preview - результат разбора майндмапы без создания задач в Notion
+type Preview struct {
+	ProjectName string    `json:"project_name"`
+	ProjectID   string    `json:"project_id,omitempty"`
+	TotalHours  float64   `json:"total_hours"`
+	NewTasks    int       `json:"new_tasks"`
+	Tasks       []Task    `json:"tasks"`
+	Warnings    []Warning `json:"warnings"`
+}
+
+// CreatedPage - созданная в Notion страница задачи
+type CreatedPage struct {
+	Title    string `json:"title"`
+	PageID   string `json:"page_id"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// ApplyResult - результат создания задач из майндмапы.
+// При ошибке содержит уже созданные страницы, чтобы их можно было найти и удалить.
+type ApplyResult struct {
+	ProjectName string        `json:"project_name"`
+	ProjectID   string        `json:"project_id,omitempty"`
+	Created     []CreatedPage `json:"created"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// Validate проверяет дерево задач: часы у листьев и уникальность названий среди соседей
+func Validate(tasks []Task) []Warning {
+	warnings := []Warning{}
+	if len(tasks) == 0 {
+		return append(warnings, Warning{Code: WarningNoTasks, Message: "В майндмапе не найдено задач"})
+	}
+	validate(tasks, nil, &warnings)
+	return warnings
+}
+
+func validate(tasks []Task, path []string, warnings *[]Warning) {
+	seen := map[string]bool{}
+	for _, task := range tasks {
+		taskPath := append(append([]string{}, path...), task.Title)
+		joined := strings.Join(taskPath, " / ")
+
+		key := strings.ToLower(strings.TrimSpace(task.Title))
+		if seen[key] {
+			*warnings = append(*warnings, Warning{
+				Code:    WarningDuplicateTitle,
+				Path:    joined,
+				Message: fmt.Sprintf("Задача «%s» встречается на одном уровне несколько раз", task.Title),
+			})
+		}
+		seen[key] = true
+
+		if len(task.Subtasks) == 0 && task.Hours == 0 {
+			*warnings = append(*warnings, Warning{
+				Code:    WarningMissingHours,
+				Path:    joined,
+				Message: fmt.Sprintf("У задачи «%s» не указаны часы", task.Title),
+			})
+		}
+
+		validate(task.Subtasks, taskPath, warnings)
+	}
+}
+
+// TotalHours возвращает сумму часов задач верхнего уровня (часы уже свернуты по подзадачам)
+func TotalHours(tasks []Task) float64 {
+	total := 0.0
+	for _, task := range tasks {
+		total += task.Hours
+	}
+	return total
+}
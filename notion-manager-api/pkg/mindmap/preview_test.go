@@ -0,0 +1,44 @@
+package mindmap
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	data := "# Shop\n" +
+		"## Backend\n" +
+		"### Auth\n" +
+		"- 8\n" +
+		"### auth\n" +
+		"- 4\n" +
+		"### Payments\n" +
+		"## Design\n" +
+		"- 6\n"
+
+	projectName, tasks, err := ParseMarkdownTasks(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projectName != "Shop" {
+		t.Errorf("expected project Shop, got %q", projectName)
+	}
+	if total := TotalHours(tasks); total != 18 {
+		t.Errorf("expected 18 total hours, got %v", total)
+	}
+
+	warnings := Validate(tasks)
+	expected := []Warning{
+		{Code: WarningDuplicateTitle, Path: "Backend / auth"},
+		{Code: WarningMissingHours, Path: "Backend / Payments"},
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %+v", len(expected), warnings)
+	}
+	for i, w := range warnings {
+		if w.Code != expected[i].Code || w.Path != expected[i].Path {
+			t.Errorf("warning %d = %s %q, expected %s %q", i, w.Code, w.Path, expected[i].Code, expected[i].Path)
+		}
+	}
+
+	if warnings := Validate(nil); len(warnings) != 1 || warnings[0].Code != WarningNoTasks {
+		t.Errorf("expected no_tasks warning, got %+v", warnings)
+	}
+}
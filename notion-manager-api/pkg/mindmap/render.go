@@ -0,0 +1,95 @@
+package mindmap
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var notionPageIDRegex = regexp.MustCompile(`(?i)notion\.so/(?:[^?#]*[-/])?([0-9a-f]{32})(?:[?#].*)?$`)
+
+// PageLink возвращает ссылку на страницу Notion по ее ID
+func PageLink(pageID string) string {
+	return "https://notion.so/" + strings.ReplaceAll(pageID, "-", "")
+}
+
+// PageIDFromLink извлекает ID страницы Notion из ссылки, "" - если ссылка не на Notion
+func PageIDFromLink(link string) string {
+	match := notionPageIDRegex.FindStringSubmatch(link)
+	if match == nil {
+		return ""
+	}
+	id := strings.ToLower(match[1])
+	return id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:]
+}
+
+// RenderMarkdown собирает майндмапу в том же диалекте, который читает ParseMarkdownTasks.
+// Hours у задач должны быть собственными, без учета подзадач: они записываются отдельным
+// узлом-числом и при разборе снова сворачиваются.
+func RenderMarkdown(projectName string, tasks []Task) string {
+	var sb strings.Builder
+	sb.WriteString("# " + projectName + "\n")
+	for _, task := range tasks {
+		renderTask(&sb, &task, 2)
+	}
+	return sb.String()
+}
+
+func renderTask(sb *strings.Builder, task *Task, level int) {
+	title := task.Title
+	if task.Link != "" {
+		title = "[" + title + "](" + task.Link + ")"
+	}
+	sb.WriteString(linePrefix(level) + title + "\n")
+
+	if task.Hours > 0 {
+		sb.WriteString(linePrefix(level+1) + strconv.FormatFloat(task.Hours, 'f', -1, 64) + "\n")
+	}
+
+	for i := range task.Subtasks {
+		renderTask(sb, &task.Subtasks[i], level+1)
+	}
+}
+
+// linePrefix повторяет detectLevel: до третьего уровня заголовки, дальше - список с отступами
+func linePrefix(level int) string {
+	if level < 4 {
+		return strings.Repeat("#", level) + " "
+	}
+	return strings.Repeat("    ", level-4) + "- "
+}
+
+// LinkedPageIDs возвращает ID страниц Notion из ссылок всех узлов дерева
+func LinkedPageIDs(tasks []Task) []string {
+	ids := []string{}
+	for _, task := range tasks {
+		if id := PageIDFromLink(task.Link); id != "" {
+			ids = append(ids, id)
+		}
+		ids = append(ids, LinkedPageIDs(task.Subtasks)...)
+	}
+	return ids
+}
+
+// MarkExisting проставляет TaskID узлам, ссылки которых ведут на существующие задачи
+func MarkExisting(tasks []Task, existing map[string]bool) {
+	for i := range tasks {
+		tasks[i].TaskID = ""
+		if id := PageIDFromLink(tasks[i].Link); existing[id] {
+			tasks[i].TaskID = id
+		}
+		MarkExisting(tasks[i].Subtasks, existing)
+	}
+}
+
+// CountNew возвращает количество узлов, которые будут созданы
+func CountNew(tasks []Task) int {
+	count := 0
+	for _, task := range tasks {
+		if task.TaskID == "" {
+			count++
+		}
+		count += CountNew(task.Subtasks)
+	}
+	return count
+}
@@ -0,0 +1,79 @@
+package mindmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenderMarkdownRoundTrip(t *testing.T) {
+	tasks := []Task{
+		{
+			Title: "Backend",
+			Link:  PageLink("1f2e3d4c-5b6a-7980-1f2e-3d4c5b6a7980"),
+			Hours: 2,
+			Subtasks: []Task{
+				{Title: "Auth", Hours: 8, Subtasks: []Task{
+					{Title: "Tokens", Hours: 3, Subtasks: []Task{
+						{Title: "Refresh", Hours: 1.5},
+					}},
+				}},
+			},
+		},
+		{Title: "Design", Hours: 6},
+	}
+
+	projectName, parsed, err := ParseMarkdownTasks(RenderMarkdown("Shop", tasks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projectName != "Shop" {
+		t.Errorf("expected project Shop, got %q", projectName)
+	}
+
+	for i := range tasks {
+		SumTaskHours(&tasks[i])
+	}
+	if !reflect.DeepEqual(parsed, tasks) {
+		t.Errorf("round trip mismatch:\nexpected %+v\ngot      %+v", tasks, parsed)
+	}
+}
+
+func TestPageIDFromLink(t *testing.T) {
+	tests := []struct {
+		link    string
+		expects string
+	}{
+		{"https://notion.so/1f2e3d4c5b6a79801f2e3d4c5b6a7980", "1f2e3d4c-5b6a-7980-1f2e-3d4c5b6a7980"},
+		{"https://www.notion.so/incetro/Auth-1F2E3D4C5B6A79801F2E3D4C5B6A7980?pvs=4", "1f2e3d4c-5b6a-7980-1f2e-3d4c5b6a7980"},
+		{"https://figma.com/file/1f2e3d4c5b6a79801f2e3d4c5b6a7980", ""},
+		{"", ""},
+	}
+
+	for _, tc := range tests {
+		if got := PageIDFromLink(tc.link); got != tc.expects {
+			t.Errorf("PageIDFromLink(%q) = %q, expected %q", tc.link, got, tc.expects)
+		}
+	}
+}
+
+func TestMarkExisting(t *testing.T) {
+	existingID := "1f2e3d4c-5b6a-7980-1f2e-3d4c5b6a7980"
+	tasks := []Task{
+		{Title: "Backend", Link: PageLink(existingID), TaskID: "stale", Subtasks: []Task{
+			{Title: "Auth", Link: PageLink("00000000-0000-0000-0000-000000000001")},
+			{Title: "Docs", Link: "https://example.com/docs"},
+		}},
+	}
+
+	if ids := LinkedPageIDs(tasks); len(ids) != 2 {
+		t.Fatalf("expected 2 linked page ids, got %v", ids)
+	}
+
+	MarkExisting(tasks, map[string]bool{existingID: true})
+	if tasks[0].TaskID != existingID || tasks[0].Subtasks[0].TaskID != "" {
+		t.Errorf("unexpected marks: %+v", tasks)
+	}
+	if count := CountNew(tasks); count != 2 {
+		t.Errorf("expected 2 new tasks, got %d", count)
+	}
+}
@@ -0,0 +1,87 @@
+package mindmap
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+var ErrXMindContentNotFound = errors.New("xmind content.json not found, legacy xmind files are not supported")
+
+type xmindSheet struct {
+	Title     string     `json:"title"`
+	RootTopic xmindTopic `json:"rootTopic"`
+}
+
+type xmindTopic struct {
+	Title string `json:"title"`
+	Href  string `json:"href"`
+	Notes struct {
+		Plain struct {
+			Content string `json:"content"`
+		} `json:"plain"`
+	} `json:"notes"`
+	Children struct {
+		Attached []xmindTopic `json:"attached"`
+	} `json:"children"`
+}
+
+func (t xmindTopic) toNode() node {
+	n := node{title: t.Title, link: t.Href, note: t.Notes.Plain.Content}
+	for _, child := range t.Children.Attached {
+		n.children = append(n.children, child.toNode())
+	}
+	return n
+}
+
+// ParseXMind разбирает .xmind (zip с content.json, XMind 8 Pro / Zen и новее).
+// Берется первый лист книги, его центральная тема - проект.
+// content.json больше maxSize байт не распаковывается, чтобы архив не раздулся в памяти.
+func ParseXMind(data []byte, maxSize int64) (string, []Task, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", nil, err
+	}
+
+	var content []byte
+	for _, file := range reader.File {
+		if file.Name != "content.json" {
+			continue
+		}
+		if file.UncompressedSize64 > uint64(maxSize) {
+			return "", nil, ErrTooLarge
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return "", nil, err
+		}
+		// Размер в заголовке zip может не совпадать с реальным, поэтому чтение тоже ограничено
+		content, err = io.ReadAll(io.LimitReader(rc, maxSize+1))
+		rc.Close()
+		if err != nil {
+			return "", nil, err
+		}
+		if int64(len(content)) > maxSize {
+			return "", nil, ErrTooLarge
+		}
+	}
+	if content == nil {
+		return "", nil, ErrXMindContentNotFound
+	}
+
+	sheets := []xmindSheet{}
+	if err := json.Unmarshal(content, &sheets); err != nil {
+		return "", nil, err
+	}
+	if len(sheets) == 0 {
+		return "", []Task{}, nil
+	}
+
+	return fromRoots(sheets[0].Title, []node{sheets[0].RootTopic.toNode()})
+}